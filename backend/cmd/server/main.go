@@ -1,90 +1,238 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
+
+	"github.com/simonryrie/otherwhere/internal/config"
+	"github.com/simonryrie/otherwhere/internal/handlers"
+	"github.com/simonryrie/otherwhere/internal/metrics"
+	"github.com/simonryrie/otherwhere/internal/openapi"
+	"github.com/simonryrie/otherwhere/internal/store"
+	"github.com/simonryrie/otherwhere/internal/tracing"
 )
 
+// compressMinSize is the smallest response body worth gzipping
+const compressMinSize = 1024
+
 func main() {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		slog.Error("invalid configuration", "error", err)
+		os.Exit(1)
+	}
+
 	// Initialize structured logger
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
+		Level: cfg.LogLevel,
 	}))
 	slog.SetDefault(logger)
 
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.OTLPEndpoint)
+	if err != nil {
+		slog.Error("failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("failed to flush traces", "error", err)
+		}
+	}()
+
+	destStore, err := store.NewStore(cfg)
+	if err != nil {
+		slog.Error("failed to create store", "backend", cfg.StoreBackend, "error", err)
+		os.Exit(1)
+	}
+	if c, ok := destStore.(io.Closer); ok {
+		defer c.Close()
+	}
+	if cfg.CacheTTL > 0 {
+		destStore = store.NewCachingStore(destStore, cfg.CacheTTL)
+	}
+	destStore = store.NewTracingStore(destStore)
+
+	// Start server
+	srv := &http.Server{
+		Addr:         ":" + cfg.Port,
+		Handler:      tracing.Handler(newRouter(cfg, destStore)),
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+	}
+
+	// Stop accepting requests on SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := serve(ctx, srv, cfg.ShutdownTimeout); err != nil {
+		slog.Error("server failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// newRouter builds the middleware and routes serving the API from destStore
+func newRouter(cfg config.Config, destStore store.DestinationStore) *chi.Mux {
+	h := handlers.New(cfg)
+
 	// Create router
 	r := chi.NewRouter()
+	m := metrics.New()
 
 	// Middleware
+	r.Use(tracing.RouteName)
 	r.Use(middleware.RequestID)
+	r.Use(handlers.EchoRequestID)
 	r.Use(middleware.RealIP)
-	r.Use(middleware.Logger)
+	r.Use(handlers.AccessLog(cfg.LogSampleRate, cfg.LogSlowThreshold))
 	r.Use(middleware.Recoverer)
+	r.Use(m.Middleware)
+	r.Use(handlers.WithStore(destStore))
 
-	// CORS configuration for local development
-	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:5173", "http://localhost:5174"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: false,
-		MaxAge:           300,
-	}))
+	// CORS configuration, advertising only what each route group accepts
+	r.Use(corsByRoute(cfg))
+
+	// Structured errors for unknown routes and methods
+	r.NotFound(handlers.NotFound)
+	r.MethodNotAllowed(handlers.MethodNotAllowed)
+
+	// Compress larger responses; /metrics negotiates its own compression
+	compress := handlers.Compress(compressMinSize)
 
 	// Routes
-	r.Get("/health", handleHealth)
+	r.Get("/health", h.Health)
+	r.Get("/livez", handlers.Livez)
+	r.Method(http.MethodGet, metrics.Path, m.Handler())
+	r.With(compress).Get(openapi.Path, openapi.Handler)
 
 	// API routes
 	r.Route("/api", func(r chi.Router) {
-		r.Get("/destinations", handleGetDestinations)
-		r.Get("/destinations/{id}", handleGetDestination)
-		r.Post("/search", handleSearch)
-	})
+		r.Use(compress)
+		r.Use(handlers.Timeout(cfg.RequestTimeout))
+		r.Use(handlers.LimitBody(cfg.MaxBodyBytes))
+		r.Use(handlers.RequireJSON)
+		r.Use(handlers.DatasetVersion)
+		r.Use(handlers.ActiveOnly(cfg.AdminToken))
+		r.With(handlers.ETag).Get("/destinations", handlers.Handle(h.GetDestinations))
+		r.Get("/destinations.csv", handlers.Handle(h.GetDestinationsCSV))
+		r.With(handlers.ETag).Get("/destinations/{id}", handlers.Handle(h.GetDestination))
+		r.Post("/destinations/batch", handlers.Handle(h.GetDestinationsBatch))
+		r.Get("/destinations/random", handlers.Handle(h.GetRandomDestination))
+		r.Get("/destinations/nearest", handlers.Handle(h.GetNearestDestinations))
+		r.Get("/destinations/{id}/similar", handlers.Handle(h.GetSimilarDestinations))
+		r.Get("/destinations/{id}/best-month", handlers.Handle(h.GetBestMonth))
+		r.Get("/features", h.GetFeatures)
+		r.Get("/presets", h.GetPresets)
+		r.Get("/filters", handlers.Handle(h.GetFilterOptions))
+		r.With(handlers.ETag).Get("/stats/continents", handlers.Handle(h.GetContinentStats))
+		r.Post("/search", handlers.Handle(h.Search))
+		r.Get("/search", handlers.Handle(h.SearchQuery))
+		r.Post("/search/diagnose", handlers.Handle(h.DiagnoseSearch))
+		r.Post("/search/validate", handlers.Handle(h.ValidateSearch))
+		r.Post("/search/more-like", handlers.Handle(h.MoreLike))
+		r.Get("/autocomplete", handlers.Handle(h.Autocomplete))
+		r.Post("/compare", handlers.Handle(h.Compare))
 
-	// Start server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
+		// Admin routes exist only when a token is configured
+		if cfg.AdminToken != "" {
+			r.With(handlers.RequireToken(cfg.AdminToken)).Post("/admin/reload", handlers.Handle(h.Reload))
+			r.With(handlers.RequireToken(cfg.AdminToken)).Get("/admin/coverage", handlers.Handle(h.Coverage))
+		}
+	})
+	return r
+}
 
-	slog.Info("server starting", "port", port)
-	if err := http.ListenAndServe(":"+port, r); err != nil {
-		slog.Error("server failed to start", "error", err)
-		os.Exit(1)
-	}
+// corsRoute is the methods and request headers browsers may use on the
+// routes under a path prefix
+type corsRoute struct {
+	prefix  string
+	methods []string
+	headers []string
 }
 
-// Health check handler
-func handleHealth(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"status":"ok"}`))
+// corsRoutes lists route groups from most to least specific; the last entry
+// covers the health, metrics, and OpenAPI endpoints
+var corsRoutes = []corsRoute{
+	{"/api/admin/", []string{http.MethodGet, http.MethodPost}, []string{"Accept", "Authorization", "Content-Type"}},
+	{"/api/", []string{http.MethodGet, http.MethodPost}, []string{"Accept", "Accept-Language", "Content-Type", "If-None-Match"}},
+	{"/", []string{http.MethodGet}, []string{"Accept"}},
 }
 
-// Placeholder handlers
-func handleGetDestinations(w http.ResponseWriter, r *http.Request) {
-	slog.Info("GET /api/destinations")
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"message":"Get all destinations - not implemented yet"}`))
+// corsByRoute applies the CORS settings of the first corsRoutes entry whose
+// prefix matches the request path. It runs before routing so preflight
+// requests are answered even though no route registers OPTIONS.
+func corsByRoute(cfg config.Config) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		byRoute := make([]http.Handler, len(corsRoutes))
+		for i, route := range corsRoutes {
+			byRoute[i] = cors.Handler(corsOptions(cfg, route))(next)
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for i, route := range corsRoutes {
+				if strings.HasPrefix(r.URL.Path, route.prefix) {
+					byRoute[i].ServeHTTP(w, r)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
 }
 
-func handleGetDestination(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	slog.Info("GET /api/destinations/:id", "id", id)
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"message":"Get destination by ID - not implemented yet","id":"` + id + `"}`))
+// corsOptions builds the CORS middleware settings for a route group from
+// the configuration
+func corsOptions(cfg config.Config, route corsRoute) cors.Options {
+	return cors.Options{
+		AllowedOrigins:   cfg.CORSAllowedOrigins,
+		AllowedMethods:   route.methods,
+		AllowedHeaders:   route.headers,
+		ExposedHeaders:   []string{"ETag", "Link", handlers.RequestIDHeader, handlers.DatasetVersionHeader},
+		AllowCredentials: cfg.CORSAllowCredentials,
+		MaxAge:           int(cfg.CORSMaxAge.Seconds()),
+	}
 }
 
-func handleSearch(w http.ResponseWriter, r *http.Request) {
-	slog.Info("POST /api/search")
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"message":"Search destinations - not implemented yet"}`))
+// serve runs srv until ctx is cancelled, then drains in-flight requests for
+// up to timeout before force-closing any remaining connections
+func serve(ctx context.Context, srv *http.Server, timeout time.Duration) error {
+	errCh := make(chan error, 1)
+	go func() {
+		slog.Info("server starting", "addr", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("server shutting down", "timeout", timeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("graceful shutdown timed out, forcing close", "error", err)
+		return srv.Close()
+	}
+
+	slog.Info("server stopped")
+	return nil
 }
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/simonryrie/otherwhere/internal/config"
+	"github.com/simonryrie/otherwhere/internal/store"
+)
+
+// freeAddr returns a localhost address with a port nothing is listening on
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
+
+func TestServeStopsCleanly(t *testing.T) {
+	started := make(chan struct{}, 1)
+	srv := &http.Server{
+		Addr: freeAddr(t),
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			started <- struct{}{}
+			// Still running when shutdown begins, so it must be drained
+			time.Sleep(100 * time.Millisecond)
+			io.WriteString(w, "ok")
+		}),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- serve(ctx, srv, 5*time.Second) }()
+
+	// Wait for the listener, then start a request and shut down under it
+	url := "http://" + srv.Addr + "/"
+	var conn net.Conn
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		var err error
+		if conn, err = net.Dial("tcp", srv.Addr); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server never started: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	conn.Close()
+
+	type response struct {
+		body string
+		err  error
+	}
+	inFlight := make(chan response, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			inFlight <- response{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		inFlight <- response{string(body), err}
+	}()
+	<-started
+	cancel()
+
+	if res := <-inFlight; res.err != nil || res.body != "ok" {
+		t.Errorf("in-flight request = %q, %v; want it to finish", res.body, res.err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("serve returned %v, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve didn't return after shutdown")
+	}
+	if _, err := http.Get(url); err == nil {
+		t.Error("server still accepting requests after shutdown")
+	}
+}
+
+func TestServeForcesCloseAfterTimeout(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	defer close(release)
+	srv := &http.Server{
+		Addr: freeAddr(t),
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			started <- struct{}{}
+			<-release
+		}),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- serve(ctx, srv, 50*time.Millisecond) }()
+
+	go func() {
+		for {
+			resp, err := http.Get("http://" + srv.Addr + "/")
+			if err == nil {
+				resp.Body.Close()
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}()
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server never answered")
+	}
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve didn't force-close a stuck request")
+	}
+}
+
+func TestCORSOptionsUseConfiguredOrigins(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://otherwhere.app, https://staging.otherwhere.app")
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	want := []string{"https://otherwhere.app", "https://staging.otherwhere.app"}
+	for _, route := range corsRoutes {
+		if got := corsOptions(cfg, route).AllowedOrigins; !slices.Equal(got, want) {
+			t.Errorf("%s: AllowedOrigins = %q, want %q", route.prefix, got, want)
+		}
+	}
+}
+
+// preflight sends a CORS preflight for method and headers to path from the
+// default development origin
+func preflight(h http.Handler, path, method, headers string) http.Header {
+	req := httptest.NewRequest(http.MethodOptions, path, nil)
+	req.Header.Set("Origin", "http://localhost:5173")
+	req.Header.Set("Access-Control-Request-Method", method)
+	if headers != "" {
+		req.Header.Set("Access-Control-Request-Headers", headers)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Header()
+}
+
+func TestCORSPreflightByRoute(t *testing.T) {
+	clearCORSEnv(t)
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	h := corsByRoute(cfg)(http.NotFoundHandler())
+
+	tests := []struct {
+		name, path, method, headers string
+		wantAllowed                 bool
+	}{
+		{"public GET", "/api/destinations", http.MethodGet, "", true},
+		{"public HEAD", "/api/destinations", http.MethodHead, "", false},
+		{"public POST", "/api/search", http.MethodPost, "Content-Type", true},
+		{"public locale header", "/api/destinations/tokyo", http.MethodGet, "Accept-Language", true},
+		{"public without Authorization", "/api/destinations", http.MethodGet, "Authorization", false},
+		{"public DELETE", "/api/destinations/tokyo", http.MethodDelete, "", false},
+		{"admin POST with a token", "/api/admin/reload", http.MethodPost, "Authorization", true},
+		{"admin GET", "/api/admin/coverage", http.MethodGet, "Authorization", true},
+		{"admin HEAD", "/api/admin/coverage", http.MethodHead, "", false},
+		{"admin without locale header", "/api/admin/coverage", http.MethodGet, "Accept-Language", false},
+		{"health GET", "/health", http.MethodGet, "", true},
+		{"health POST", "/health", http.MethodPost, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := preflight(h, tt.path, tt.method, tt.headers)
+			allowed := got.Get("Access-Control-Allow-Origin") == "http://localhost:5173"
+			if allowed != tt.wantAllowed {
+				t.Fatalf("preflight allowed = %t, want %t; headers %v", allowed, tt.wantAllowed, got)
+			}
+			if allowed && got.Get("Access-Control-Allow-Methods") != tt.method {
+				t.Errorf("Access-Control-Allow-Methods = %q, want %q", got.Get("Access-Control-Allow-Methods"), tt.method)
+			}
+		})
+	}
+}
+
+func TestCORSRoutesMatchRouter(t *testing.T) {
+	clearCORSEnv(t)
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	cfg.AdminToken = "secret"
+
+	// Each corsRoutes entry should advertise exactly the methods registered
+	// on the routes it's the first match for
+	routable := make([]map[string]bool, len(corsRoutes))
+	for i := range routable {
+		routable[i] = map[string]bool{}
+	}
+	walk := func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		for i, cr := range corsRoutes {
+			if strings.HasPrefix(route, cr.prefix) {
+				routable[i][method] = true
+				return nil
+			}
+		}
+		t.Errorf("%s %s isn't covered by any corsRoutes entry", method, route)
+		return nil
+	}
+	if err := chi.Walk(newRouter(cfg, store.NewMemoryStore(nil)), walk); err != nil {
+		t.Fatalf("Walk: %v", err)
+	}
+	for i, cr := range corsRoutes {
+		got := slices.Sorted(maps.Keys(routable[i]))
+		if want := slices.Sorted(slices.Values(cr.methods)); !slices.Equal(got, want) {
+			t.Errorf("%s advertises %v, but its routes accept %v", cr.prefix, want, got)
+		}
+	}
+}
+
+func TestCORSMaxAge(t *testing.T) {
+	tests := []struct {
+		env, want string
+	}{
+		{"", "300"},
+		{"10m", "600"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			clearCORSEnv(t)
+			t.Setenv("CORS_MAX_AGE", tt.env)
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			got := preflight(corsByRoute(cfg)(http.NotFoundHandler()), "/api/destinations", http.MethodGet, "")
+			if age := got.Get("Access-Control-Max-Age"); age != tt.want {
+				t.Errorf("Access-Control-Max-Age = %q, want %q", age, tt.want)
+			}
+		})
+	}
+}
+
+// clearCORSEnv unsets the CORS settings for the rest of the test, so the
+// defaults apply
+func clearCORSEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{"CORS_ALLOWED_ORIGINS", "CORS_ALLOW_CREDENTIALS", "CORS_MAX_AGE"} {
+		t.Setenv(name, "")
+	}
+}
@@ -0,0 +1,419 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/simonryrie/otherwhere/internal/ranking"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// Config holds server settings loaded from the environment
+type Config struct {
+	// Server
+	Port            string
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	IdleTimeout     time.Duration
+	ShutdownTimeout time.Duration
+	// RequestTimeout bounds the handler work for each API request
+	RequestTimeout time.Duration
+	// MaxBodyBytes caps the size of API request bodies
+	MaxBodyBytes int64
+
+	// CORS
+	CORSAllowedOrigins   []string
+	CORSAllowCredentials bool
+	// CORSMaxAge is how long browsers may cache preflight responses
+	CORSMaxAge time.Duration
+
+	// Logging
+	LogLevel slog.Level
+	// LogSampleRate logs one in this many successful requests; errors and
+	// requests slower than LogSlowThreshold are always logged
+	LogSampleRate    int
+	LogSlowThreshold time.Duration
+
+	// PlaceholderImageURL is returned for destinations without images
+	PlaceholderImageURL string
+
+	// AdminToken authorizes /api/admin endpoints; they're disabled when empty
+	AdminToken string
+
+	// Storage
+	// StoreBackend selects the destination store: memory, file, or firestore
+	StoreBackend string
+	SeedFile     string
+	// InvalidImages is "drop" to discard malformed seed image URLs or
+	// "reject" to fail the load
+	InvalidImages string
+	// NonFiniteFeatures is "zero" to store NaN and infinite feature values
+	// as missing zeros or "reject" to fail the load
+	NonFiniteFeatures   string
+	FirestoreCollection string
+	FirestoreProjectID  string
+	// CacheTTL is how long destination lists are cached; 0 disables caching
+	CacheTTL time.Duration
+
+	// OTLPEndpoint receives exported traces; empty disables tracing
+	OTLPEndpoint string
+
+	// Ranking
+	TextBlend    float64
+	AvoidPenalty float64
+	// FeatureAliases adds names searches accept for features, on top of
+	// the built-in ones, mapping each alias to a feature key
+	FeatureAliases map[string]string
+	// RedactedFeatures are feature keys hidden from API responses while
+	// still used for ranking
+	RedactedFeatures []string
+	// PopularityFeature orders searches with no query or constraints
+	PopularityFeature string
+	// CoastalKm is the coast distance below which destinations are coastal
+	CoastalKm float64
+	// TierHiddenGemBelow and TierIconicFrom are the popularity thresholds
+	// separating hidden gems, popular, and iconic destinations
+	TierHiddenGemBelow float64
+	TierIconicFrom     float64
+	// ComfortMinC and ComfortMaxC bound the temperature band, in °C, that
+	// searches with comfort=true favour; ComfortPenalty is the most score
+	// the bias can take away
+	ComfortMinC    float64
+	ComfortMaxC    float64
+	ComfortPenalty float64
+	// CollapseRadiusKm and CollapseMinSimilarity decide which results
+	// searches with collapse=true merge as near-duplicates
+	CollapseRadiusKm      float64
+	CollapseMinSimilarity float64
+	// ClampConstraints pulls search constraint bounds outside the normalized
+	// scale back onto it instead of rejecting the search
+	ClampConstraints bool
+	// MaxQueryComplexity caps how many constraints, constraint groups,
+	// weights, and avoid terms a search may combine
+	MaxQueryComplexity int
+	// ScoringWorkers caps the goroutines scoring a search; 0 uses GOMAXPROCS
+	ScoringWorkers int
+	// RandomSeed makes /api/destinations/random repeatable; 0 seeds randomly
+	RandomSeed uint64
+
+	// Page sizes for GET /api/destinations, search, and the similar and
+	// more-like endpoints
+	DestinationsPage PageLimits
+	SearchPage       PageLimits
+	SimilarPage      PageLimits
+}
+
+// PageLimits are an endpoint's page size when the client doesn't ask for
+// one, and the most it can ask for
+type PageLimits struct {
+	Default int
+	Max     int
+}
+
+// Default page sizes per endpoint
+var (
+	DefaultDestinationsPage = PageLimits{Default: 20, Max: 100}
+	DefaultSearchPage       = PageLimits{Default: 20, Max: 100}
+	DefaultSimilarPage      = PageLimits{Default: 5, Max: 100}
+)
+
+// defaultCORSOrigins are the Vite dev server origins used for local development
+var defaultCORSOrigins = []string{"http://localhost:5173", "http://localhost:5174"}
+
+// defaultPlaceholderImage is a neutral image for destinations without photos
+const defaultPlaceholderImage = "https://placehold.co/800x600?text=Otherwhere"
+
+// LoadConfig reads the configuration from environment variables, applying
+// defaults for anything unset
+func LoadConfig() (Config, error) {
+	cfg := Config{
+		Port:                envOr("PORT", "8080"),
+		CORSAllowedOrigins:  defaultCORSOrigins,
+		SeedFile:            envOr("SEED_FILE", "data/destinations.json"),
+		InvalidImages:       envOr("INVALID_IMAGES", "drop"),
+		NonFiniteFeatures:   envOr("NON_FINITE_FEATURES", "zero"),
+		PlaceholderImageURL: envOr("PLACEHOLDER_IMAGE_URL", defaultPlaceholderImage),
+		AdminToken:          os.Getenv("ADMIN_TOKEN"),
+		FirestoreCollection: envOr("FIRESTORE_COLLECTION", "destinations"),
+		FirestoreProjectID:  envOr("FIRESTORE_PROJECT_ID", os.Getenv("GCP_PROJECT_ID")),
+		StoreBackend:        envOr("STORE_BACKEND", "file"),
+		OTLPEndpoint:        os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
+	}
+
+	if cfg.InvalidImages != "drop" && cfg.InvalidImages != "reject" {
+		return Config{}, fmt.Errorf("INVALID_IMAGES must be drop or reject, got %q", cfg.InvalidImages)
+	}
+	if cfg.NonFiniteFeatures != "zero" && cfg.NonFiniteFeatures != "reject" {
+		return Config{}, fmt.Errorf("NON_FINITE_FEATURES must be zero or reject, got %q", cfg.NonFiniteFeatures)
+	}
+	if !types.ValidImageURL(cfg.PlaceholderImageURL) {
+		return Config{}, fmt.Errorf("PLACEHOLDER_IMAGE_URL must be an absolute http(s) URL, got %q", cfg.PlaceholderImageURL)
+	}
+
+	if _, err := strconv.ParseUint(cfg.Port, 10, 16); err != nil {
+		return Config{}, fmt.Errorf("PORT must be a number between 0 and 65535, got %q", cfg.Port)
+	}
+
+	if origins := parseList(os.Getenv("CORS_ALLOWED_ORIGINS")); len(origins) > 0 {
+		cfg.CORSAllowedOrigins = origins
+	}
+	if raw := os.Getenv("CORS_ALLOW_CREDENTIALS"); raw != "" {
+		allow, err := strconv.ParseBool(raw)
+		if err != nil {
+			return Config{}, fmt.Errorf("CORS_ALLOW_CREDENTIALS must be true or false, got %q", raw)
+		}
+		cfg.CORSAllowCredentials = allow
+	}
+	// Browsers reject credentialed requests to a wildcard origin
+	if slices.Contains(cfg.CORSAllowedOrigins, "*") {
+		cfg.CORSAllowCredentials = false
+	}
+
+	if err := cfg.LogLevel.UnmarshalText([]byte(envOr("LOG_LEVEL", "info"))); err != nil {
+		return Config{}, fmt.Errorf("LOG_LEVEL must be one of debug, info, warn, error: %w", err)
+	}
+
+	var err error
+	if cfg.CORSMaxAge, err = durationEnv("CORS_MAX_AGE", 5*time.Minute); err != nil {
+		return Config{}, err
+	}
+	if cfg.ReadTimeout, err = durationEnv("READ_TIMEOUT", 10*time.Second); err != nil {
+		return Config{}, err
+	}
+	if cfg.WriteTimeout, err = durationEnv("WRITE_TIMEOUT", 30*time.Second); err != nil {
+		return Config{}, err
+	}
+	if cfg.IdleTimeout, err = durationEnv("IDLE_TIMEOUT", 60*time.Second); err != nil {
+		return Config{}, err
+	}
+	if cfg.ShutdownTimeout, err = durationEnv("SHUTDOWN_TIMEOUT", 15*time.Second); err != nil {
+		return Config{}, err
+	}
+	if cfg.RequestTimeout, err = durationEnv("REQUEST_TIMEOUT", 15*time.Second); err != nil {
+		return Config{}, err
+	}
+	// A deadline past the write timeout would never get to send its 503
+	if cfg.RequestTimeout > cfg.WriteTimeout {
+		return Config{}, fmt.Errorf("REQUEST_TIMEOUT (%s) must not exceed WRITE_TIMEOUT (%s)", cfg.RequestTimeout, cfg.WriteTimeout)
+	}
+	if cfg.LogSlowThreshold, err = durationEnv("LOG_SLOW_THRESHOLD", time.Second); err != nil {
+		return Config{}, err
+	}
+	cfg.LogSampleRate = 1
+	if raw := os.Getenv("LOG_SAMPLE_RATE"); raw != "" {
+		if cfg.LogSampleRate, err = strconv.Atoi(raw); err != nil || cfg.LogSampleRate < 1 {
+			return Config{}, fmt.Errorf("LOG_SAMPLE_RATE must be a positive integer, got %q", raw)
+		}
+	}
+	cfg.MaxBodyBytes = 1 << 20
+	if raw := os.Getenv("MAX_BODY_BYTES"); raw != "" {
+		if cfg.MaxBodyBytes, err = strconv.ParseInt(raw, 10, 64); err != nil || cfg.MaxBodyBytes <= 0 {
+			return Config{}, fmt.Errorf("MAX_BODY_BYTES must be a positive integer, got %q", raw)
+		}
+	}
+	cfg.CacheTTL = 5 * time.Minute
+	if raw := os.Getenv("CACHE_TTL"); raw != "" {
+		if cfg.CacheTTL, err = time.ParseDuration(raw); err != nil || cfg.CacheTTL < 0 {
+			return Config{}, fmt.Errorf("CACHE_TTL must be a duration like 5m, or 0 to disable, got %q", raw)
+		}
+	}
+	if cfg.TextBlend, err = floatEnv("TEXT_BLEND", ranking.DefaultTextBlend); err != nil {
+		return Config{}, err
+	}
+	if cfg.TextBlend < 0 || cfg.TextBlend > 1 {
+		return Config{}, fmt.Errorf("TEXT_BLEND must be between 0 and 1, got %g", cfg.TextBlend)
+	}
+	if cfg.AvoidPenalty, err = floatEnv("AVOID_PENALTY", ranking.DefaultAvoidPenalty); err != nil {
+		return Config{}, err
+	}
+	if cfg.AvoidPenalty < 0 || cfg.AvoidPenalty > 1 {
+		return Config{}, fmt.Errorf("AVOID_PENALTY must be between 0 and 1, got %g", cfg.AvoidPenalty)
+	}
+	if cfg.FeatureAliases, err = parseAliases(os.Getenv("FEATURE_ALIASES")); err != nil {
+		return Config{}, err
+	}
+	if _, err := ranking.NewFeatureAliases(cfg.FeatureAliases); err != nil {
+		return Config{}, fmt.Errorf("FEATURE_ALIASES: %w", err)
+	}
+	cfg.RedactedFeatures = parseList(os.Getenv("REDACTED_FEATURES"))
+	for _, key := range cfg.RedactedFeatures {
+		if _, ok := ranking.FeatureByKey(key); !ok {
+			return Config{}, fmt.Errorf("REDACTED_FEATURES must list feature keys, got %q", key)
+		}
+	}
+	cfg.PopularityFeature = envOr("POPULARITY_FEATURE", ranking.DefaultPopularityFeature)
+	if _, ok := ranking.FeatureByKey(cfg.PopularityFeature); !ok {
+		return Config{}, fmt.Errorf("POPULARITY_FEATURE must be a feature key, got %q", cfg.PopularityFeature)
+	}
+	if cfg.CoastalKm, err = floatEnv("COASTAL_THRESHOLD_KM", ranking.DefaultCoastalKm); err != nil {
+		return Config{}, err
+	}
+	if cfg.CoastalKm <= 0 {
+		return Config{}, fmt.Errorf("COASTAL_THRESHOLD_KM must be positive, got %g", cfg.CoastalKm)
+	}
+	if cfg.TierHiddenGemBelow, err = floatEnv("TIER_HIDDEN_GEM_BELOW", ranking.DefaultTierThresholds.HiddenGemBelow); err != nil {
+		return Config{}, err
+	}
+	if cfg.TierIconicFrom, err = floatEnv("TIER_ICONIC_FROM", ranking.DefaultTierThresholds.IconicFrom); err != nil {
+		return Config{}, err
+	}
+	if cfg.TierHiddenGemBelow < 0 || cfg.TierIconicFrom > 1 {
+		return Config{}, fmt.Errorf("TIER_HIDDEN_GEM_BELOW and TIER_ICONIC_FROM must be between 0 and 1, got %g and %g", cfg.TierHiddenGemBelow, cfg.TierIconicFrom)
+	}
+	if cfg.TierHiddenGemBelow > cfg.TierIconicFrom {
+		return Config{}, fmt.Errorf("TIER_HIDDEN_GEM_BELOW (%g) must not exceed TIER_ICONIC_FROM (%g)", cfg.TierHiddenGemBelow, cfg.TierIconicFrom)
+	}
+	if cfg.ComfortMinC, err = floatEnv("COMFORT_MIN_C", ranking.DefaultComfortBiasMinC); err != nil {
+		return Config{}, err
+	}
+	if cfg.ComfortMaxC, err = floatEnv("COMFORT_MAX_C", ranking.DefaultComfortBiasMaxC); err != nil {
+		return Config{}, err
+	}
+	if cfg.ComfortMinC > cfg.ComfortMaxC {
+		return Config{}, fmt.Errorf("COMFORT_MIN_C (%g) must not exceed COMFORT_MAX_C (%g)", cfg.ComfortMinC, cfg.ComfortMaxC)
+	}
+	if cfg.ComfortPenalty, err = floatEnv("COMFORT_PENALTY", ranking.DefaultComfortPenalty); err != nil {
+		return Config{}, err
+	}
+	if cfg.ComfortPenalty < 0 || cfg.ComfortPenalty > 1 {
+		return Config{}, fmt.Errorf("COMFORT_PENALTY must be between 0 and 1, got %g", cfg.ComfortPenalty)
+	}
+	if cfg.CollapseRadiusKm, err = floatEnv("COLLAPSE_RADIUS_KM", ranking.DefaultCollapseRadiusKm); err != nil {
+		return Config{}, err
+	}
+	if cfg.CollapseRadiusKm <= 0 {
+		return Config{}, fmt.Errorf("COLLAPSE_RADIUS_KM must be positive, got %g", cfg.CollapseRadiusKm)
+	}
+	if cfg.CollapseMinSimilarity, err = floatEnv("COLLAPSE_MIN_SIMILARITY", ranking.DefaultCollapseMinSimilarity); err != nil {
+		return Config{}, err
+	}
+	if cfg.CollapseMinSimilarity < 0 || cfg.CollapseMinSimilarity > 1 {
+		return Config{}, fmt.Errorf("COLLAPSE_MIN_SIMILARITY must be between 0 and 1, got %g", cfg.CollapseMinSimilarity)
+	}
+	if raw := os.Getenv("CLAMP_CONSTRAINTS"); raw != "" {
+		if cfg.ClampConstraints, err = strconv.ParseBool(raw); err != nil {
+			return Config{}, fmt.Errorf("CLAMP_CONSTRAINTS must be true or false, got %q", raw)
+		}
+	}
+	cfg.MaxQueryComplexity = ranking.DefaultMaxComplexity
+	if raw := os.Getenv("MAX_QUERY_COMPLEXITY"); raw != "" {
+		if cfg.MaxQueryComplexity, err = strconv.Atoi(raw); err != nil || cfg.MaxQueryComplexity <= 0 {
+			return Config{}, fmt.Errorf("MAX_QUERY_COMPLEXITY must be a positive integer, got %q", raw)
+		}
+	}
+	if raw := os.Getenv("SCORING_WORKERS"); raw != "" {
+		if cfg.ScoringWorkers, err = strconv.Atoi(raw); err != nil || cfg.ScoringWorkers < 0 {
+			return Config{}, fmt.Errorf("SCORING_WORKERS must be a non-negative integer, got %q", raw)
+		}
+	}
+	if raw := os.Getenv("RANDOM_SEED"); raw != "" {
+		if cfg.RandomSeed, err = strconv.ParseUint(raw, 10, 64); err != nil {
+			return Config{}, fmt.Errorf("RANDOM_SEED must be a non-negative integer, got %q", raw)
+		}
+	}
+	if cfg.DestinationsPage, err = pageLimitsEnv("DESTINATIONS", DefaultDestinationsPage); err != nil {
+		return Config{}, err
+	}
+	if cfg.SearchPage, err = pageLimitsEnv("SEARCH", DefaultSearchPage); err != nil {
+		return Config{}, err
+	}
+	if cfg.SimilarPage, err = pageLimitsEnv("SIMILAR", DefaultSimilarPage); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// parseList splits a comma-separated list such as CORS origins, trimming
+// whitespace and dropping empty entries
+func parseList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseAliases reads a comma-separated list of alias=feature_key pairs
+func parseAliases(raw string) (map[string]string, error) {
+	aliases := map[string]string{}
+	for _, pair := range parseList(raw) {
+		alias, key, ok := strings.Cut(pair, "=")
+		alias, key = strings.TrimSpace(alias), strings.TrimSpace(key)
+		if !ok || alias == "" || key == "" {
+			return nil, fmt.Errorf("FEATURE_ALIASES entries must look like alias=feature_key, got %q", pair)
+		}
+		aliases[alias] = key
+	}
+	return aliases, nil
+}
+
+// pageLimitsEnv reads <prefix>_DEFAULT_LIMIT and <prefix>_MAX_LIMIT, which
+// must be positive with the default no larger than the max
+func pageLimitsEnv(prefix string, def PageLimits) (PageLimits, error) {
+	limits := def
+	for _, env := range []struct {
+		name string
+		v    *int
+	}{
+		{prefix + "_DEFAULT_LIMIT", &limits.Default},
+		{prefix + "_MAX_LIMIT", &limits.Max},
+	} {
+		raw := os.Getenv(env.name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			return PageLimits{}, fmt.Errorf("%s must be a positive integer, got %q", env.name, raw)
+		}
+		*env.v = n
+	}
+	if limits.Default > limits.Max {
+		return PageLimits{}, fmt.Errorf("%s_DEFAULT_LIMIT (%d) must not exceed %s_MAX_LIMIT (%d)", prefix, limits.Default, prefix, limits.Max)
+	}
+	return limits, nil
+}
+
+// envOr returns the named environment variable, or def when it's unset
+func envOr(name, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return def
+}
+
+// durationEnv parses a positive duration such as "15s" from the environment
+func durationEnv(name string, def time.Duration) (time.Duration, error) {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%s must be a positive duration like 15s, got %q", name, raw)
+	}
+	return d, nil
+}
+
+// floatEnv parses a finite float from the environment. NaN would slip past
+// every range check, since all comparisons with it are false.
+func floatEnv(name string, def float64) (float64, error) {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return def, nil
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("%s must be a finite number, got %q", name, raw)
+	}
+	return v, nil
+}
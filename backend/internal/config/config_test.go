@@ -0,0 +1,237 @@
+package config
+
+import (
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// configEnv lists the environment variables LoadConfig reads
+var configEnv = []string{
+	"PORT", "READ_TIMEOUT", "WRITE_TIMEOUT", "IDLE_TIMEOUT", "SHUTDOWN_TIMEOUT", "REQUEST_TIMEOUT", "MAX_BODY_BYTES",
+	"CORS_ALLOWED_ORIGINS", "CORS_ALLOW_CREDENTIALS", "CORS_MAX_AGE",
+	"LOG_LEVEL", "LOG_SAMPLE_RATE", "LOG_SLOW_THRESHOLD",
+	"PLACEHOLDER_IMAGE_URL", "ADMIN_TOKEN",
+	"STORE_BACKEND", "SEED_FILE", "INVALID_IMAGES", "NON_FINITE_FEATURES",
+	"FIRESTORE_COLLECTION", "FIRESTORE_PROJECT_ID", "GCP_PROJECT_ID", "CACHE_TTL", "OTEL_EXPORTER_OTLP_ENDPOINT",
+	"TEXT_BLEND", "AVOID_PENALTY", "FEATURE_ALIASES", "REDACTED_FEATURES", "POPULARITY_FEATURE", "COASTAL_THRESHOLD_KM",
+	"TIER_HIDDEN_GEM_BELOW", "TIER_ICONIC_FROM", "COMFORT_MIN_C", "COMFORT_MAX_C", "COMFORT_PENALTY",
+	"COLLAPSE_RADIUS_KM", "COLLAPSE_MIN_SIMILARITY", "CLAMP_CONSTRAINTS", "MAX_QUERY_COMPLEXITY",
+	"SCORING_WORKERS", "RANDOM_SEED",
+	"DESTINATIONS_DEFAULT_LIMIT", "DESTINATIONS_MAX_LIMIT", "SEARCH_DEFAULT_LIMIT", "SEARCH_MAX_LIMIT",
+	"SIMILAR_DEFAULT_LIMIT", "SIMILAR_MAX_LIMIT",
+}
+
+// clearEnv blanks every variable LoadConfig reads for the rest of the test,
+// which LoadConfig treats the same as unset
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range configEnv {
+		t.Setenv(name, "")
+	}
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	clearEnv(t)
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		got, want any
+	}{
+		{"Port", cfg.Port, "8080"},
+		{"CORSAllowedOrigins", strings.Join(cfg.CORSAllowedOrigins, ","), "http://localhost:5173,http://localhost:5174"},
+		{"FirestoreCollection", cfg.FirestoreCollection, "destinations"},
+		{"LogLevel", cfg.LogLevel, slog.LevelInfo},
+		{"ReadTimeout", cfg.ReadTimeout, 10 * time.Second},
+		{"WriteTimeout", cfg.WriteTimeout, 30 * time.Second},
+		{"IdleTimeout", cfg.IdleTimeout, 60 * time.Second},
+		{"ShutdownTimeout", cfg.ShutdownTimeout, 15 * time.Second},
+		{"MaxBodyBytes", cfg.MaxBodyBytes, int64(1 << 20)},
+		{"CORSMaxAge", cfg.CORSMaxAge, 5 * time.Minute},
+		{"LogSampleRate", cfg.LogSampleRate, 1},
+		{"LogSlowThreshold", cfg.LogSlowThreshold, time.Second},
+		{"StoreBackend", cfg.StoreBackend, "file"},
+		{"SeedFile", cfg.SeedFile, "data/destinations.json"},
+		{"NonFiniteFeatures", cfg.NonFiniteFeatures, "zero"},
+		{"ClampConstraints", cfg.ClampConstraints, false},
+		{"MaxQueryComplexity", cfg.MaxQueryComplexity, 100},
+		{"DestinationsPage", cfg.DestinationsPage, PageLimits{Default: 20, Max: 100}},
+		{"SearchPage", cfg.SearchPage, PageLimits{Default: 20, Max: 100}},
+		{"SimilarPage", cfg.SimilarPage, PageLimits{Default: 5, Max: 100}},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLoadConfigOverrides(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("PORT", "9090")
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("READ_TIMEOUT", "2s")
+	t.Setenv("FIRESTORE_COLLECTION", "places")
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Port != "9090" || cfg.LogLevel != slog.LevelDebug || cfg.ReadTimeout != 2*time.Second || cfg.FirestoreCollection != "places" {
+		t.Errorf("overrides not applied: %+v", cfg)
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	tests := []struct {
+		name, value string
+		// wantErr is a substring of the expected error
+		wantErr string
+	}{
+		{"PORT", "http", "PORT must be a number"},
+		{"PORT", "70000", "PORT must be a number"},
+		{"LOG_LEVEL", "loud", "LOG_LEVEL must be one of"},
+		{"READ_TIMEOUT", "soon", "READ_TIMEOUT must be a positive duration"},
+		{"READ_TIMEOUT", "-1s", "READ_TIMEOUT must be a positive duration"},
+		{"SHUTDOWN_TIMEOUT", "0s", "SHUTDOWN_TIMEOUT must be a positive duration"},
+		{"LOG_SAMPLE_RATE", "0", `LOG_SAMPLE_RATE must be a positive integer, got "0"`},
+		{"LOG_SAMPLE_RATE", "half", `LOG_SAMPLE_RATE must be a positive integer, got "half"`},
+		{"LOG_SLOW_THRESHOLD", "slow", "LOG_SLOW_THRESHOLD must be a positive duration"},
+		{"NON_FINITE_FEATURES", "skip", `NON_FINITE_FEATURES must be zero or reject, got "skip"`},
+		{"CORS_MAX_AGE", "300", "CORS_MAX_AGE must be a positive duration"},
+		{"MAX_BODY_BYTES", "1MB", `MAX_BODY_BYTES must be a positive integer, got "1MB"`},
+		{"MAX_BODY_BYTES", "0", `MAX_BODY_BYTES must be a positive integer, got "0"`},
+		{"TEXT_BLEND", "lots", "TEXT_BLEND must be a finite number"},
+		{"TEXT_BLEND", "NaN", "TEXT_BLEND must be a finite number"},
+		{"TEXT_BLEND", "1.5", "TEXT_BLEND must be between 0 and 1"},
+		{"FEATURE_ALIASES", "heat", `FEATURE_ALIASES entries must look like alias=feature_key, got "heat"`},
+		{"FEATURE_ALIASES", "heat=llama_density", `FEATURE_ALIASES: alias "heat" names unknown feature "llama_density"`},
+		{"REDACTED_FEATURES", "gdp_per_capita,salary", `REDACTED_FEATURES must list feature keys, got "salary"`},
+		{"POPULARITY_FEATURE", "fame", `POPULARITY_FEATURE must be a feature key, got "fame"`},
+		{"TIER_HIDDEN_GEM_BELOW", "-0.1", "TIER_HIDDEN_GEM_BELOW and TIER_ICONIC_FROM must be between 0 and 1"},
+		{"TIER_ICONIC_FROM", "0.2", "TIER_HIDDEN_GEM_BELOW (0.35) must not exceed TIER_ICONIC_FROM (0.2)"},
+		{"COMFORT_MIN_C", "-Inf", "COMFORT_MIN_C must be a finite number"},
+		{"COMFORT_MIN_C", "30", "COMFORT_MIN_C (30) must not exceed COMFORT_MAX_C (25)"},
+		{"COLLAPSE_RADIUS_KM", "0", "COLLAPSE_RADIUS_KM must be positive, got 0"},
+		{"COLLAPSE_MIN_SIMILARITY", "1.1", "COLLAPSE_MIN_SIMILARITY must be between 0 and 1, got 1.1"},
+		{"CLAMP_CONSTRAINTS", "maybe", `CLAMP_CONSTRAINTS must be true or false, got "maybe"`},
+		{"MAX_QUERY_COMPLEXITY", "0", `MAX_QUERY_COMPLEXITY must be a positive integer, got "0"`},
+		{"SCORING_WORKERS", "-1", `SCORING_WORKERS must be a non-negative integer, got "-1"`},
+		{"DESTINATIONS_DEFAULT_LIMIT", "0", `DESTINATIONS_DEFAULT_LIMIT must be a positive integer, got "0"`},
+		{"SEARCH_MAX_LIMIT", "lots", `SEARCH_MAX_LIMIT must be a positive integer, got "lots"`},
+		{"SIMILAR_DEFAULT_LIMIT", "200", "SIMILAR_DEFAULT_LIMIT (200) must not exceed SIMILAR_MAX_LIMIT (100)"},
+		{"SIMILAR_MAX_LIMIT", "3", "SIMILAR_DEFAULT_LIMIT (5) must not exceed SIMILAR_MAX_LIMIT (3)"},
+		{"COMFORT_PENALTY", "2", "COMFORT_PENALTY must be between 0 and 1, got 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+"="+tt.value, func(t *testing.T) {
+			clearEnv(t)
+			t.Setenv(tt.name, tt.value)
+			_, err := LoadConfig()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadConfig error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFeatureAliases(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("FEATURE_ALIASES", " heat = avg_temp_c , ,powder=skiing_score")
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	want := map[string]string{"heat": "avg_temp_c", "powder": "skiing_score"}
+	if !maps.Equal(cfg.FeatureAliases, want) {
+		t.Errorf("FeatureAliases = %v, want %v", cfg.FeatureAliases, want)
+	}
+}
+
+func TestLoadConfigRedactedFeatures(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("REDACTED_FEATURES", " gdp_per_capita, ,development_level")
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if want := []string{"gdp_per_capita", "development_level"}; !slices.Equal(cfg.RedactedFeatures, want) {
+		t.Errorf("RedactedFeatures = %q, want %q", cfg.RedactedFeatures, want)
+	}
+}
+
+func TestLoadConfigPageLimits(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("DESTINATIONS_DEFAULT_LIMIT", "50")
+	t.Setenv("DESTINATIONS_MAX_LIMIT", "500")
+	t.Setenv("SEARCH_MAX_LIMIT", "40")
+	t.Setenv("SIMILAR_DEFAULT_LIMIT", "10")
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	tests := []struct {
+		name      string
+		got, want PageLimits
+	}{
+		{"destinations", cfg.DestinationsPage, PageLimits{Default: 50, Max: 500}},
+		{"search", cfg.SearchPage, PageLimits{Default: 20, Max: 40}},
+		{"similar", cfg.SimilarPage, PageLimits{Default: 10, Max: 100}},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s page = %+v, want %+v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestParseList(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want []string
+	}{
+		{"", nil},
+		{" , ,", nil},
+		{"a", []string{"a"}},
+		{" a , b,,c ", []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		if got := parseList(tt.raw); !slices.Equal(got, tt.want) {
+			t.Errorf("parseList(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestLoadConfigCORS(t *testing.T) {
+	tests := []struct {
+		origins, credentials string
+		wantOrigins          []string
+		wantCredentials      bool
+	}{
+		{"", "", defaultCORSOrigins, false},
+		{" https://otherwhere.app , ,https://www.otherwhere.app", "true", []string{"https://otherwhere.app", "https://www.otherwhere.app"}, true},
+		// Browsers refuse credentials with a wildcard, so it turns them off
+		{"https://otherwhere.app,*", "true", []string{"https://otherwhere.app", "*"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.origins, func(t *testing.T) {
+			clearEnv(t)
+			t.Setenv("CORS_ALLOWED_ORIGINS", tt.origins)
+			t.Setenv("CORS_ALLOW_CREDENTIALS", tt.credentials)
+			cfg, err := LoadConfig()
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			if !slices.Equal(cfg.CORSAllowedOrigins, tt.wantOrigins) || cfg.CORSAllowCredentials != tt.wantCredentials {
+				t.Errorf("origins %q, credentials %t; want %q, %t",
+					cfg.CORSAllowedOrigins, cfg.CORSAllowCredentials, tt.wantOrigins, tt.wantCredentials)
+			}
+		})
+	}
+}
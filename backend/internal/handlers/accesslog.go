@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// AccessLog logs one structured line per request. Server errors (5xx) are
+// logged at error level and client errors (4xx) at warn, every time. Other
+// requests log at info, but only one in sampleN, unless they took at least
+// slow; a sampleN of 1 or less logs them all. Sampled lines carry
+// sample_rate so counts can be scaled back up.
+func AccessLog(sampleN int, slow time.Duration) func(http.Handler) http.Handler {
+	var seen atomic.Uint64
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+			elapsed := time.Since(start)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			level := slog.LevelInfo
+			switch {
+			case status >= 500:
+				level = slog.LevelError
+			case status >= 400:
+				level = slog.LevelWarn
+			}
+
+			attrs := []any{
+				"request_id", middleware.GetReqID(r.Context()),
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", status,
+				"bytes", ww.BytesWritten(),
+				"elapsed_ms", elapsed.Milliseconds(),
+				"remote_addr", r.RemoteAddr,
+			}
+			if level == slog.LevelInfo && elapsed < slow && sampleN > 1 {
+				if seen.Add(1)%uint64(sampleN) != 0 {
+					return
+				}
+				attrs = append(attrs, "sample_rate", sampleN)
+			}
+			if elapsed >= slow {
+				attrs = append(attrs, "slow", true)
+			}
+			slog.Log(r.Context(), level, "request", attrs...)
+		})
+	}
+}
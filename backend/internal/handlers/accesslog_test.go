@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// newAccessLogRouter serves /status/{code}, answering with that code, and
+// /slow, which takes a little over 20ms, behind AccessLog
+func newAccessLogRouter(sampleN int) http.Handler {
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID)
+	r.Use(AccessLog(sampleN, 20*time.Millisecond))
+	r.Get("/status/{code}", func(w http.ResponseWriter, r *http.Request) {
+		code, _ := strconv.Atoi(chi.URLParam(r, "code"))
+		w.WriteHeader(code)
+	})
+	r.Get("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(25 * time.Millisecond)
+	})
+	return r
+}
+
+func TestAccessLogSampling(t *testing.T) {
+	tests := []struct {
+		name     string
+		sampleN  int
+		path     string
+		requests int
+		// wantLogged is how many of the requests produce a log line
+		wantLogged int
+		wantLevel  string
+	}{
+		{"info sampled", 5, "/status/200", 20, 4, "INFO"},
+		{"sample rate of one logs everything", 1, "/status/200", 5, 5, "INFO"},
+		{"server errors always logged", 5, "/status/500", 7, 7, "ERROR"},
+		{"client errors always logged", 5, "/status/404", 7, 7, "WARN"},
+		{"slow requests always logged", 100, "/slow", 3, 3, "INFO"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLogs(t)
+			router := newAccessLogRouter(tt.sampleN)
+			for range tt.requests {
+				router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+			}
+			records := logs()
+			if len(records) != tt.wantLogged {
+				t.Fatalf("logged %d of %d requests, want %d", len(records), tt.requests, tt.wantLogged)
+			}
+			for _, rec := range records {
+				if rec["level"] != tt.wantLevel {
+					t.Errorf("level = %v, want %s", rec["level"], tt.wantLevel)
+				}
+				if id, _ := rec["request_id"].(string); id == "" {
+					t.Errorf("record %v has no request_id", rec)
+				}
+			}
+		})
+	}
+}
+
+func TestAccessLogAttributes(t *testing.T) {
+	tests := []struct {
+		name, path string
+		sampleN    int
+		// requests is enough that exactly one line is logged
+		requests       int
+		wantSampleRate any
+		wantSlow       any
+	}{
+		{"sampled lines carry the rate", "/status/200", 2, 2, float64(2), nil},
+		{"unsampled lines have no rate", "/status/200", 1, 1, nil, nil},
+		{"slow lines are flagged and not sampled", "/slow", 2, 1, nil, true},
+		{"errors are not sampled", "/status/503", 2, 1, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLogs(t)
+			router := newAccessLogRouter(tt.sampleN)
+			for range tt.requests {
+				router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+			}
+			records := logs()
+			if len(records) != 1 {
+				t.Fatalf("logged %d lines, want 1", len(records))
+			}
+			rec := records[0]
+			if rec["sample_rate"] != tt.wantSampleRate {
+				t.Errorf("sample_rate = %v, want %v", rec["sample_rate"], tt.wantSampleRate)
+			}
+			if rec["slow"] != tt.wantSlow {
+				t.Errorf("slow = %v, want %v", rec["slow"], tt.wantSlow)
+			}
+			if rec["path"] != tt.path {
+				t.Errorf("path = %v, want %s", rec["path"], tt.path)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/simonryrie/otherwhere/internal/ranking"
+	"github.com/simonryrie/otherwhere/internal/store"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// RequireToken rejects requests that don't carry "Authorization: Bearer
+// <token>", comparing in constant time
+func RequireToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !hasToken(r, token) {
+				unauthorized(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// hasToken reports whether r carries "Authorization: Bearer <token>",
+// comparing in constant time. An empty token never matches.
+func hasToken(r *http.Request, token string) bool {
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && token != "" && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+// unauthorized writes the 401 for a missing or invalid admin token
+func unauthorized(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	writeError(w, r, http.StatusUnauthorized, codeUnauthorized, "missing or invalid admin token")
+}
+
+// Reload refreshes the dataset from its source without a restart
+func (h *Handler) Reload(w http.ResponseWriter, r *http.Request) error {
+	reloader, ok := storeFromContext(r.Context()).(store.Reloader)
+	if !ok {
+		return unprocessable("the configured store does not support reloading")
+	}
+
+	before, after, err := reloader.Reload(r.Context())
+	if errors.Is(err, store.ErrNotReloadable) {
+		return unprocessable("the configured store does not support reloading")
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	if err != nil {
+		// The caller is an operator, so report what was wrong with the data
+		requestLogger(r).Error("failed to reload destinations", "error", err)
+		return unprocessable(fmt.Sprintf("reload failed, keeping the current dataset: %v", err))
+	}
+	requestLogger(r).Info("reloaded destinations", "before", before, "after", after)
+
+	writeData(w, r, http.StatusOK, types.ReloadResponse{Before: before, After: after})
+	return nil
+}
+
+// Coverage reports how many destinations have data for each feature, so
+// operators can spot sparse features before they hurt ranking
+func (h *Handler) Coverage(w http.ResponseWriter, r *http.Request) error {
+	destinations, err := storeFromContext(r.Context()).List(r.Context())
+	if err != nil {
+		return fmt.Errorf("list destinations: %w", err)
+	}
+	writeData(w, r, http.StatusOK, types.CoverageResponse{
+		Total:    len(destinations),
+		Features: ranking.Coverage(destinations),
+	})
+	return nil
+}
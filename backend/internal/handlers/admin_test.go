@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/store"
+)
+
+// newReloadableRouter serves testDestinations from a seed file, with admin
+// routes behind the token "secret"
+func newReloadableRouter(t *testing.T) (http.Handler, string) {
+	t.Helper()
+	data, err := json.Marshal(testDestinations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "destinations.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := store.NewMemoryStoreFromFile(path, "", "")
+	if err != nil {
+		t.Fatalf("NewMemoryStoreFromFile: %v", err)
+	}
+	cfg := testConfig(t)
+	cfg.AdminToken = "secret"
+	return newTestRouterWithStore(cfg, s), path
+}
+
+// adminRequest sends a POST with the given bearer token
+func adminRequest(h http.Handler, target, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, target, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestReload(t *testing.T) {
+	router, path := newReloadableRouter(t)
+
+	for _, token := range []string{"", "wrong"} {
+		got := decodeError(t, adminRequest(router, "/api/admin/reload", token), http.StatusUnauthorized)
+		if got.Code != codeUnauthorized {
+			t.Errorf("token %q: code = %s, want %s", token, got.Code, codeUnauthorized)
+		}
+	}
+
+	data, _ := json.Marshal(testDestinations[:2])
+	os.WriteFile(path, data, 0o600)
+	var got struct {
+		Before int `json:"before"`
+		After  int `json:"after"`
+	}
+	decodeData(t, adminRequest(router, "/api/admin/reload", "secret"), http.StatusOK, &got)
+	if got.Before != 4 || got.After != 2 {
+		t.Errorf("reload = %+v, want 4 before and 2 after", got)
+	}
+
+	os.WriteFile(path, []byte(`[{"id":"broken"}]`), 0o600)
+	decodeError(t, adminRequest(router, "/api/admin/reload", "secret"), http.StatusUnprocessableEntity)
+	var list resultList
+	decodeData(t, serve(router, http.MethodGet, "/api/destinations", ""), http.StatusOK, &list)
+	if list.Total != 2 {
+		t.Errorf("after a failed reload total = %d, want the 2 already loaded", list.Total)
+	}
+}
+
+func TestReloadUnsupported(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.AdminToken = "secret"
+	router := newTestRouter(cfg, testDestinations)
+	decodeError(t, adminRequest(router, "/api/admin/reload", "secret"), http.StatusUnprocessableEntity)
+}
+
+// TestReloadDuringSearches reloads while searches run; run with -race
+func TestReloadDuringSearches(t *testing.T) {
+	router, _ := newReloadableRouter(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			for ctx.Err() == nil {
+				// decodeData can't be used off the test goroutine, since it calls Fatal
+				rec := serve(router, http.MethodPost, "/api/search", `{"query":"ski"}`)
+				var got struct {
+					Data resultList `json:"data"`
+				}
+				if err := json.Unmarshal(rec.Body.Bytes(), &got); rec.Code != http.StatusOK || err != nil {
+					t.Errorf("search = %d, %v; body: %s", rec.Code, err, rec.Body)
+					return
+				}
+				if got.Data.Total != len(got.Data.Destinations) || got.Data.Total == 0 {
+					t.Errorf("search returned %d of %d results", len(got.Data.Destinations), got.Data.Total)
+				}
+			}
+		})
+	}
+	for range 20 {
+		if rec := adminRequest(router, "/api/admin/reload", "secret"); rec.Code != http.StatusOK {
+			t.Errorf("reload status = %d; body: %s", rec.Code, rec.Body)
+		}
+	}
+	cancel()
+	wg.Wait()
+}
+
+func TestCoverage(t *testing.T) {
+	router, _ := newReloadableRouter(t)
+	coverage := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/coverage", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	decodeError(t, coverage(""), http.StatusUnauthorized)
+
+	var got struct {
+		Total    int `json:"total"`
+		Features []struct {
+			Key       string  `json:"key"`
+			Populated int     `json:"populated"`
+			Percent   float64 `json:"percent"`
+		} `json:"features"`
+	}
+	decodeData(t, coverage("secret"), http.StatusOK, &got)
+	if got.Total != len(testDestinations) {
+		t.Errorf("total = %d, want %d", got.Total, len(testDestinations))
+	}
+	percent := map[string]float64{}
+	for _, f := range got.Features {
+		percent[f.Key] = f.Percent
+	}
+
+	// testDestinations has known gaps: only zermatt and lofoten ski, zermatt
+	// has no water sports, and tamarindo sits on the coast at 0 km
+	tests := []struct {
+		key  string
+		want float64
+	}{
+		{"avg_temp_c", 100},
+		{"skiing_score", 50},
+		{"water_sports_score", 75},
+		{"coast_distance_km", 75},
+	}
+	for _, tt := range tests {
+		if percent[tt.key] != tt.want {
+			t.Errorf("%s coverage = %v%%, want %v%%", tt.key, percent[tt.key], tt.want)
+		}
+	}
+}
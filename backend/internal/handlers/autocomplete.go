@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/simonryrie/otherwhere/internal/ranking"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// maxSuggestions caps how many names autocomplete returns
+const maxSuggestions = 10
+
+// Autocomplete suggests destination names matching the q query parameter.
+// Queries too short to be useful get an empty list rather than an error.
+func (h *Handler) Autocomplete(w http.ResponseWriter, r *http.Request) error {
+	q := r.URL.Query().Get("q")
+	if len([]rune(q)) > maxQueryLength {
+		return badRequestf("q must be at most %d characters", maxQueryLength)
+	}
+
+	destinations, err := storeFromContext(r.Context()).List(r.Context())
+	if err != nil {
+		return fmt.Errorf("list destinations: %w", err)
+	}
+
+	matches := ranking.Suggest(q, destinations, maxSuggestions)
+	suggestions := make([]types.Suggestion, len(matches))
+	for i, d := range matches {
+		suggestions[i] = types.Suggestion{ID: d.ID, Name: d.Name, Country: d.Country}
+	}
+	writeData(w, r, http.StatusOK, suggestions)
+	return nil
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestAutocomplete(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	tests := []struct {
+		q    string
+		want []types.Suggestion
+	}{
+		{"to", []types.Suggestion{{ID: "tokyo", Name: "Tokyo", Country: "Japan"}}},
+		{"ZERM", []types.Suggestion{{ID: "zermatt", Name: "Zermatt", Country: "Switzerland"}}},
+		{"t", []types.Suggestion{}},
+		{"", []types.Suggestion{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.q, func(t *testing.T) {
+			rec := serve(router, http.MethodGet, "/api/autocomplete?q="+url.QueryEscape(tt.q), "")
+			var got []types.Suggestion
+			decodeData(t, rec, http.StatusOK, &got)
+			if got == nil || len(got) != len(tt.want) {
+				t.Fatalf("suggestions = %#v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("suggestion %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	long := strings.Repeat("a", maxQueryLength+1)
+	decodeError(t, serve(router, http.MethodGet, "/api/autocomplete?q="+long, ""), http.StatusBadRequest)
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// maxBatchIDs caps how many destinations one batch request can fetch
+const maxBatchIDs = 100
+
+// GetDestinationsBatch returns the requested destinations in input order,
+// listing any IDs that don't exist instead of failing the whole request
+func (h *Handler) GetDestinationsBatch(w http.ResponseWriter, r *http.Request) error {
+	slog.Info("POST /api/destinations/batch")
+
+	var req types.BatchRequest
+	if err := decodeJSON(r, &req); err != nil {
+		return err
+	}
+	if len(req.IDs) > maxBatchIDs {
+		return badRequestf("ids must list at most %d destinations, got %d", maxBatchIDs, len(req.IDs))
+	}
+	ids := dedupe(req.IDs)
+	if len(ids) == 0 {
+		return badRequestf("ids must list at least one destination")
+	}
+	view, err := h.viewOptions(r)
+	if err != nil {
+		return badRequest(err)
+	}
+	fields, err := parseFields(r)
+	if err != nil {
+		return badRequest(err)
+	}
+
+	destinations, notFound, err := getDestinations(r, ids)
+	if err != nil {
+		return err
+	}
+
+	writeFields(w, r, http.StatusOK, types.BatchResponse{
+		Destinations: newDestinationViews(destinations, view),
+		NotFound:     notFound,
+	}, fields)
+	return nil
+}
+
+// getDestinations fetches ids from the request's store in one batched read,
+// returning the destinations found in ids order and the IDs that weren't
+func getDestinations(r *http.Request, ids []string) ([]types.Destination, []string, error) {
+	destinations, err := storeFromContext(r.Context()).GetMany(r.Context(), ids)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get destinations: %w", err)
+	}
+	found := make(map[string]bool, len(destinations))
+	for _, d := range destinations {
+		found[d.ID] = true
+	}
+	missing := []string{}
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	return destinations, missing, nil
+}
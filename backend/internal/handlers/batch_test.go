@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGetDestinationsBatch(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	tests := []struct {
+		name         string
+		ids          string
+		want         []string
+		wantNotFound []string
+	}{
+		{"input order kept", `"zermatt","tokyo","lofoten"`, []string{"zermatt", "tokyo", "lofoten"}, []string{}},
+		{"duplicates dropped", `"tokyo","zermatt","tokyo",""`, []string{"tokyo", "zermatt"}, []string{}},
+		{"missing IDs listed", `"atlantis","tokyo","eldorado","atlantis"`, []string{"tokyo"}, []string{"atlantis", "eldorado"}},
+		{"all missing", `"atlantis"`, []string{}, []string{"atlantis"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got struct {
+				resultList
+				NotFound []string `json:"not_found"`
+			}
+			decodeData(t, serve(router, http.MethodPost, "/api/destinations/batch", `{"ids":[`+tt.ids+`]}`), http.StatusOK, &got)
+			if !slices.Equal(got.ids(), tt.want) {
+				t.Errorf("destinations = %v, want %v", got.ids(), tt.want)
+			}
+			if got.NotFound == nil || !slices.Equal(got.NotFound, tt.wantNotFound) {
+				t.Errorf("not_found = %#v, want %v", got.NotFound, tt.wantNotFound)
+			}
+		})
+	}
+}
+
+func TestGetDestinationsBatchLimits(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	ids := func(n int) string {
+		quoted := make([]string, n)
+		for i := range quoted {
+			quoted[i] = fmt.Sprintf(`"id-%d"`, i)
+		}
+		return `{"ids":[` + strings.Join(quoted, ",") + `]}`
+	}
+
+	if rec := serve(router, http.MethodPost, "/api/destinations/batch", ids(maxBatchIDs)); rec.Code != http.StatusOK {
+		t.Errorf("%d IDs: status = %d, want 200", maxBatchIDs, rec.Code)
+	}
+	tests := []struct {
+		name string
+		body string
+		// wantMsg is a substring of the expected error message
+		wantMsg string
+	}{
+		{"over the cap", ids(maxBatchIDs + 1), "at most 100 destinations, got 101"},
+		{"empty", `{"ids":[]}`, "at least one destination"},
+		{"only blanks", `{"ids":["",""]}`, "at least one destination"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := decodeError(t, serve(router, http.MethodPost, "/api/destinations/batch", tt.body), http.StatusBadRequest)
+			if !strings.Contains(got.Message, tt.wantMsg) {
+				t.Errorf("message = %q, want %q", got.Message, tt.wantMsg)
+			}
+		})
+	}
+}
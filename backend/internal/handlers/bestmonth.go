@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/simonryrie/otherwhere/internal/ranking"
+	"github.com/simonryrie/otherwhere/internal/store"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// GetBestMonth suggests the most pleasant month to visit a destination. The
+// comfort_min and comfort_max query parameters set the comfortable
+// temperature range in the requested units.
+func (h *Handler) GetBestMonth(w http.ResponseWriter, r *http.Request) error {
+	id, err := destinationID(r)
+	if err != nil {
+		return badRequest(err)
+	}
+	unit, err := parseUnits(r)
+	if err != nil {
+		return badRequest(err)
+	}
+	minC, err := tempParam(r, "comfort_min", unit, ranking.DefaultComfortMinC)
+	if err != nil {
+		return badRequest(err)
+	}
+	maxC, err := tempParam(r, "comfort_max", unit, ranking.DefaultComfortMaxC)
+	if err != nil {
+		return badRequest(err)
+	}
+	if minC > maxC {
+		return badRequestf("comfort_min must not be greater than comfort_max")
+	}
+	slog.Info("GET /api/destinations/:id/best-month", "id", id)
+
+	destination, err := storeFromContext(r.Context()).Get(r.Context(), id)
+	if errors.Is(err, store.ErrNotFound) {
+		return notFound("destination not found")
+	}
+	if err != nil {
+		return fmt.Errorf("get destination %s: %w", id, err)
+	}
+
+	pick, err := ranking.BestMonth(destination, minC, maxC)
+	if errors.Is(err, ranking.ErrNoSeasonalData) {
+		return unprocessable("destination has only annual temperature data, not monthly")
+	}
+	if err != nil {
+		return err
+	}
+
+	writeData(w, r, http.StatusOK, types.BestMonthResponse{
+		ID:        destination.ID,
+		Month:     pick.Month,
+		MonthName: time.Month(pick.Month).String(),
+		Temperature: types.Temperature{
+			Avg:  displayTemp(pick.TempC, unit),
+			Unit: unit,
+		},
+		Rationale: bestMonthRationale(pick, minC, maxC, unit),
+	})
+	return nil
+}
+
+// tempParam reads an optional temperature query parameter given in unit,
+// returning it in °C
+func tempParam(r *http.Request, name string, unit types.TemperatureUnit, defC float64) (float64, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return defC, nil
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("%s must be a finite number", name)
+	}
+	if unit == types.Fahrenheit {
+		v = (v - 32) * 5 / 9
+	}
+	return v, nil
+}
+
+// bestMonthRationale explains a MonthPick in a sentence
+func bestMonthRationale(pick ranking.MonthPick, minC, maxC float64, unit types.TemperatureUnit) string {
+	symbol := "°" + strings.ToUpper(string(unit))
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s averages %g %s", time.Month(pick.Month), displayTemp(pick.TempC, unit), symbol)
+	if pick.InComfortRange {
+		b.WriteString(", within")
+	} else {
+		b.WriteString(", the closest month to")
+	}
+	fmt.Fprintf(&b, " the comfortable %g–%g %s range", displayTemp(minC, unit), displayTemp(maxC, unit), symbol)
+	if pick.Skiing {
+		b.WriteString(", and is cold enough for skiing")
+	}
+	if pick.WaterSports {
+		b.WriteString(", and is warm enough for water sports")
+	}
+	b.WriteString(".")
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestGetBestMonth(t *testing.T) {
+	// Lisbon's monthly temperatures, 11-24 °C, on the normalized -15 to 45 °C scale
+	lisbon := types.Destination{ID: "lisbon", Name: "Lisbon", Active: true, Features: types.DestinationFeatures{
+		MonthlyTempC: [12]float64{26. / 60, 27. / 60, 29. / 60, 31. / 60, 32. / 60, 36. / 60, 38. / 60, 39. / 60, 37. / 60, 33. / 60, 29. / 60, 27. / 60},
+	}}
+	router := newTestRouter(testConfig(t), append([]types.Destination{lisbon}, testDestinations...))
+
+	tests := []struct {
+		name, query string
+		month       int
+		rationale   string
+	}{
+		{"default range", "", 6, "June averages 21 °C, within the comfortable 18–26 °C range."},
+		{"custom range", "?comfort_min=23&comfort_max=30", 7, "July averages 23 °C, within the comfortable 23–30 °C range."},
+		{"fahrenheit", "?units=f&comfort_min=73&comfort_max=86", 7, "July averages 73.4 °F, within the comfortable 73–86 °F range."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got types.BestMonthResponse
+			decodeData(t, serve(router, http.MethodGet, "/api/destinations/lisbon/best-month"+tt.query, ""), http.StatusOK, &got)
+			if got.Month != tt.month || got.MonthName != strings.Fields(tt.rationale)[0] || got.Rationale != tt.rationale {
+				t.Errorf("best month = %d %s %q, want %d %q", got.Month, got.MonthName, got.Rationale, tt.month, tt.rationale)
+			}
+		})
+	}
+
+	errs := []struct {
+		name, target string
+		status       int
+	}{
+		{"annual data only", "/api/destinations/tokyo/best-month", http.StatusUnprocessableEntity},
+		{"unknown destination", "/api/destinations/atlantis/best-month", http.StatusNotFound},
+		{"inverted range", "/api/destinations/lisbon/best-month?comfort_min=30&comfort_max=20", http.StatusBadRequest},
+		{"non-numeric bound", "/api/destinations/lisbon/best-month?comfort_min=warm", http.StatusBadRequest},
+	}
+	for _, tt := range errs {
+		t.Run(tt.name, func(t *testing.T) {
+			decodeError(t, serve(router, http.MethodGet, tt.target, ""), tt.status)
+		})
+	}
+}
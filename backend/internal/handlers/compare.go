@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/simonryrie/otherwhere/internal/ranking"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// Bounds on how many destinations a comparison can include
+const (
+	minCompareIDs = 2
+	maxCompareIDs = 5
+)
+
+// Compare returns the requested destinations with a per-feature comparison matrix
+func (h *Handler) Compare(w http.ResponseWriter, r *http.Request) error {
+	slog.Info("POST /api/compare")
+
+	var req types.CompareRequest
+	if err := decodeJSON(r, &req); err != nil {
+		return err
+	}
+	view, err := h.viewOptions(r)
+	if err != nil {
+		return badRequest(err)
+	}
+
+	ids := dedupe(req.IDs)
+	if len(ids) < minCompareIDs || len(ids) > maxCompareIDs {
+		return badRequestf("ids must list between %d and %d distinct destinations, got %d", minCompareIDs, maxCompareIDs, len(ids))
+	}
+
+	destinations, missing, err := getDestinations(r, ids)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return notFound("destinations not found: " + strings.Join(missing, ", "))
+	}
+
+	writeData(w, r, http.StatusOK, types.CompareResponse{
+		Destinations: newDestinationViews(destinations, view),
+		Features:     h.redactComparisons(ranking.Compare(destinations)),
+	})
+	return nil
+}
+
+// dedupe drops repeated and empty IDs, keeping first-seen order
+func dedupe(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestCompare(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+
+	var got struct {
+		Destinations []struct {
+			ID string `json:"id"`
+		} `json:"destinations"`
+		Features []types.FeatureComparison `json:"features"`
+	}
+	decodeData(t, serve(router, http.MethodPost, "/api/compare", `{"ids":["tokyo","zermatt","lofoten"]}`), http.StatusOK, &got)
+	if len(got.Destinations) != 3 || got.Destinations[0].ID != "tokyo" || got.Destinations[2].ID != "lofoten" {
+		t.Fatalf("destinations = %+v, want tokyo, zermatt, lofoten in request order", got.Destinations)
+	}
+	i := slices.IndexFunc(got.Features, func(c types.FeatureComparison) bool { return c.Key == "population" })
+	if i < 0 {
+		t.Fatal("no population comparison")
+	}
+	pop := got.Features[i]
+	if pop.Min != 0.02 || pop.Max != 1 || !slices.Equal(pop.Values, []float64{1, 0.05, 0.02}) {
+		t.Errorf("population = %+v, want min 0.02, max 1", pop)
+	}
+	if pop.Normalized[0] != 1 || pop.Normalized[2] != 0 {
+		t.Errorf("population normalized = %v, want tokyo 1 and lofoten 0", pop.Normalized)
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		// wantMsg is a substring of the expected error message
+		wantMsg string
+	}{
+		{"oversized set", `{"ids":["a","b","c","d","e","f"]}`, http.StatusBadRequest, "between 2 and 5"},
+		{"single destination", `{"ids":["tokyo","tokyo"]}`, http.StatusBadRequest, "got 1"},
+		{"missing ID", `{"ids":["tokyo","atlantis"]}`, http.StatusNotFound, "destinations not found: atlantis"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := decodeError(t, serve(router, http.MethodPost, "/api/compare", tt.body), tt.status)
+			if !strings.Contains(got.Message, tt.wantMsg) {
+				t.Errorf("message = %q, want %q", got.Message, tt.wantMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+)
+
+// Compress gzips responses for clients that accept it once the body reaches
+// minSize bytes; smaller bodies are sent as-is since gzip would barely shrink
+// them. Responses that already set Content-Encoding pass through untouched.
+func Compress(minSize int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			gw := &gzipWriter{ResponseWriter: w, minSize: minSize, status: http.StatusOK}
+			defer gw.close()
+			next.ServeHTTP(gw, r)
+		})
+	}
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+		q := strings.ReplaceAll(params, " ", "")
+		return q != "q=0" && q != "q=0.0" && q != "q=0.00" && q != "q=0.000"
+	}
+	return false
+}
+
+// gzipWriter buffers the start of a response until it knows whether the body
+// is large enough to compress
+type gzipWriter struct {
+	http.ResponseWriter
+	minSize int
+
+	status int
+	buf    []byte
+	gz     *gzip.Writer
+	// sent is set once the status line has been passed to ResponseWriter
+	sent bool
+}
+
+func (g *gzipWriter) WriteHeader(status int) {
+	if !g.sent {
+		g.status = status
+	}
+}
+
+func (g *gzipWriter) Write(p []byte) (int, error) {
+	if g.gz != nil {
+		return g.gz.Write(p)
+	}
+	if g.sent {
+		return g.ResponseWriter.Write(p)
+	}
+
+	g.buf = append(g.buf, p...)
+	if len(g.buf) < g.minSize {
+		return len(p), nil
+	}
+	if err := g.start(); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// start sends the headers and buffered bytes, compressing when the response
+// can carry a gzip body
+func (g *gzipWriter) start() error {
+	h := g.Header()
+	g.sent = true
+	if h.Get("Content-Encoding") != "" || g.status < http.StatusOK ||
+		g.status == http.StatusNoContent || g.status == http.StatusNotModified {
+		g.ResponseWriter.WriteHeader(g.status)
+		_, err := g.ResponseWriter.Write(g.buf)
+		return err
+	}
+
+	h.Set("Content-Encoding", "gzip")
+	h.Del("Content-Length")
+	g.ResponseWriter.WriteHeader(g.status)
+	g.gz = gzip.NewWriter(g.ResponseWriter)
+	_, err := g.gz.Write(g.buf)
+	return err
+}
+
+// FlushError sends what has been written so far, compressing it regardless
+// of minSize since a flushing handler is streaming
+func (g *gzipWriter) FlushError() error {
+	if !g.sent {
+		if err := g.start(); err != nil {
+			return err
+		}
+	}
+	if g.gz != nil {
+		if err := g.gz.Flush(); err != nil {
+			return err
+		}
+	}
+	return http.NewResponseController(g.ResponseWriter).Flush()
+}
+
+// close flushes whatever the handler wrote: the gzip trailer for compressed
+// responses, or the small buffered body for uncompressed ones
+func (g *gzipWriter) close() {
+	if g.gz != nil {
+		g.gz.Close()
+		return
+	}
+	if !g.sent {
+		g.sent = true
+		g.ResponseWriter.WriteHeader(g.status)
+		g.ResponseWriter.Write(g.buf)
+	}
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat(`{"id":"tokyo","name":"Tokyo"},`, 200)
+	body := func(s string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, s)
+		})
+	}
+	tests := []struct {
+		name           string
+		handler        http.Handler
+		acceptEncoding string
+		wantGzip       bool
+		want           string
+	}{
+		{"large body", body(large), "gzip, deflate", true, large},
+		{"small body", body(`{"status":"ok"}`), "gzip", false, `{"status":"ok"}`},
+		{"client without gzip", body(large), "br", false, large},
+		{"gzip refused", body(large), "gzip;q=0, br", false, large},
+		{"already encoded", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "identity")
+			io.WriteString(w, large)
+		}), "gzip", false, large},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			rec := httptest.NewRecorder()
+			Compress(1024)(tt.handler).ServeHTTP(rec, req)
+
+			if vary := rec.Header().Get("Vary"); vary != "Accept-Encoding" {
+				t.Errorf("Vary = %q, want Accept-Encoding", vary)
+			}
+			gzipped := rec.Header().Get("Content-Encoding") == "gzip"
+			if gzipped != tt.wantGzip {
+				t.Fatalf("gzip-encoded = %t, want %t", gzipped, tt.wantGzip)
+			}
+			var r io.Reader = rec.Body
+			if gzipped {
+				gz, err := gzip.NewReader(rec.Body)
+				if err != nil {
+					t.Fatalf("gzip reader: %v", err)
+				}
+				r = gz
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("read body: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("body = %.40q..., want %.40q...", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"GZIP", true},
+		{"deflate, gzip;q=0.5", true},
+		{"gzip; q=0", false},
+		{"gzip;q=0.000", false},
+		{"identity", false},
+	}
+	for _, tt := range tests {
+		if got := acceptsGzip(tt.header); got != tt.want {
+			t.Errorf("acceptsGzip(%q) = %t, want %t", tt.header, got, tt.want)
+		}
+	}
+}
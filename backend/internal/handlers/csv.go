@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/simonryrie/otherwhere/internal/ranking"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// csvFlushEvery is how many rows are written between flushes
+const csvFlushEvery = 100
+
+// csvIdentityColumns lead every CSV row, before the feature columns
+var csvIdentityColumns = []string{"id", "name", "country", "continent", "region", "type", "lat", "lon", "tags"}
+
+// GetDestinationsCSV streams destinations as CSV for spreadsheets, with the
+// same geographic filters and sort as GetDestinations. Each row holds the
+// identity fields, then every feature in vector order, then the twelve
+// monthly temperatures. Tags are joined with "|". Feature values are on the
+// normalized [0, 1] scale.
+func (h *Handler) GetDestinationsCSV(w http.ResponseWriter, r *http.Request) error {
+	destinations, err := h.listDestinations(r)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="destinations.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	cw := csv.NewWriter(w)
+	cw.Write(h.csvHeader())
+	for i, d := range destinations {
+		if err := r.Context().Err(); err != nil {
+			requestLogger(r).Warn("csv stream aborted", "error", err, "written", i)
+			return nil
+		}
+		cw.Write(h.csvRow(d))
+
+		if (i+1)%csvFlushEvery == 0 {
+			cw.Flush()
+			if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				return nil
+			}
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		requestLogger(r).Warn("csv stream aborted", "error", err)
+	}
+	return nil
+}
+
+// csvHeader names the CSV columns, leaving out redacted features
+func (h *Handler) csvHeader() []string {
+	header := append([]string{}, csvIdentityColumns...)
+	for _, feat := range ranking.Features {
+		if !h.redacted(feat.Key) {
+			header = append(header, feat.Key)
+		}
+	}
+	if !h.redacted("avg_temp_c") {
+		for m := 1; m <= 12; m++ {
+			header = append(header, fmt.Sprintf("monthly_temp_c_%d", m))
+		}
+	}
+	return header
+}
+
+// csvRow flattens d into the columns named by csvHeader
+func (h *Handler) csvRow(d types.Destination) []string {
+	region := ""
+	if d.Region != nil {
+		region = *d.Region
+	}
+	row := []string{
+		d.ID, d.Name, d.Country, string(d.Continent), region, string(d.Type),
+		formatFloat(d.Location.Lat), formatFloat(d.Location.Lon),
+		strings.Join(d.Tags, "|"),
+	}
+	for i, v := range ranking.Vector(d.Features) {
+		if !h.redacted(ranking.Features[i].Key) {
+			row = append(row, formatFloat(v))
+		}
+	}
+	if !h.redacted("avg_temp_c") {
+		for _, v := range d.Features.MonthlyTempC {
+			row = append(row, formatFloat(v))
+		}
+	}
+	return row
+}
+
+// formatFloat formats v with the fewest digits that round-trip
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
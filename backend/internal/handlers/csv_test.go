@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/ranking"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// readCSV parses a CSV export body into its header and rows keyed by column
+func readCSV(t *testing.T, body string) ([]string, []map[string]string) {
+	t.Helper()
+	records, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if len(records) == 0 {
+		t.Fatal("csv has no header row")
+	}
+	header := records[0]
+	rows := make([]map[string]string, len(records)-1)
+	for i, rec := range records[1:] {
+		rows[i] = make(map[string]string, len(header))
+		for j, col := range header {
+			rows[i][col] = rec[j]
+		}
+	}
+	return header, rows
+}
+
+func TestGetDestinationsCSV(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	rec := serve(router, http.MethodGet, "/api/destinations.csv?continent=Europe&sort=name", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; body: %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/csv; charset=utf-8" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	header, rows := readCSV(t, rec.Body.String())
+
+	if !slices.Equal(header[:len(csvIdentityColumns)], csvIdentityColumns) {
+		t.Errorf("header starts %q, want %q", header[:len(csvIdentityColumns)], csvIdentityColumns)
+	}
+	if want := len(csvIdentityColumns) + len(ranking.Features) + 12; len(header) != want {
+		t.Errorf("header has %d columns, want %d", len(header), want)
+	}
+	if len(rows) != 2 || rows[0]["id"] != "lofoten" || rows[1]["id"] != "zermatt" {
+		t.Fatalf("rows = %v, want lofoten then zermatt", rows)
+	}
+
+	zermatt := rows[1]
+	tests := []struct{ column, want string }{
+		{"name", "Zermatt"},
+		{"country", "Switzerland"},
+		{"continent", "Europe"},
+		{"region", "Valais"},
+		{"lat", "46.02"},
+		{"lon", "7.75"},
+		{"tags", "ski|alpine"},
+		{"skiing_score", "0.95"},
+		{"coast_distance_km", "0.6"},
+	}
+	for _, tt := range tests {
+		if got := zermatt[tt.column]; got != tt.want {
+			t.Errorf("zermatt %s = %q, want %q", tt.column, got, tt.want)
+		}
+	}
+	if rows[0]["region"] != "" {
+		t.Errorf("lofoten region = %q, want empty", rows[0]["region"])
+	}
+}
+
+func TestGetDestinationsCSVQuotes(t *testing.T) {
+	d := testDestinations[0]
+	d.Name = `Tamarindo, "Tama"`
+	router := newTestRouter(testConfig(t), []types.Destination{d})
+	rec := serve(router, http.MethodGet, "/api/destinations.csv", "")
+	if !strings.Contains(rec.Body.String(), `"Tamarindo, ""Tama"""`) {
+		t.Errorf("name with a comma wasn't quoted: %s", rec.Body)
+	}
+	if _, rows := readCSV(t, rec.Body.String()); rows[0]["name"] != d.Name {
+		t.Errorf("name = %q, want %q", rows[0]["name"], d.Name)
+	}
+}
+
+func TestGetDestinationsCSVRejectsBadFilters(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	for _, query := range []string{"continent=Atlantis", "sort=vibes"} {
+		if rec := serve(router, http.MethodGet, "/api/destinations.csv?"+query, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, rec.Code)
+		}
+	}
+}
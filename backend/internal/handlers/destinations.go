@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/simonryrie/otherwhere/internal/ranking"
+	"github.com/simonryrie/otherwhere/internal/store"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// GetDestinations returns a page of destinations, or streams all of them
+// as NDJSON with format=ndjson
+func (h *Handler) GetDestinations(w http.ResponseWriter, r *http.Request) error {
+	slog.Info("GET /api/destinations")
+
+	p, err := parsePage(r, h.destinationsPage)
+	if err != nil {
+		return badRequest(err)
+	}
+	view, err := h.viewOptions(r)
+	if err != nil {
+		return badRequest(err)
+	}
+	fields, err := parseFields(r)
+	if err != nil {
+		return badRequest(err)
+	}
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "ndjson" {
+		return badRequestf("format must be ndjson")
+	}
+
+	destinations, err := h.listDestinations(r)
+	if err != nil {
+		return err
+	}
+	if format == "ndjson" {
+		return writeNDJSON(w, r, destinations, view, fields)
+	}
+	writeFields(w, r, http.StatusOK, types.DestinationsResponse{
+		Destinations: newDestinationViews(paginate(destinations, p), view),
+		Total:        len(destinations),
+		Meta:         p.meta(len(destinations)),
+	}, fields)
+	return nil
+}
+
+// listDestinations returns the destinations matching the request's
+// geographic filters and tier, ordered by its sort parameter
+func (h *Handler) listDestinations(r *http.Request) ([]types.Destination, error) {
+	filters, err := parseGeoFilters(r)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+	tier, err := tierParam(r)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+	sortKey := r.URL.Query().Get("sort")
+	if sortKey == "" {
+		sortKey = ranking.DefaultSort
+	}
+
+	destinations, err := storeFromContext(r.Context()).List(r.Context())
+	if err != nil {
+		return nil, fmt.Errorf("list destinations: %w", err)
+	}
+	destinations = ranking.ApplyFilters(destinations, filters)
+	if tier != "" {
+		destinations = ranking.FilterByTier(destinations, tier, h.tiers)
+	}
+	if err := ranking.SortDestinations(destinations, sortKey); err != nil {
+		return nil, badRequest(err)
+	}
+	return destinations, nil
+}
+
+// GetDestination returns a single destination by ID
+func (h *Handler) GetDestination(w http.ResponseWriter, r *http.Request) error {
+	id, err := destinationID(r)
+	if err != nil {
+		return badRequest(err)
+	}
+	view, err := h.viewOptions(r)
+	if err != nil {
+		return badRequest(err)
+	}
+	fields, err := parseFields(r)
+	if err != nil {
+		return badRequest(err)
+	}
+	slog.Info("GET /api/destinations/:id", "id", id)
+
+	destination, err := storeFromContext(r.Context()).Get(r.Context(), id)
+	if errors.Is(err, store.ErrNotFound) {
+		return notFound("destination not found")
+	}
+	if err != nil {
+		return fmt.Errorf("get destination %s: %w", id, err)
+	}
+
+	writeFields(w, r, http.StatusOK, newDestinationView(destination, view), fields)
+	return nil
+}
+
+// GetRandomDestination returns one destination picked at random, optionally
+// limited to those matching geographic filters in the query string
+func (h *Handler) GetRandomDestination(w http.ResponseWriter, r *http.Request) error {
+	filters, err := parseGeoFilters(r)
+	if err != nil {
+		return badRequest(err)
+	}
+	view, err := h.viewOptions(r)
+	if err != nil {
+		return badRequest(err)
+	}
+	fields, err := parseFields(r)
+	if err != nil {
+		return badRequest(err)
+	}
+	slog.Info("GET /api/destinations/random")
+
+	destinations, err := storeFromContext(r.Context()).List(r.Context())
+	if err != nil {
+		return fmt.Errorf("list destinations: %w", err)
+	}
+	destinations = ranking.ApplyFilters(destinations, filters)
+	if len(destinations) == 0 {
+		return notFound("no destinations match the filters")
+	}
+
+	// Sort first so a seeded pick doesn't depend on store order
+	if err := ranking.SortDestinations(destinations, ranking.DefaultSort); err != nil {
+		return err
+	}
+	pick := destinations[h.randIntN(len(destinations))]
+	writeFields(w, r, http.StatusOK, newDestinationView(pick, view), fields)
+	return nil
+}
+
+// GetSimilarDestinations returns the destinations closest in vibe to the given one
+func (h *Handler) GetSimilarDestinations(w http.ResponseWriter, r *http.Request) error {
+	id, err := destinationID(r)
+	if err != nil {
+		return badRequest(err)
+	}
+	limit, err := intParam(r, "limit")
+	if err != nil {
+		return badRequest(err)
+	}
+	p, err := newPage(limit, 0, h.similarPage)
+	if err != nil {
+		return badRequest(err)
+	}
+	view, err := h.viewOptions(r)
+	if err != nil {
+		return badRequest(err)
+	}
+	fields, err := parseFields(r)
+	if err != nil {
+		return badRequest(err)
+	}
+	slog.Info("GET /api/destinations/:id/similar", "id", id, "limit", p.limit)
+
+	source, err := storeFromContext(r.Context()).Get(r.Context(), id)
+	if errors.Is(err, store.ErrNotFound) {
+		return notFound("destination not found")
+	}
+	if err != nil {
+		return fmt.Errorf("get destination %s: %w", id, err)
+	}
+
+	destinations, err := storeFromContext(r.Context()).List(r.Context())
+	if err != nil {
+		return fmt.Errorf("list destinations: %w", err)
+	}
+
+	results := ranking.Similar(source, destinations, p.limit)
+	similar := make([]types.Destination, len(results))
+	for i, res := range results {
+		similar[i] = res.Destination
+	}
+
+	writeFields(w, r, http.StatusOK, types.DestinationsResponse{
+		Destinations: newDestinationViews(similar, view),
+		Total:        len(similar),
+		Meta:         p.meta(len(similar)),
+	}, fields)
+	return nil
+}
+
+// destinationID extracts and validates the {id} route parameter
+func destinationID(r *http.Request) (string, error) {
+	id, err := url.PathUnescape(chi.URLParam(r, "id"))
+	if err != nil || id == "" {
+		return "", errors.New("destination id is required")
+	}
+	if strings.ContainsAny(id, `/\`) {
+		return "", errors.New("destination id must not contain path separators")
+	}
+	return id, nil
+}
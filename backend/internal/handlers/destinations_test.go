@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestGetDestination(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+
+	var got struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	decodeData(t, serve(router, http.MethodGet, "/api/destinations/zermatt", ""), http.StatusOK, &got)
+	if got.ID != "zermatt" || got.Name != "Zermatt" {
+		t.Errorf("got %+v, want zermatt", got)
+	}
+
+	tests := []struct {
+		name   string
+		target string
+		status int
+	}{
+		{"not found", "/api/destinations/atlantis", http.StatusNotFound},
+		{"path separator", "/api/destinations/a%2Fb", http.StatusBadRequest},
+		{"backslash", "/api/destinations/a%5Cb", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decodeError(t, serve(router, http.MethodGet, tt.target, ""), tt.status)
+		})
+	}
+}
+
+func TestDestinationID(t *testing.T) {
+	tests := []struct {
+		param   string
+		want    string
+		wantErr bool
+	}{
+		{"tokyo", "tokyo", false},
+		{"s%C3%A3o-paulo", "são-paulo", false},
+		{"", "", true},
+		{"a%2Fb", "", true},
+		{"%zz", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.param, func(t *testing.T) {
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.param)
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+			got, err := destinationID(r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("destinationID(%q) error = %v, want error %t", tt.param, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("destinationID(%q) = %q, want %q", tt.param, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetDestinationsSort(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	tests := []struct {
+		sort string
+		want []string
+	}{
+		{"", []string{"lofoten", "tamarindo", "tokyo", "zermatt"}},
+		{"population", []string{"lofoten", "zermatt", "tamarindo", "tokyo"}},
+		{"-population", []string{"tokyo", "tamarindo", "zermatt", "lofoten"}},
+		{"temp", []string{"zermatt", "lofoten", "tokyo", "tamarindo"}},
+		{"-temp", []string{"tamarindo", "tokyo", "lofoten", "zermatt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			var got resultList
+			decodeData(t, serve(router, http.MethodGet, "/api/destinations?sort="+tt.sort, ""), http.StatusOK, &got)
+			if !slices.Equal(got.ids(), tt.want) {
+				t.Errorf("sort=%s = %v, want %v", tt.sort, got.ids(), tt.want)
+			}
+		})
+	}
+
+	for _, key := range []string{"altitude", "--name", "+name"} {
+		t.Run(key, func(t *testing.T) {
+			got := decodeError(t, serve(router, http.MethodGet, "/api/destinations?sort="+url.QueryEscape(key), ""), http.StatusBadRequest)
+			if !strings.Contains(got.Message, "unknown sort key") {
+				t.Errorf("message = %q, want an unknown sort key error", got.Message)
+			}
+		})
+	}
+}
+
+func TestGetRandomDestination(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.RandomSeed = 42
+	picks := func(target string) []string {
+		// A fresh router restarts the seeded sequence
+		router := newTestRouter(cfg, testDestinations)
+		out := make([]string, 8)
+		for i := range out {
+			var got struct {
+				ID        string `json:"id"`
+				Continent string `json:"continent"`
+			}
+			decodeData(t, serve(router, http.MethodGet, target, ""), http.StatusOK, &got)
+			out[i] = got.ID + "/" + got.Continent
+		}
+		return out
+	}
+
+	tests := []struct {
+		target    string
+		continent string
+	}{
+		{"/api/destinations/random", ""},
+		{"/api/destinations/random?continent=Europe", "Europe"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			first := picks(tt.target)
+			if second := picks(tt.target); !slices.Equal(first, second) {
+				t.Errorf("picks under seed 42 differ: %v then %v", first, second)
+			}
+			if distinct := slices.Compact(slices.Sorted(slices.Values(first))); len(distinct) < 2 {
+				t.Errorf("every pick is %s", first[0])
+			}
+			for _, pick := range first {
+				if tt.continent != "" && !strings.HasSuffix(pick, "/"+tt.continent) {
+					t.Errorf("pick %s outside %s", pick, tt.continent)
+				}
+			}
+		})
+	}
+
+	router := newTestRouter(cfg, testDestinations)
+	got := decodeError(t, serve(router, http.MethodGet, "/api/destinations/random?continent=Africa", ""), http.StatusNotFound)
+	if got.Message != "no destinations match the filters" {
+		t.Errorf("message = %q", got.Message)
+	}
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/simonryrie/otherwhere/internal/ranking"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// DiagnoseSearch explains which feature constraints of a search, from its
+// query keywords and explicit constraints, are cutting down the results.
+// Geographic filters, tags, and exclusions narrow the candidates first.
+func (h *Handler) DiagnoseSearch(w http.ResponseWriter, r *http.Request) error {
+	var req types.SearchRequest
+	if err := decodeStrictJSON(r, &req); err != nil {
+		return err
+	}
+	if err := h.resolveAliases(&req); err != nil {
+		return badRequest(err)
+	}
+	h.clampSearchRequest(r, &req)
+	if err := validateSearchRequest(req, h.maxComplexity); err != nil {
+		return badRequest(err)
+	}
+
+	constraints, err := ranking.ParseQuery(req.Query)
+	if err != nil {
+		return badRequest(err)
+	}
+	if req.Constraints != nil {
+		if constraints, err = ranking.MergeConstraints(constraints, *req.Constraints); err != nil {
+			return badRequest(err)
+		}
+	}
+
+	destinations, err := storeFromContext(r.Context()).List(r.Context())
+	if err != nil {
+		return fmt.Errorf("list destinations: %w", err)
+	}
+	destinations = ranking.ApplyFilters(destinations, req.Filters)
+	destinations = ranking.FilterByTags(destinations, types.NormalizeTags(req.Tags), types.NormalizeTags(req.AnyTags))
+	destinations = ranking.ExcludeIDs(destinations, dedupe(req.Exclude))
+
+	writeData(w, r, http.StatusOK, ranking.Diagnose(destinations, constraints))
+	return nil
+}
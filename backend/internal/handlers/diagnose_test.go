@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestDiagnoseSearch(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	tests := []struct {
+		name, body string
+		want       types.DiagnoseResponse
+	}{
+		{
+			// Tamarindo and tokyo fail only on hiking, zermatt only on warmth
+			"hiking is the bottleneck",
+			`{"constraints":{"hiking_score":{"min":0.5},"elevation":{"max":0.5},"avg_temp_c":{"min":0.28}}}`,
+			types.DiagnoseResponse{
+				Candidates: 4, Matching: 1, MostLimiting: "hiking_score",
+				Constraints: []types.ConstraintDiagnosis{
+					{Feature: "avg_temp_c", Eliminated: 1, WouldAdd: 1},
+					{Feature: "elevation"},
+					{Feature: "hiking_score", Eliminated: 2, WouldAdd: 2},
+				},
+			},
+		},
+		{
+			"filters narrow the candidates first",
+			`{"filters":{"continent":"Europe"},"constraints":{"hiking_score":{"min":0.5},"avg_temp_c":{"min":0.28}}}`,
+			types.DiagnoseResponse{
+				Candidates: 2, Matching: 1, MostLimiting: "avg_temp_c",
+				Constraints: []types.ConstraintDiagnosis{
+					{Feature: "avg_temp_c", Eliminated: 1, WouldAdd: 1},
+					{Feature: "hiking_score"},
+				},
+			},
+		},
+		{
+			"no constraints",
+			`{}`,
+			types.DiagnoseResponse{Candidates: 4, Matching: 4, Constraints: []types.ConstraintDiagnosis{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got types.DiagnoseResponse
+			decodeData(t, serve(router, http.MethodPost, "/api/search/diagnose", tt.body), http.StatusOK, &got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diagnosis = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	var keywords types.DiagnoseResponse
+	decodeData(t, serve(router, http.MethodPost, "/api/search/diagnose", `{"query":"ski"}`), http.StatusOK, &keywords)
+	if len(keywords.Constraints) == 0 || keywords.Matching != 1 {
+		t.Errorf("query keywords diagnosis = %+v, want its constraints diagnosed with zermatt matching", keywords)
+	}
+
+	got := decodeError(t, serve(router, http.MethodPost, "/api/search/diagnose", `{"constraints":{"hiking_score":{"min":0.9,"max":0.1}}}`), http.StatusBadRequest)
+	if !strings.Contains(got.Message, "hiking_score") {
+		t.Errorf("message = %q, want the bad constraint named", got.Message)
+	}
+}
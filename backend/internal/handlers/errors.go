@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// Error codes returned in structured error bodies
+const (
+	codeBadRequest       = "bad_request"
+	codeNotFound         = "not_found"
+	codeUnprocessable    = "unprocessable"
+	codeMethodNotAllowed = "method_not_allowed"
+	codeUnauthorized     = "unauthorized"
+	codeInternal         = "internal_error"
+	codeTimeout          = "timeout"
+	codeTooLarge         = "payload_too_large"
+	codeUnsupportedMedia = "unsupported_media_type"
+)
+
+// apiError is a structured error returned to API clients
+type apiError struct {
+	Status  int    `json:"-"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Errors lists each problem separately when a request has several
+	Errors []string `json:"errors,omitempty"`
+}
+
+// errorResponse is the JSON envelope for every error body
+type errorResponse struct {
+	Error apiError            `json:"error"`
+	Meta  *types.ResponseMeta `json:"meta,omitempty"`
+}
+
+// writeError writes a {"error":{"code":...,"message":...},"meta":...} body
+// with the given status
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, msg string) {
+	writeJSON(w, status, errorResponse{
+		Error: apiError{Status: status, Code: code, Message: msg},
+		Meta:  responseMeta(r),
+	})
+}
+
+// NotFound handles requests for routes that don't exist
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, http.StatusNotFound, codeNotFound, "route not found")
+}
+
+// MethodNotAllowed handles requests using an unsupported method on a known route
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+}
+
+// Sentinel errors a handler can return to choose the response status
+var (
+	ErrBadRequest = errors.New("bad request")
+	ErrNotFound   = errors.New("not found")
+	// ErrUnprocessable means the request was valid but can't be answered
+	// for the targeted resource
+	ErrUnprocessable = errors.New("unprocessable")
+	ErrTooLarge      = errors.New("request body too large")
+)
+
+// clientError is an error whose message is safe to show to API clients.
+// It unwraps to the sentinel describing its kind.
+type clientError struct {
+	kind error
+	msg  string
+	// details are the individual problems msg sums up, if there are several
+	details []string
+}
+
+func (e *clientError) Error() string { return e.msg }
+
+func (e *clientError) Unwrap() error { return e.kind }
+
+// badRequest reports err to the client as a 400
+func badRequest(err error) error {
+	return &clientError{kind: ErrBadRequest, msg: err.Error()}
+}
+
+// badRequestf is badRequest with a formatted message
+func badRequestf(format string, args ...any) error {
+	return &clientError{kind: ErrBadRequest, msg: fmt.Sprintf(format, args...)}
+}
+
+// invalid reports every one of errs to the client in a single 400
+func invalid(errs []error) error {
+	details := make([]string, len(errs))
+	for i, err := range errs {
+		details[i] = err.Error()
+	}
+	return &clientError{kind: ErrBadRequest, msg: strings.Join(details, "; "), details: details}
+}
+
+// notFound reports msg to the client as a 404
+func notFound(msg string) error {
+	return &clientError{kind: ErrNotFound, msg: msg}
+}
+
+// tooLarge reports a request body over its limit of n bytes as a 413
+func tooLarge(n int64) error {
+	return &clientError{kind: ErrTooLarge, msg: fmt.Sprintf("request body must be at most %d bytes", n)}
+}
+
+// unprocessable reports msg to the client as a 422
+func unprocessable(msg string) error {
+	return &clientError{kind: ErrUnprocessable, msg: msg}
+}
+
+// Handle adapts a handler that returns errors into an http.HandlerFunc.
+// Errors wrapping ErrBadRequest, ErrNotFound, ErrTooLarge, or
+// ErrUnprocessable become 400, 404, 413, and 422 responses with the error's
+// message, and a passed request deadline becomes a 503. Anything else,
+// including a panic, is logged and answered with a generic 500 so internal
+// details don't leak.
+func Handle(fn func(http.ResponseWriter, *http.Request) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if v := recover(); v != nil {
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				requestLogger(r).Error("handler panicked", "panic", v, "stack", string(debug.Stack()))
+				writeError(w, r, http.StatusInternalServerError, codeInternal, "internal server error")
+			}
+		}()
+
+		if err := fn(w, r); err != nil {
+			writeHandlerError(w, r, err)
+		}
+	}
+}
+
+// writeHandlerError maps an error returned by a handler to its response
+func writeHandlerError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrBadRequest):
+		var details []string
+		var ce *clientError
+		if errors.As(err, &ce) {
+			details = ce.details
+		}
+		writeJSON(w, http.StatusBadRequest, errorResponse{
+			Error: apiError{Status: http.StatusBadRequest, Code: codeBadRequest, Message: err.Error(), Errors: details},
+			Meta:  responseMeta(r),
+		})
+	case errors.Is(err, ErrNotFound):
+		writeError(w, r, http.StatusNotFound, codeNotFound, err.Error())
+	case errors.Is(err, ErrUnprocessable):
+		writeError(w, r, http.StatusUnprocessableEntity, codeUnprocessable, err.Error())
+	case errors.Is(err, ErrTooLarge):
+		writeError(w, r, http.StatusRequestEntityTooLarge, codeTooLarge, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		requestLogger(r).Warn("request timed out", "error", err)
+		writeError(w, r, http.StatusServiceUnavailable, codeTimeout, "request timed out")
+	default:
+		requestLogger(r).Error("request failed", "error", err)
+		writeError(w, r, http.StatusInternalServerError, codeInternal, "internal server error")
+	}
+}
+
+// LimitBody caps request bodies at n bytes; reading past the cap fails and
+// decodeJSON reports it as a 413
+func LimitBody(n int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, n)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireJSON rejects POST requests whose body isn't declared as
+// application/json with a 415. Parameters such as charset are allowed, and
+// POSTs without a body, such as an admin reload, pass through.
+func RequireJSON(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.ContentLength != 0 {
+			mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if err != nil || mediaType != "application/json" {
+				writeError(w, r, http.StatusUnsupportedMediaType, codeUnsupportedMedia, "request body must be application/json")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Timeout gives each request's context a deadline so store calls give up
+// on a slow backend; handlers using Handle then answer with a 503
+func Timeout(d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestErrorShape(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	tests := []struct {
+		name           string
+		method, target string
+		status         int
+		code           string
+	}{
+		{"missing destination", http.MethodGet, "/api/destinations/atlantis", http.StatusNotFound, codeNotFound},
+		{"unknown route", http.MethodGet, "/api/nowhere", http.StatusNotFound, codeNotFound},
+		{"wrong method", http.MethodDelete, "/api/destinations/tokyo", http.StatusMethodNotAllowed, codeMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(router, tt.method, tt.target, "")
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", ct)
+			}
+			var body map[string]map[string]any
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode: %v; body: %s", err, rec.Body)
+			}
+			if body["error"]["code"] != tt.code {
+				t.Errorf("error.code = %v, want %q", body["error"]["code"], tt.code)
+			}
+			if msg, _ := body["error"]["message"].(string); msg == "" {
+				t.Errorf("error.message is empty; body: %s", rec.Body)
+			}
+		})
+	}
+}
+
+func TestHandleMapsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		code    string
+		message string
+	}{
+		{"bad request", badRequestf("limit must be positive"), http.StatusBadRequest, codeBadRequest, "limit must be positive"},
+		{"wrapped sentinel", fmt.Errorf("lookup: %w", ErrNotFound), http.StatusNotFound, codeNotFound, "lookup: not found"},
+		{"not found", notFound("destination not found"), http.StatusNotFound, codeNotFound, "destination not found"},
+		{"unprocessable", unprocessable("no seasonal data"), http.StatusUnprocessableEntity, codeUnprocessable, "no seasonal data"},
+		{"too large", tooLarge(64), http.StatusRequestEntityTooLarge, codeTooLarge, "request body must be at most 64 bytes"},
+		{"deadline", fmt.Errorf("list: %w", context.DeadlineExceeded), http.StatusServiceUnavailable, codeTimeout, "request timed out"},
+		{"internal error hidden", errors.New("firestore: secret-project unavailable"), http.StatusInternalServerError, codeInternal, "internal server error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := Handle(func(http.ResponseWriter, *http.Request) error { return tt.err })
+			got := decodeError(t, serve(h, http.MethodGet, "/", ""), tt.status)
+			if got.Code != tt.code || got.Message != tt.message {
+				t.Errorf("error = %s %q, want %s %q", got.Code, got.Message, tt.code, tt.message)
+			}
+		})
+	}
+}
+
+func TestHandleRecoversPanics(t *testing.T) {
+	h := Handle(func(http.ResponseWriter, *http.Request) error { panic("nil map") })
+	got := decodeError(t, serve(h, http.MethodGet, "/", ""), http.StatusInternalServerError)
+	if got.Code != codeInternal || got.Message != "internal server error" {
+		t.Errorf("error = %s %q, want a generic 500", got.Code, got.Message)
+	}
+}
+
+func TestHandleListsEveryProblem(t *testing.T) {
+	fn := func(http.ResponseWriter, *http.Request) error {
+		return invalid([]error{errors.New("limit must be positive"), errors.New("offset must not be negative")})
+	}
+	got := decodeError(t, serve(Handle(fn), http.MethodGet, "/", ""), http.StatusBadRequest)
+	if len(got.Errors) != 2 || got.Message != "limit must be positive; offset must not be negative" {
+		t.Errorf("error = %q with %q, want both problems", got.Message, got.Errors)
+	}
+}
+
+func TestHandleRepanicsOnAbort(t *testing.T) {
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", v)
+		}
+	}()
+	serve(Handle(func(http.ResponseWriter, *http.Request) error { panic(http.ErrAbortHandler) }), http.MethodGet, "/", "")
+}
+
+// slowStore is a store whose reads block until their context ends
+type slowStore struct{}
+
+func (slowStore) List(ctx context.Context) ([]types.Destination, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (slowStore) Get(ctx context.Context, _ string) (types.Destination, error) {
+	<-ctx.Done()
+	return types.Destination{}, ctx.Err()
+}
+
+func (slowStore) GetMany(ctx context.Context, _ []string) ([]types.Destination, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestTimeoutWithSlowStore(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.RequestTimeout = 20 * time.Millisecond
+	router := newTestRouterWithStore(cfg, slowStore{})
+	tests := []struct {
+		method, target, body string
+	}{
+		{http.MethodGet, "/api/destinations", ""},
+		{http.MethodGet, "/api/destinations/tokyo", ""},
+		{http.MethodPost, "/api/search", `{"query":"ski"}`},
+		{http.MethodPost, "/api/destinations/batch", `{"ids":["tokyo"]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			start := time.Now()
+			got := decodeError(t, serve(router, tt.method, tt.target, tt.body), http.StatusServiceUnavailable)
+			if got.Code != codeTimeout {
+				t.Errorf("code = %s, want %s", got.Code, codeTimeout)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("took %v to time out", elapsed)
+			}
+		})
+	}
+}
+
+func TestLimitBody(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.MaxBodyBytes = 64
+	router := newTestRouter(cfg, testDestinations)
+	// Big enough to pass the cap while still being valid JSON
+	padded := func(prefix string) string {
+		return prefix + `,"pad":"` + strings.Repeat("x", 100) + `"}`
+	}
+	tests := []struct {
+		name, target, body string
+	}{
+		{"search", "/api/search", padded(`{"query":"beach"`)},
+		{"batch", "/api/destinations/batch", padded(`{"ids":["tokyo"]`)},
+		{"compare", "/api/compare", padded(`{"ids":["tokyo","zermatt"]`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := decodeError(t, serve(router, http.MethodPost, tt.target, tt.body), http.StatusRequestEntityTooLarge)
+			if got.Code != codeTooLarge || got.Message != "request body must be at most 64 bytes" {
+				t.Errorf("error = %s %q", got.Code, got.Message)
+			}
+		})
+	}
+
+	var ok resultList
+	decodeData(t, serve(router, http.MethodPost, "/api/search", `{"query":"beach"}`), http.StatusOK, &ok)
+}
+
+func TestRequireJSON(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	tests := []struct {
+		name, method, target string
+		contentType, body    string
+		wantStatus           int
+	}{
+		{"json", http.MethodPost, "/api/search", "application/json", `{}`, http.StatusOK},
+		{"json with charset", http.MethodPost, "/api/search", "application/json; charset=utf-8", `{}`, http.StatusOK},
+		{"json in capitals", http.MethodPost, "/api/search", "Application/JSON", `{}`, http.StatusOK},
+		{"missing", http.MethodPost, "/api/search", "", `{}`, http.StatusUnsupportedMediaType},
+		{"xml", http.MethodPost, "/api/search", "application/xml", `<search/>`, http.StatusUnsupportedMediaType},
+		{"form", http.MethodPost, "/api/compare", "application/x-www-form-urlencoded", `ids=tokyo`, http.StatusUnsupportedMediaType},
+		{"malformed", http.MethodPost, "/api/destinations/batch", "application/", `{}`, http.StatusUnsupportedMediaType},
+		{"GET skips the check", http.MethodGet, "/api/destinations", "application/xml", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if tt.wantStatus != http.StatusUnsupportedMediaType {
+				if rec.Code != tt.wantStatus {
+					t.Errorf("status = %d, want %d; body: %s", rec.Code, tt.wantStatus, rec.Body)
+				}
+				return
+			}
+			got := decodeError(t, rec, http.StatusUnsupportedMediaType)
+			if got.Code != codeUnsupportedMedia || got.Message != "request body must be application/json" {
+				t.Errorf("error = %s %q, want %s", got.Code, got.Message, codeUnsupportedMedia)
+			}
+		})
+	}
+}
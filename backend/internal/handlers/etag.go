@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// ETag tags successful GET responses with a hash of their body and answers
+// 304 Not Modified when the client's If-None-Match already has that version.
+// Hashing the body means the tag changes whenever the underlying data or the
+// requested representation does. The request ID echoed in the envelope is
+// left out of the hash, since it differs on every request. Handlers that
+// flush are streaming, so their responses pass through untagged.
+func ETag(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		buf := &bufferedWriter{w: w, status: http.StatusOK}
+		next.ServeHTTP(buf, r)
+		if buf.streaming {
+			return
+		}
+
+		if buf.status != http.StatusOK {
+			w.WriteHeader(buf.status)
+			w.Write(buf.body.Bytes())
+			return
+		}
+
+		sum := sha256.Sum256(withoutRequestID(buf.body.Bytes(), r))
+		tag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		w.Header().Set("ETag", tag)
+
+		if etagMatches(r.Header.Get("If-None-Match"), tag) {
+			w.Header().Del("Content-Type")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(buf.body.Bytes())
+	})
+}
+
+// withoutRequestID blanks the request's ID wherever it appears as a JSON
+// string in body
+func withoutRequestID(body []byte, r *http.Request) []byte {
+	id := middleware.GetReqID(r.Context())
+	if id == "" {
+		return body
+	}
+	quoted, err := json.Marshal(id)
+	if err != nil {
+		return body
+	}
+	return bytes.ReplaceAll(body, quoted, []byte(`""`))
+}
+
+// etagMatches reports whether an If-None-Match header lists tag, using the
+// weak comparison RFC 9110 prescribes for conditional GETs
+func etagMatches(header, tag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedWriter captures a response so it can be inspected before sending.
+// Flushing switches it to writing straight through to w.
+type bufferedWriter struct {
+	w      http.ResponseWriter
+	status int
+	body   bytes.Buffer
+	// streaming is set once the handler has flushed
+	streaming bool
+}
+
+func (b *bufferedWriter) Header() http.Header { return b.w.Header() }
+
+func (b *bufferedWriter) Write(p []byte) (int, error) {
+	if b.streaming {
+		return b.w.Write(p)
+	}
+	return b.body.Write(p)
+}
+
+func (b *bufferedWriter) WriteHeader(status int) {
+	if !b.streaming {
+		b.status = status
+	}
+}
+
+// FlushError sends everything buffered so far and stops buffering
+func (b *bufferedWriter) FlushError() error {
+	if !b.streaming {
+		b.streaming = true
+		b.w.WriteHeader(b.status)
+		if _, err := b.w.Write(b.body.Bytes()); err != nil {
+			return err
+		}
+		b.body.Reset()
+	}
+	return http.NewResponseController(b.w).Flush()
+}
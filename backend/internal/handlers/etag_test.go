@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// conditionalGet sends a GET with the given If-None-Match header
+func conditionalGet(h http.Handler, target, ifNoneMatch string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestETagConditionalGet(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	for _, target := range []string{"/api/destinations", "/api/destinations/tokyo"} {
+		t.Run(target, func(t *testing.T) {
+			first := conditionalGet(router, target, "")
+			tag := first.Header().Get("ETag")
+			if first.Code != http.StatusOK || tag == "" {
+				t.Fatalf("first GET = %d with ETag %q, want 200 with a tag", first.Code, tag)
+			}
+
+			tests := []struct {
+				name        string
+				ifNoneMatch string
+				want        int
+			}{
+				{"matching tag", tag, http.StatusNotModified},
+				{"weak tag", "W/" + tag, http.StatusNotModified},
+				{"one of several", `"stale", ` + tag, http.StatusNotModified},
+				{"wildcard", "*", http.StatusNotModified},
+				{"stale tag", `"stale"`, http.StatusOK},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					rec := conditionalGet(router, target, tt.ifNoneMatch)
+					if rec.Code != tt.want {
+						t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+					}
+					if got := rec.Header().Get("ETag"); got != tag {
+						t.Errorf("ETag = %q, want the stable %q", got, tag)
+					}
+					if tt.want == http.StatusNotModified && rec.Body.Len() != 0 {
+						t.Errorf("304 has a body: %s", rec.Body)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestETagChangesWithData(t *testing.T) {
+	cfg := testConfig(t)
+	tag := func(h http.Handler, target string) string {
+		return conditionalGet(h, target, "").Header().Get("ETag")
+	}
+
+	changed := slices.Clone(testDestinations)
+	changed[2].Name = "Tōkyō"
+	before, after := newTestRouter(cfg, testDestinations), newTestRouter(cfg, changed)
+
+	tests := []struct {
+		name   string
+		a, b   http.Handler
+		ta, tb string
+	}{
+		{"edited destination", before, after, "/api/destinations/tokyo", "/api/destinations/tokyo"},
+		{"edited list", before, after, "/api/destinations", "/api/destinations"},
+		{"different representation", before, before, "/api/destinations/tokyo", "/api/destinations/tokyo?units=f"},
+	}
+	for _, tt := range tests {
+		if a, b := tag(tt.a, tt.ta), tag(tt.b, tt.tb); a == b {
+			t.Errorf("%s: ETag unchanged (%s)", tt.name, a)
+		}
+	}
+}
+
+func TestETagSkipsErrors(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	rec := conditionalGet(router, "/api/destinations/atlantis", "*")
+	if rec.Code != http.StatusNotFound || rec.Header().Get("ETag") != "" {
+		t.Errorf("missing destination = %d with ETag %q, want an untagged 404", rec.Code, rec.Header().Get("ETag"))
+	}
+}
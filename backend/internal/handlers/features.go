@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/simonryrie/otherwhere/internal/ranking"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// GetFeatures describes every searchable feature, in vector order, with the
+// aliases searches may use for it
+func (h *Handler) GetFeatures(w http.ResponseWriter, r *http.Request) {
+	features := make([]types.FeatureMetadata, len(ranking.Features))
+	for i, feat := range ranking.Features {
+		features[i] = types.FeatureMetadata{
+			Key:           feat.Key,
+			Label:         feat.Label,
+			Unit:          feat.Unit,
+			HigherIsMore:  feat.HigherIsMore,
+			SourceMin:     feat.Range.Min,
+			SourceMax:     feat.Range.Max,
+			DefaultWeight: feat.DefaultWeight,
+			Aliases:       h.aliases.For(feat.Key),
+		}
+	}
+	writeData(w, r, http.StatusOK, features)
+}
+
+// GetPresets lists the vibe presets searches can start from
+func (h *Handler) GetPresets(w http.ResponseWriter, r *http.Request) {
+	writeData(w, r, http.StatusOK, ranking.Presets)
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/ranking"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// TestGetFeaturesListsEveryField checks that /api/features describes every
+// scalar DestinationFeatures field exactly once, in vector order, with the
+// registry's metadata
+func TestGetFeaturesListsEveryField(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	var got []types.FeatureMetadata
+	decodeData(t, serve(router, http.MethodGet, "/api/features", ""), http.StatusOK, &got)
+
+	count := map[string]int{}
+	for _, f := range got {
+		count[f.Key]++
+	}
+	typ := reflect.TypeFor[types.DestinationFeatures]()
+	var fields int
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if field.Type.Kind() != reflect.Float64 {
+			continue
+		}
+		fields++
+		key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if count[key] != 1 {
+			t.Errorf("DestinationFeatures.%s (%q) listed %d times, want once", field.Name, key, count[key])
+		}
+	}
+	if len(got) != fields {
+		t.Errorf("listed %d features, want %d", len(got), fields)
+	}
+
+	for i, feat := range ranking.Features {
+		if i >= len(got) {
+			break
+		}
+		f := got[i]
+		if f.Key != feat.Key || f.Label != feat.Label || f.Unit != feat.Unit || f.HigherIsMore != feat.HigherIsMore ||
+			f.SourceMin != feat.Range.Min || f.SourceMax != feat.Range.Max || f.DefaultWeight != feat.DefaultWeight {
+			t.Errorf("feature %d = %+v, want the registry's %s", i, f, feat.Key)
+		}
+	}
+}
+
+func TestGetFeaturesListsAliases(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.FeatureAliases = map[string]string{"heat": "avg_temp_c"}
+	router := newTestRouter(cfg, testDestinations)
+	var got []types.FeatureMetadata
+	decodeData(t, serve(router, http.MethodGet, "/api/features", ""), http.StatusOK, &got)
+	want := map[string][]string{
+		"avg_temp_c":        {"heat", "temperature", "warmth"},
+		"nightlife_density": {"nightlife"},
+	}
+	for _, f := range got {
+		if w, ok := want[f.Key]; ok && !slices.Equal(f.Aliases, w) {
+			t.Errorf("%s aliases = %q, want %q", f.Key, f.Aliases, w)
+		}
+	}
+}
+
+func TestGetPresets(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	var got []types.Preset
+	decodeData(t, serve(router, http.MethodGet, "/api/presets", ""), http.StatusOK, &got)
+	if !reflect.DeepEqual(got, ranking.Presets) {
+		t.Errorf("presets = %+v, want %+v", got, ranking.Presets)
+	}
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// fieldSet is the set of top-level destination keys a client asked for
+type fieldSet map[string]bool
+
+// destinationFields lists every top-level JSON key of a destination view
+var destinationFields = sync.OnceValue(func() map[string]bool {
+	keys := map[string]bool{}
+	collectJSONKeys(reflect.TypeFor[types.DestinationView](), keys)
+	return keys
+})
+
+// collectJSONKeys adds the JSON keys of a struct's fields, flattening
+// embedded structs the way encoding/json does
+func collectJSONKeys(t reflect.Type, keys map[string]bool) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch {
+		case name == "-" || !field.IsExported():
+		case field.Anonymous && name == "":
+			collectJSONKeys(field.Type, keys)
+		case name == "":
+			keys[field.Name] = true
+		default:
+			keys[name] = true
+		}
+	}
+}
+
+// parseFields reads the comma-separated fields query parameter. It returns
+// nil when absent, meaning every field is included. The id is always kept.
+func parseFields(r *http.Request) (fieldSet, error) {
+	raw := r.URL.Query().Get("fields")
+	if raw == "" {
+		return nil, nil
+	}
+
+	fs := fieldSet{"id": true}
+	var unknown []string
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !destinationFields()[name] {
+			unknown = append(unknown, name)
+			continue
+		}
+		fs[name] = true
+	}
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		return nil, fmt.Errorf("unknown fields: %s", strings.Join(unknown, ", "))
+	}
+	return fs, nil
+}
+
+// writeFields writes v like writeData, projecting destinations down to the
+// requested fields. v is either a single destination view or a response
+// with a "destinations" list.
+func writeFields(w http.ResponseWriter, r *http.Request, status int, v any, fs fieldSet) {
+	if fs == nil {
+		writeData(w, r, status, v)
+		return
+	}
+
+	projected, err := project(v, fs)
+	if err != nil {
+		slog.Error("failed to project response fields", "error", err)
+		writeError(w, r, http.StatusInternalServerError, codeInternal, "failed to encode response")
+		return
+	}
+	writeData(w, r, status, projected)
+}
+
+// project re-encodes v with destination objects reduced to the keys in fs
+func project(v any, fs fieldSet) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+
+	list, ok := obj["destinations"]
+	if !ok {
+		return fs.keep(obj), nil
+	}
+
+	var items []map[string]json.RawMessage
+	if err := json.Unmarshal(list, &items); err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i] = fs.keep(items[i])
+	}
+	if obj["destinations"], err = json.Marshal(items); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// keep drops the keys of obj that aren't in the field set
+func (fs fieldSet) keep(obj map[string]json.RawMessage) map[string]json.RawMessage {
+	for key := range obj {
+		if !fs[key] {
+			delete(obj, key)
+		}
+	}
+	return obj
+}
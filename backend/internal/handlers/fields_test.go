@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFieldsProjection(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	tests := []struct {
+		name, method, target, body string
+		// list is set when the response holds a destinations list
+		list bool
+		want []string
+	}{
+		{"single destination", http.MethodGet, "/api/destinations/tokyo?fields=name,country", "", false, []string{"country", "id", "name"}},
+		{"id always kept", http.MethodGet, "/api/destinations/tokyo?fields=tags", "", false, []string{"id", "tags"}},
+		{"destinations list", http.MethodGet, "/api/destinations?fields=name", "", true, []string{"id", "name"}},
+		{"search results", http.MethodPost, "/api/search?fields=score,+name", `{"query":"ski"}`, true, []string{"id", "name", "score"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var items []map[string]json.RawMessage
+			if tt.list {
+				var got struct {
+					Destinations []map[string]json.RawMessage `json:"destinations"`
+					Total        int                          `json:"total"`
+				}
+				decodeData(t, serve(router, tt.method, tt.target, tt.body), http.StatusOK, &got)
+				if got.Total == 0 {
+					t.Error("list metadata dropped by projection")
+				}
+				items = got.Destinations
+			} else {
+				var got map[string]json.RawMessage
+				decodeData(t, serve(router, tt.method, tt.target, tt.body), http.StatusOK, &got)
+				items = append(items, got)
+			}
+			if len(items) == 0 {
+				t.Fatal("no destinations")
+			}
+			for _, item := range items {
+				if keys := slices.Sorted(maps.Keys(item)); !slices.Equal(keys, tt.want) {
+					t.Errorf("keys = %v, want %v", keys, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFieldsRejectsUnknown(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	got := decodeError(t, serve(router, http.MethodGet, "/api/destinations?fields=name,vibes,altitude", ""), http.StatusBadRequest)
+	if !strings.Contains(got.Message, "unknown fields: altitude, vibes") {
+		t.Errorf("message = %q, want the unknown fields listed", got.Message)
+	}
+}
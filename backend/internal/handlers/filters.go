@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/simonryrie/otherwhere/internal/ranking"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// GetFilterOptions lists the continents, countries, and regions present in
+// the dataset for populating filter dropdowns
+func (h *Handler) GetFilterOptions(w http.ResponseWriter, r *http.Request) error {
+	destinations, err := storeFromContext(r.Context()).List(r.Context())
+	if err != nil {
+		return fmt.Errorf("list destinations: %w", err)
+	}
+
+	writeData(w, r, http.StatusOK, ranking.BuildFilterOptions(destinations))
+	return nil
+}
+
+// parseGeoFilters reads geographic filters from query parameters: continent,
+// country, region, near=lat,lon with radius_km, and
+// bbox=min_lat,min_lon,max_lat,max_lon. It returns nil when none are set.
+func parseGeoFilters(r *http.Request) (*types.GeographicFilters, error) {
+	q := r.URL.Query()
+	var f types.GeographicFilters
+	set := false
+
+	if v := q.Get("continent"); v != "" {
+		c := types.Continent(v)
+		f.Continent, set = &c, true
+	}
+	if v := q.Get("country"); v != "" {
+		f.Country, set = &v, true
+	}
+	if v := q.Get("region"); v != "" {
+		f.Region, set = &v, true
+	}
+	if v := q.Get("near"); v != "" {
+		coords, err := parseFloats("near", v, 2)
+		if err != nil {
+			return nil, err
+		}
+		f.Near, set = &types.Location{Lat: coords[0], Lon: coords[1]}, true
+	}
+	if v := q.Get("radius_km"); v != "" {
+		radius, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("radius_km must be a number")
+		}
+		f.RadiusKm = radius
+	}
+	if v := q.Get("bbox"); v != "" {
+		coords, err := parseFloats("bbox", v, 4)
+		if err != nil {
+			return nil, err
+		}
+		f.BoundingBox, set = &types.BoundingBox{MinLat: coords[0], MinLon: coords[1], MaxLat: coords[2], MaxLon: coords[3]}, true
+	}
+
+	if !set {
+		return nil, nil
+	}
+	return &f, ranking.ValidateFilters(&f)
+}
+
+// parseFloats parses a comma-separated list of exactly n numbers
+func parseFloats(name, raw string, n int) ([]float64, error) {
+	parts := strings.Split(raw, ",")
+	if len(parts) != n {
+		return nil, fmt.Errorf("%s must be %d comma-separated numbers", name, n)
+	}
+	out := make([]float64, n)
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be %d comma-separated numbers", name, n)
+		}
+		out[i] = v
+	}
+	return out, nil
+}
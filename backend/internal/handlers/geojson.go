@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/simonryrie/otherwhere/internal/ranking"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+const geoJSONContentType = "application/geo+json"
+
+// wantsGeoJSON reports whether the client asked for GeoJSON, either with
+// format=geojson or an Accept header naming application/geo+json
+func wantsGeoJSON(r *http.Request) bool {
+	if strings.EqualFold(r.URL.Query().Get("format"), "geojson") {
+		return true
+	}
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		if mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted)); err == nil && mediaType == geoJSONContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// newFeatureCollection converts ranked results into GeoJSON point features
+func newFeatureCollection(results []ranking.Result, total int) types.FeatureCollection {
+	features := make([]types.GeoFeature, len(results))
+	for i, res := range results {
+		d := res.Destination
+		features[i] = types.GeoFeature{
+			Type: "Feature",
+			Geometry: types.Point{
+				Type:        "Point",
+				Coordinates: [2]float64{d.Location.Lon, d.Location.Lat},
+			},
+			Properties: types.GeoProperties{
+				ID:    d.ID,
+				Name:  d.Name,
+				Score: res.Score,
+			},
+		}
+	}
+	return types.FeatureCollection{Type: "FeatureCollection", Features: features, Total: total}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSearchGeoJSON(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	tests := []struct {
+		name   string
+		target string
+		accept string
+	}{
+		{"format parameter", "/api/search?format=geojson", ""},
+		{"Accept header", "/api/search", "application/json;q=0.5, application/geo+json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(`{"query":"ski"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d; body: %s", rec.Code, rec.Body)
+			}
+			if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, geoJSONContentType) {
+				t.Errorf("Content-Type = %q, want %s", ct, geoJSONContentType)
+			}
+
+			var got struct {
+				Type     string `json:"type"`
+				Features []struct {
+					Type     string `json:"type"`
+					Geometry struct {
+						Type        string    `json:"type"`
+						Coordinates []float64 `json:"coordinates"`
+					} `json:"geometry"`
+					Properties struct {
+						ID    string   `json:"id"`
+						Name  string   `json:"name"`
+						Score *float64 `json:"score"`
+					} `json:"properties"`
+				} `json:"features"`
+				Total int `json:"total"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("decode: %v; body: %s", err, rec.Body)
+			}
+			if got.Type != "FeatureCollection" || len(got.Features) == 0 || got.Total != len(got.Features) {
+				t.Fatalf("collection = %s with %d features, total %d", got.Type, len(got.Features), got.Total)
+			}
+			f := got.Features[0]
+			if f.Type != "Feature" || f.Geometry.Type != "Point" || f.Properties.Score == nil {
+				t.Errorf("feature = %+v, want a scored Point feature", f)
+			}
+			// Zermatt is at 46.02°N 7.75°E, so longitude must come first
+			if f.Properties.ID != "zermatt" || f.Properties.Name != "Zermatt" {
+				t.Fatalf("first feature = %s, want zermatt", f.Properties.ID)
+			}
+			if c := f.Geometry.Coordinates; len(c) != 2 || c[0] != 7.75 || c[1] != 46.02 {
+				t.Errorf("coordinates = %v, want [lon, lat] [7.75 46.02]", c)
+			}
+		})
+	}
+}
+
+func TestSearchDefaultsToJSON(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	rec := serve(router, http.MethodPost, "/api/search", `{"query":"ski"}`)
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var got resultList
+	decodeData(t, rec, http.StatusOK, &got)
+	if len(got.Destinations) == 0 {
+		t.Error("default response has no destinations")
+	}
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/simonryrie/otherwhere/internal/config"
+	"github.com/simonryrie/otherwhere/internal/ranking"
+	"github.com/simonryrie/otherwhere/internal/tracing"
+)
+
+// tracer records manual spans around handler work such as scoring
+var tracer = tracing.Tracer("handlers")
+
+// Handler holds the settings shared by the API handlers. The destination
+// store comes from each request's context; see WithStore.
+type Handler struct {
+	// textBlend weighs name matching against feature similarity for
+	// queries without recognized keywords
+	textBlend float64
+	// avoidPenalty scales the score penalty for avoided features
+	avoidPenalty float64
+	// comfort is the temperature bias applied to searches with comfort=true
+	comfort ranking.ComfortBias
+
+	// aliases map alternative feature names onto feature keys
+	aliases ranking.FeatureAliases
+
+	// redactedFeatures are hidden from responses but still used for ranking
+	redactedFeatures []string
+
+	// placeholderImage is shown for destinations without images
+	placeholderImage string
+	// popularity orders searches that have nothing to rank on
+	popularity ranking.Feature
+	// coastalKm is the coast distance below which destinations are coastal
+	coastalKm float64
+	// tiers classify destinations by popularity
+	tiers ranking.TierThresholds
+	// collapseRadiusKm and collapseMinSimilarity pick out near-duplicate
+	// search results
+	collapseRadiusKm      float64
+	collapseMinSimilarity float64
+
+	// clampConstraints clamps out-of-range constraint bounds instead of
+	// rejecting the search
+	clampConstraints bool
+	// maxComplexity caps the ranking.Complexity of a search
+	maxComplexity int
+
+	// scoringWorkers caps the goroutines scoring a search
+	scoringWorkers int
+
+	// Page sizes for listing, searching, and finding similar destinations
+	destinationsPage config.PageLimits
+	searchPage       config.PageLimits
+	similarPage      config.PageLimits
+
+	// randIntN picks a random index in [0, n) for the random endpoint
+	randIntN func(n int) int
+}
+
+// New creates a Handler configured by cfg. A non-zero cfg.RandomSeed makes
+// random picks repeatable.
+func New(cfg config.Config) *Handler {
+	popularity, ok := ranking.FeatureByKey(cfg.PopularityFeature)
+	if !ok {
+		popularity, _ = ranking.FeatureByKey(ranking.DefaultPopularityFeature)
+	}
+	// LoadConfig has already checked the configured aliases
+	aliases, err := ranking.NewFeatureAliases(cfg.FeatureAliases)
+	if err != nil {
+		aliases = ranking.DefaultFeatureAliases
+	}
+	h := &Handler{
+		aliases:          aliases,
+		redactedFeatures: cfg.RedactedFeatures,
+		textBlend:        cfg.TextBlend,
+		avoidPenalty:     cfg.AvoidPenalty,
+		comfort:          ranking.ComfortBias{MinC: cfg.ComfortMinC, MaxC: cfg.ComfortMaxC, Penalty: cfg.ComfortPenalty},
+		placeholderImage: cfg.PlaceholderImageURL,
+		coastalKm:        cfg.CoastalKm,
+		tiers:            ranking.TierThresholds{HiddenGemBelow: cfg.TierHiddenGemBelow, IconicFrom: cfg.TierIconicFrom},
+		popularity:       popularity,
+		randIntN:         rand.IntN,
+
+		collapseRadiusKm:      cfg.CollapseRadiusKm,
+		collapseMinSimilarity: cfg.CollapseMinSimilarity,
+		clampConstraints:      cfg.ClampConstraints,
+		maxComplexity:         cfg.MaxQueryComplexity,
+		scoringWorkers:        cfg.ScoringWorkers,
+
+		destinationsPage: cfg.DestinationsPage,
+		searchPage:       cfg.SearchPage,
+		similarPage:      cfg.SimilarPage,
+	}
+	if cfg.RandomSeed != 0 {
+		h.randIntN = seededIntN(cfg.RandomSeed)
+	}
+	return h
+}
+
+// seededIntN returns a goroutine-safe IntN drawing from a fixed seed
+func seededIntN(seed uint64) func(n int) int {
+	var mu sync.Mutex
+	rng := rand.New(rand.NewPCG(seed, seed))
+	return func(n int) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return rng.IntN(n)
+	}
+}
+
+// requestLogger returns the default logger tagged with the request's ID so
+// a handler's log lines can be correlated with the access log
+func requestLogger(r *http.Request) *slog.Logger {
+	return slog.With("request_id", middleware.GetReqID(r.Context()))
+}
+
+// writeJSON encodes v as the JSON response body with the given status
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	writeJSONAs(w, status, "application/json", v)
+}
+
+// writeJSONAs is writeJSON for JSON-based media types such as GeoJSON. The
+// body is encoded before anything is sent, so a value JSON can't represent,
+// such as a NaN score, becomes a 500 instead of a truncated 200.
+func writeJSONAs(w http.ResponseWriter, status int, contentType string, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		status, contentType = http.StatusInternalServerError, "application/json"
+		body, _ = json.Marshal(errorResponse{
+			Error: apiError{Status: status, Code: codeInternal, Message: "internal server error"},
+		})
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	w.Write(append(body, '\n'))
+}
+
+// decodeJSON decodes the request body into v. Errors are client errors
+// describing what was wrong with the body: a 413 for bodies over the
+// LimitBody cap, otherwise a 400.
+func decodeJSON(r *http.Request, v any) error {
+	return decodeBody(json.NewDecoder(r.Body), v)
+}
+
+// decodeStrictJSON is decodeJSON for request types whose fields are easy to
+// misspell: a field v doesn't have is a 400 naming it, rather than being
+// silently ignored
+func decodeStrictJSON(r *http.Request, v any) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	return decodeBody(dec, v)
+}
+
+// decodeBody decodes one value from dec into v, translating failures into
+// client errors
+func decodeBody(dec *json.Decoder, v any) error {
+	err := dec.Decode(v)
+	if err == nil {
+		return nil
+	}
+
+	var maxErr *http.MaxBytesError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	// encoding/json reports unknown fields only through the message
+	field, unknown := strings.CutPrefix(err.Error(), "json: unknown field ")
+	switch {
+	case errors.As(err, &maxErr):
+		return tooLarge(maxErr.Limit)
+	case errors.Is(err, io.EOF):
+		return badRequestf("request body is required")
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return badRequestf("request body is not valid JSON")
+	case errors.As(err, &typeErr):
+		return badRequestf("field %s must be of type %s", typeErr.Field, typeErr.Type)
+	case unknown:
+		return badRequestf("unknown field %s", field)
+	default:
+		return badRequestf("invalid request body")
+	}
+}
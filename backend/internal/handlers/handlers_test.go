@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/simonryrie/otherwhere/internal/config"
+	"github.com/simonryrie/otherwhere/internal/ranking"
+	"github.com/simonryrie/otherwhere/internal/store"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// testDestinations is a small dataset with one clear match for each of the
+// common vibes: a beach town, a ski resort, a big city, and somewhere remote
+var testDestinations = []types.Destination{
+	{
+		ID: "tamarindo", Name: "Tamarindo", Country: "Costa Rica", Continent: types.NorthAmerica,
+		Type: types.City, Location: types.Location{Lat: 10.30, Lon: -85.84}, Active: true,
+		Tags: []string{"surf"},
+		Features: types.DestinationFeatures{
+			AvgTempC: 0.72, TourismDensity: 0.6, WikipediaPageviews: 0.3, AccommodationDensity: 0.7,
+			Population: 0.1, CoastDistanceKm: 0, NatureRatio: 0.6, Elevation: 0.01,
+			WaterSportsScore: 0.9, HikingScore: 0.3, WildlifeScore: 0.5, NightlifeDensity: 0.7,
+			DevelopmentLevel: 0.5, GDPPerCapita: 0.4,
+		},
+	},
+	{
+		ID: "zermatt", Name: "Zermatt", Country: "Switzerland", Continent: types.Europe, Region: new("Valais"),
+		Type: types.City, Location: types.Location{Lat: 46.02, Lon: 7.75}, Active: true,
+		Tags: []string{"ski", "alpine"},
+		Features: types.DestinationFeatures{
+			AvgTempC: 0.25, TourismDensity: 0.8, WikipediaPageviews: 0.6, AccommodationDensity: 0.8,
+			Population: 0.05, CoastDistanceKm: 0.6, NatureRatio: 0.8, Elevation: 0.4,
+			SkiingScore: 0.95, HikingScore: 0.9, WildlifeScore: 0.3, NightlifeDensity: 0.3,
+			DevelopmentLevel: 0.95, GDPPerCapita: 0.95,
+		},
+	},
+	{
+		ID: "tokyo", Name: "Tokyo", Country: "Japan", Continent: types.Asia, Region: new("Kanto"),
+		Type: types.City, Location: types.Location{Lat: 35.68, Lon: 139.69}, Active: true,
+		Tags: []string{"food"},
+		Features: types.DestinationFeatures{
+			AvgTempC: 0.52, TourismDensity: 0.9, WikipediaPageviews: 1, AccommodationDensity: 0.9,
+			Population: 1, CoastDistanceKm: 0.01, NatureRatio: 0.1, Elevation: 0.01,
+			WaterSportsScore: 0.2, HikingScore: 0.2, WildlifeScore: 0.05, NightlifeDensity: 1,
+			DevelopmentLevel: 0.95, GDPPerCapita: 0.8,
+		},
+	},
+	{
+		ID: "lofoten", Name: "Lofoten", Country: "Norway", Continent: types.Europe,
+		Type: types.Region, Location: types.Location{Lat: 68.2, Lon: 13.6}, Active: true,
+		Tags: []string{"fjords"},
+		Features: types.DestinationFeatures{
+			AvgTempC: 0.3, TourismDensity: 0.2, WikipediaPageviews: 0.2, AccommodationDensity: 0.2,
+			Population: 0.02, CoastDistanceKm: 0.002, NatureRatio: 0.95, Elevation: 0.1,
+			SkiingScore: 0.3, WaterSportsScore: 0.4, HikingScore: 0.85, WildlifeScore: 0.7, NightlifeDensity: 0.05,
+			DevelopmentLevel: 0.9, GDPPerCapita: 0.9,
+		},
+	},
+}
+
+// testConfig returns the default configuration
+func testConfig(t *testing.T) config.Config {
+	t.Helper()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	return cfg
+}
+
+// newTestRouter serves the API routes cmd/server registers, with the
+// middleware handlers rely on, over an in-memory store holding dests
+func newTestRouter(cfg config.Config, dests []types.Destination) http.Handler {
+	return newTestRouterWithStore(cfg, store.NewMemoryStore(dests))
+}
+
+// newTestRouterWithStore is newTestRouter over any store
+func newTestRouterWithStore(cfg config.Config, s store.DestinationStore) http.Handler {
+	h := New(cfg)
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID)
+	r.Use(EchoRequestID)
+	r.Use(WithStore(s))
+	r.NotFound(NotFound)
+	r.MethodNotAllowed(MethodNotAllowed)
+	r.Get("/health", h.Health)
+	r.Route("/api", func(r chi.Router) {
+		r.Use(Timeout(cfg.RequestTimeout))
+		r.Use(LimitBody(cfg.MaxBodyBytes))
+		r.Use(RequireJSON)
+		r.Use(DatasetVersion)
+		r.Use(ActiveOnly(cfg.AdminToken))
+		r.With(ETag).Get("/destinations", Handle(h.GetDestinations))
+		r.Get("/destinations.csv", Handle(h.GetDestinationsCSV))
+		r.With(ETag).Get("/destinations/{id}", Handle(h.GetDestination))
+		r.Post("/destinations/batch", Handle(h.GetDestinationsBatch))
+		r.Get("/destinations/random", Handle(h.GetRandomDestination))
+		r.Get("/destinations/nearest", Handle(h.GetNearestDestinations))
+		r.Get("/destinations/{id}/similar", Handle(h.GetSimilarDestinations))
+		r.Get("/destinations/{id}/best-month", Handle(h.GetBestMonth))
+		r.Get("/features", h.GetFeatures)
+		r.Get("/presets", h.GetPresets)
+		r.Get("/filters", Handle(h.GetFilterOptions))
+		r.With(ETag).Get("/stats/continents", Handle(h.GetContinentStats))
+		r.Post("/search", Handle(h.Search))
+		r.Get("/search", Handle(h.SearchQuery))
+		r.Post("/search/diagnose", Handle(h.DiagnoseSearch))
+		r.Post("/search/validate", Handle(h.ValidateSearch))
+		r.Post("/search/more-like", Handle(h.MoreLike))
+		r.Get("/autocomplete", Handle(h.Autocomplete))
+		r.Post("/compare", Handle(h.Compare))
+		if cfg.AdminToken != "" {
+			r.With(RequireToken(cfg.AdminToken)).Post("/admin/reload", Handle(h.Reload))
+			r.With(RequireToken(cfg.AdminToken)).Get("/admin/coverage", Handle(h.Coverage))
+		}
+	})
+	return r
+}
+
+// serve sends a request to h, as JSON when body is set, and records the response
+func serve(h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, target, r)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// decodeData decodes the data of an API response envelope into v, failing
+// the test unless the response has the wanted status
+func decodeData(t *testing.T, rec *httptest.ResponseRecorder, status int, v any) {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d; body: %s", rec.Code, status, rec.Body)
+	}
+	var env struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &env); err != nil {
+		t.Fatalf("decode envelope: %v; body: %s", err, rec.Body)
+	}
+	if err := json.Unmarshal(env.Data, v); err != nil {
+		t.Fatalf("decode data: %v; body: %s", err, rec.Body)
+	}
+}
+
+// decodeError decodes a structured error body, failing the test unless the
+// response has the wanted status
+func decodeError(t *testing.T, rec *httptest.ResponseRecorder, status int) apiError {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d; body: %s", rec.Code, status, rec.Body)
+	}
+	var body errorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode error body: %v; body: %s", err, rec.Body)
+	}
+	return body.Error
+}
+
+// resultList is the part of a destinations or search response the tests read
+type resultList struct {
+	Destinations []struct {
+		ID             string             `json:"id"`
+		Score          *float64           `json:"score"`
+		ScoreBreakdown map[string]float64 `json:"score_breakdown"`
+		Summary        string             `json:"summary"`
+	} `json:"destinations"`
+	Total int             `json:"total"`
+	Meta  *types.PageMeta `json:"meta"`
+}
+
+// ids lists the result IDs in order
+func (l resultList) ids() []string {
+	out := make([]string, len(l.Destinations))
+	for i, d := range l.Destinations {
+		out[i] = d.ID
+	}
+	return out
+}
+
+// withNaN returns a copy of testDestinations with zermatt's skiing score
+// set to NaN, optionally zeroed the way loading does
+func withNaN(zeroed bool) []types.Destination {
+	dests := make([]types.Destination, len(testDestinations))
+	copy(dests, testDestinations)
+	dests[1].Features.SkiingScore = math.NaN()
+	if zeroed {
+		ranking.ZeroNonFinite(&dests[1].Features)
+		dests[1].MissingFeatures = []string{"skiing_score"}
+	}
+	return dests
+}
+
+func TestNonFiniteFeatures(t *testing.T) {
+	// A NaN that reaches the response can't be encoded, so it fails whole
+	// instead of sending a 200 with a truncated or invalid body
+	raw := newTestRouter(testConfig(t), withNaN(false))
+	for _, target := range []string{"/api/destinations/zermatt", "/api/destinations"} {
+		rec := serve(raw, http.MethodGet, target, "")
+		if got := decodeError(t, rec, http.StatusInternalServerError); got.Code != codeInternal {
+			t.Errorf("%s: code = %s, want %s", target, got.Code, codeInternal)
+		}
+		if strings.Contains(rec.Body.String(), "NaN") {
+			t.Errorf("%s: body contains NaN: %s", target, rec.Body)
+		}
+	}
+
+	// Once loading zeroes it, scoring and encoding work as usual
+	zeroed := newTestRouter(testConfig(t), withNaN(true))
+	for _, body := range []string{`{"query":"tokyo"}`, `{"weights":{"skiing_score":3}}`} {
+		var got resultList
+		decodeData(t, serve(zeroed, http.MethodPost, "/api/search", body), http.StatusOK, &got)
+		if got.Total != len(testDestinations) {
+			t.Errorf("%s: total = %d, want %d", body, got.Total, len(testDestinations))
+		}
+		for _, d := range got.Destinations {
+			if d.Score == nil || math.IsNaN(*d.Score) {
+				t.Errorf("%s: %s score = %v, want a finite score", body, d.ID, d.Score)
+			}
+		}
+	}
+}
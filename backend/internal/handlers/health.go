@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/simonryrie/otherwhere/internal/store"
+)
+
+// healthCheckTimeout bounds how long the readiness check waits on the store
+const healthCheckTimeout = 2 * time.Second
+
+// healthProbeID is looked up to exercise the store; it's not expected to exist
+const healthProbeID = "__health__"
+
+// healthResponse is the body of the health endpoints
+type healthResponse struct {
+	Status string `json:"status"`
+	// DatasetVersion is the X-Dataset-Version data responses carry
+	DatasetVersion string `json:"dataset_version,omitempty"`
+}
+
+// Health reports whether the store is reachable, answering 503 when it isn't
+func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+	defer cancel()
+
+	// A missing-document lookup is a cheap round trip that bypasses the cache
+	if _, err := storeFromContext(ctx).Get(ctx, healthProbeID); err != nil && !errors.Is(err, store.ErrNotFound) {
+		requestLogger(r).Warn("store health check failed", "error", err)
+		writeJSON(w, http.StatusServiceUnavailable, healthResponse{Status: "degraded"})
+		return
+	}
+	writeJSON(w, http.StatusOK, healthResponse{Status: "ok", DatasetVersion: datasetVersion(ctx)})
+}
+
+// Livez reports that the process is serving requests without touching the
+// store, for container liveness probes
+func Livez(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, healthResponse{Status: "ok"})
+}
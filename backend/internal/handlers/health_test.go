@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/store"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// failingStore is a store whose every read fails with err
+type failingStore struct {
+	err error
+}
+
+func (s failingStore) List(context.Context) ([]types.Destination, error) {
+	return nil, s.err
+}
+
+func (s failingStore) Get(context.Context, string) (types.Destination, error) {
+	return types.Destination{}, s.err
+}
+
+func (s failingStore) GetMany(context.Context, []string) ([]types.Destination, error) {
+	return nil, s.err
+}
+
+func TestHealth(t *testing.T) {
+	cfg := testConfig(t)
+	tests := []struct {
+		name       string
+		store      store.DestinationStore
+		wantStatus int
+		wantBody   string
+	}{
+		{"store reachable", store.NewMemoryStore(testDestinations), http.StatusOK, "ok"},
+		{"store unreachable", failingStore{errors.New("connection refused")}, http.StatusServiceUnavailable, "degraded"},
+		{"store timing out", failingStore{context.DeadlineExceeded}, http.StatusServiceUnavailable, "degraded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(newTestRouterWithStore(cfg, tt.store), http.MethodGet, "/health", "")
+			var got healthResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("decode: %v; body: %s", err, rec.Body)
+			}
+			if rec.Code != tt.wantStatus || got.Status != tt.wantBody {
+				t.Errorf("health = %d %q, want %d %q", rec.Code, got.Status, tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestLivezSkipsStore(t *testing.T) {
+	// No store in the context, so touching it would panic
+	rec := httptest.NewRecorder()
+	Livez(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("livez = %d, want 200", rec.Code)
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+
+	"golang.org/x/text/language"
+)
+
+// parseLanguages reads the client's preferred languages from Accept-Language,
+// most preferred first. A malformed header counts as no preference.
+func parseLanguages(r *http.Request) []language.Tag {
+	header := r.Header.Get("Accept-Language")
+	if header == "" {
+		return nil
+	}
+	prefs, _, err := language.ParseAcceptLanguage(header)
+	if err != nil {
+		return nil
+	}
+	return prefs
+}
+
+// localize returns the translation that best matches prefs, or def when
+// there are no preferences or no translation is a close enough match
+func localize(def string, translations map[string]string, prefs []language.Tag) string {
+	if len(prefs) == 0 || len(translations) == 0 {
+		return def
+	}
+
+	keys := make([]string, 0, len(translations))
+	for key := range translations {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	// The matcher falls back to its first entry, def, which is English
+	supported := []language.Tag{language.English}
+	matched := []string{def}
+	for _, key := range keys {
+		tag, err := language.Parse(key)
+		if err != nil {
+			continue
+		}
+		supported = append(supported, tag)
+		matched = append(matched, translations[key])
+	}
+
+	_, i, conf := language.NewMatcher(supported).Match(prefs...)
+	if conf == language.No {
+		return def
+	}
+	return matched[i]
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestLocalize(t *testing.T) {
+	translations := map[string]string{"fr": "Lisbonne", "pt-BR": "Lisboa", "ja": "リスボン"}
+	tests := []struct {
+		name, acceptLanguage string
+		want                 string
+	}{
+		{"exact match", "fr", "Lisbonne"},
+		{"exact regional match", "pt-BR", "Lisboa"},
+		{"region falls back to the language", "fr-CA", "Lisbonne"},
+		{"preference order", "de, ja;q=0.9, fr;q=0.8", "リスボン"},
+		{"english", "en-GB", "Lisbon"},
+		{"unknown locale", "de", "Lisbon"},
+		{"no header", "", "Lisbon"},
+		{"malformed header", "fr;q=lots", "Lisbon"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.acceptLanguage != "" {
+				r.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			if got := localize("Lisbon", translations, parseLanguages(r)); got != tt.want {
+				t.Errorf("localize for %q = %q, want %q", tt.acceptLanguage, got, tt.want)
+			}
+		})
+	}
+	if got := localize("Lisbon", nil, parseLanguages(httptest.NewRequest(http.MethodGet, "/", nil))); got != "Lisbon" {
+		t.Errorf("localize without translations = %q", got)
+	}
+}
+
+func TestDestinationLocalized(t *testing.T) {
+	d := testDestinations[1]
+	d.Description = new("A car-free alpine village")
+	d.Names = map[string]string{"fr": "Zermatt (VS)"}
+	d.Descriptions = map[string]string{"fr": "Un village alpin sans voitures", "de": "Ein autofreies Alpendorf"}
+	router := newTestRouter(testConfig(t), []types.Destination{d})
+
+	tests := []struct {
+		acceptLanguage, wantName, wantDescription string
+	}{
+		{"fr-CH", "Zermatt (VS)", "Un village alpin sans voitures"},
+		// A language with only a description translation keeps the default name
+		{"de", "Zermatt", "Ein autofreies Alpendorf"},
+		{"it", "Zermatt", "A car-free alpine village"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/destinations/zermatt", nil)
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			var got struct {
+				Name        string `json:"name"`
+				Description string `json:"description"`
+			}
+			decodeData(t, rec, http.StatusOK, &got)
+			if got.Name != tt.wantName || got.Description != tt.wantDescription {
+				t.Errorf("got %q, %q; want %q, %q", got.Name, got.Description, tt.wantName, tt.wantDescription)
+			}
+			if !strings.Contains(strings.Join(rec.Header().Values("Vary"), ","), "Accept-Language") {
+				t.Errorf("Vary = %q, want it to include Accept-Language", rec.Header().Values("Vary"))
+			}
+		})
+	}
+}
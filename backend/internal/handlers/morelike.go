@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/simonryrie/otherwhere/internal/ranking"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// maxMoreLikeIDs caps how many liked destinations one request can average
+const maxMoreLikeIDs = 20
+
+// MoreLike ranks destinations by similarity to the centroid of the liked
+// ones, leaving the liked destinations out. Liked IDs that don't exist are
+// listed rather than failing the request unless none of them do.
+func (h *Handler) MoreLike(w http.ResponseWriter, r *http.Request) error {
+	var req types.MoreLikeRequest
+	if err := decodeStrictJSON(r, &req); err != nil {
+		return err
+	}
+	if len(req.IDs) > maxMoreLikeIDs {
+		return badRequestf("ids must list at most %d destinations, got %d", maxMoreLikeIDs, len(req.IDs))
+	}
+	ids := dedupe(req.IDs)
+	if len(ids) == 0 {
+		return badRequestf("ids must list at least one destination")
+	}
+	p, err := newPage(req.Limit, 0, h.similarPage)
+	if err != nil {
+		return badRequest(err)
+	}
+	view, err := h.viewOptions(r)
+	if err != nil {
+		return badRequest(err)
+	}
+	fields, err := parseFields(r)
+	if err != nil {
+		return badRequest(err)
+	}
+	slog.Info("POST /api/search/more-like", "ids", len(ids), "limit", p.limit)
+
+	seeds, missing, err := getDestinations(r, ids)
+	if err != nil {
+		return err
+	}
+	if len(seeds) == 0 {
+		return notFound("none of the liked destinations were found")
+	}
+
+	destinations, err := storeFromContext(r.Context()).List(r.Context())
+	if err != nil {
+		return fmt.Errorf("list destinations: %w", err)
+	}
+
+	results := ranking.MoreLike(seeds, destinations, p.limit)
+	views := make([]types.DestinationView, len(results))
+	for i, res := range results {
+		views[i] = newDestinationView(res.Destination, view)
+		views[i].Score = &res.Score
+	}
+
+	writeFields(w, r, http.StatusOK, types.MoreLikeResponse{
+		Destinations: views,
+		Total:        len(views),
+		Meta:         p.meta(len(views)),
+		NotFound:     missing,
+	}, fields)
+	return nil
+}
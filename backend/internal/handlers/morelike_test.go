@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// withBeachTowns returns testDestinations plus two more beach towns like
+// tamarindo
+func withBeachTowns() []types.Destination {
+	dests := slices.Clone(testDestinations)
+	for _, id := range []string{"tulum", "puerto_escondido"} {
+		d := testDestinations[0]
+		d.ID, d.Name = id, id
+		d.Features.AvgTempC += 0.04
+		d.Features.NightlifeDensity -= 0.05
+		dests = append(dests, d)
+	}
+	dests[len(dests)-1].Features.WaterSportsScore = 0.95
+	return dests
+}
+
+func TestMoreLike(t *testing.T) {
+	router := newTestRouter(testConfig(t), withBeachTowns())
+	tests := []struct {
+		name, body string
+		// wantFirst is the expected closest match
+		wantFirst    string
+		wantNotFound []string
+	}{
+		{"two beach towns", `{"ids":["tamarindo","tulum"]}`, "puerto_escondido", []string{}},
+		{"missing IDs listed", `{"ids":["tamarindo","atlantis","tulum"]}`, "puerto_escondido", []string{"atlantis"}},
+		{"duplicates dropped", `{"ids":["tamarindo","tamarindo","tulum"]}`, "puerto_escondido", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got struct {
+				resultList
+				NotFound []string `json:"not_found"`
+			}
+			decodeData(t, serve(router, http.MethodPost, "/api/search/more-like", tt.body), http.StatusOK, &got)
+			ids := got.ids()
+			if len(ids) == 0 || ids[0] != tt.wantFirst {
+				t.Fatalf("results = %v, want %s first", ids, tt.wantFirst)
+			}
+			if slices.Contains(ids, "tamarindo") || slices.Contains(ids, "tulum") {
+				t.Errorf("results = %v, want the liked destinations left out", ids)
+			}
+			if !slices.Equal(got.NotFound, tt.wantNotFound) {
+				t.Errorf("not_found = %v, want %v", got.NotFound, tt.wantNotFound)
+			}
+		})
+	}
+
+	var limited resultList
+	decodeData(t, serve(router, http.MethodPost, "/api/search/more-like", `{"ids":["tamarindo"],"limit":2}`), http.StatusOK, &limited)
+	if len(limited.Destinations) != 2 {
+		t.Errorf("limit 2 returned %v", limited.ids())
+	}
+}
+
+func TestMoreLikeRejects(t *testing.T) {
+	router := newTestRouter(testConfig(t), withBeachTowns())
+	tests := []struct {
+		name, body string
+		status     int
+		// wantMsg is a substring of the expected error message
+		wantMsg string
+	}{
+		{"no IDs", `{"ids":[]}`, http.StatusBadRequest, "ids must list at least one destination"},
+		{"only empty IDs", `{"ids":["",""]}`, http.StatusBadRequest, "ids must list at least one destination"},
+		{"too many IDs", `{"ids":["` + strings.Repeat(`a","`, maxMoreLikeIDs) + `b"]}`, http.StatusBadRequest, "ids must list at most 20 destinations, got 21"},
+		{"unknown field", `{"ids":["tamarindo"],"seeds":["tulum"]}`, http.StatusBadRequest, `unknown field "seeds"`},
+		{"all missing", `{"ids":["atlantis","eldorado"]}`, http.StatusNotFound, "none of the liked destinations were found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := decodeError(t, serve(router, http.MethodPost, "/api/search/more-like", tt.body), tt.status)
+			if !strings.Contains(got.Message, tt.wantMsg) {
+				t.Errorf("message = %q, want it to contain %q", got.Message, tt.wantMsg)
+			}
+		})
+	}
+}
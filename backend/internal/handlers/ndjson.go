@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// ndjsonContentType is the media type for newline-delimited JSON
+const ndjsonContentType = "application/x-ndjson"
+
+// ndjsonFlushEvery is how many lines are written between flushes
+const ndjsonFlushEvery = 100
+
+// writeNDJSON streams one destination view per line, flushing periodically
+// so the response is never buffered whole. Once the first line is written
+// the status is committed, so failures part way through end the stream
+// early and are only logged.
+func writeNDJSON(w http.ResponseWriter, r *http.Request, dests []types.Destination, opts viewOptions, fs fieldSet) error {
+	w.Header().Set("Content-Type", ndjsonContentType)
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	enc := json.NewEncoder(w)
+	for i, d := range dests {
+		if err := r.Context().Err(); err != nil {
+			requestLogger(r).Warn("ndjson stream aborted", "error", err, "written", i)
+			return nil
+		}
+
+		var line any = newDestinationView(d, opts)
+		if fs != nil {
+			projected, err := project(line, fs)
+			if err != nil {
+				requestLogger(r).Error("failed to project ndjson line", "error", err)
+				return nil
+			}
+			line = projected
+		}
+		if err := enc.Encode(line); err != nil {
+			requestLogger(r).Warn("ndjson stream aborted", "error", err, "written", i)
+			return nil
+		}
+
+		if (i+1)%ndjsonFlushEvery == 0 {
+			if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// readNDJSON unmarshals each line of an NDJSON body into a Destination
+func readNDJSON(t *testing.T, body string) []types.Destination {
+	t.Helper()
+	var out []types.Destination
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		var d types.Destination
+		if err := json.Unmarshal(scanner.Bytes(), &d); err != nil {
+			t.Fatalf("line %d: %v: %s", len(out)+1, err, scanner.Text())
+		}
+		out = append(out, d)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	return out
+}
+
+func TestGetDestinationsNDJSON(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	// The page size doesn't apply, since the stream is the whole export
+	rec := serve(router, http.MethodGet, "/api/destinations?format=ndjson&limit=1&sort=name", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; body: %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != ndjsonContentType {
+		t.Errorf("Content-Type = %q, want %q", ct, ndjsonContentType)
+	}
+	got := readNDJSON(t, rec.Body.String())
+	ids := make([]string, len(got))
+	for i, d := range got {
+		ids[i] = d.ID
+	}
+	if want := []string{"lofoten", "tamarindo", "tokyo", "zermatt"}; !slices.Equal(ids, want) {
+		t.Errorf("streamed %v, want %v", ids, want)
+	}
+	if got[3].Name != "Zermatt" || got[3].Continent != types.Europe {
+		t.Errorf("zermatt line = %+v", got[3])
+	}
+}
+
+func TestGetDestinationsNDJSONFilters(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	rec := serve(router, http.MethodGet, "/api/destinations?format=ndjson&continent=Europe&fields=id", "")
+	got := readNDJSON(t, rec.Body.String())
+	if len(got) != 2 {
+		t.Fatalf("streamed %d lines, want the 2 in Europe", len(got))
+	}
+	for _, d := range got {
+		if d.Name != "" {
+			t.Errorf("fields=id line carries name %q", d.Name)
+		}
+	}
+
+	bad := decodeError(t, serve(router, http.MethodGet, "/api/destinations?format=xml", ""), http.StatusBadRequest)
+	if bad.Message != "format must be ndjson" {
+		t.Errorf("message = %q", bad.Message)
+	}
+}
+
+func TestGetDestinationsNDJSONFlushes(t *testing.T) {
+	dests := make([]types.Destination, 2*ndjsonFlushEvery+1)
+	for i := range dests {
+		dests[i] = testDestinations[0]
+		dests[i].ID = fmt.Sprintf("d%03d", i)
+		dests[i].Name = dests[i].ID
+	}
+	router := newTestRouter(testConfig(t), dests)
+	rec := serve(router, http.MethodGet, "/api/destinations?format=ndjson", "")
+	if !rec.Flushed {
+		t.Error("large stream was never flushed")
+	}
+	// A flushed stream can't be hashed, so it passes through the ETag middleware
+	if rec.Header().Get("ETag") != "" {
+		t.Errorf("streamed response tagged %s", rec.Header().Get("ETag"))
+	}
+	if got := readNDJSON(t, rec.Body.String()); len(got) != len(dests) {
+		t.Errorf("streamed %d lines, want %d", len(got), len(dests))
+	}
+}
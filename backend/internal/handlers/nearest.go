@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/simonryrie/otherwhere/internal/ranking"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// defaultNearestLimit is how many nearby destinations are returned by default
+const defaultNearestLimit = 5
+
+// GetNearestDestinations returns the destinations closest to the lat and lon
+// query parameters, nearest first, with each one's distance_km rounded to
+// 0.1 km
+func (h *Handler) GetNearestDestinations(w http.ResponseWriter, r *http.Request) error {
+	origin, err := parseCoordinates(r)
+	if err != nil {
+		return badRequest(err)
+	}
+	limit, err := intParam(r, "limit")
+	if err != nil {
+		return badRequest(err)
+	}
+	if limit < 0 {
+		return badRequestf("limit must not be negative")
+	}
+	if limit == 0 {
+		limit = defaultNearestLimit
+	}
+	limit = min(limit, maxLimit)
+	view, err := h.viewOptions(r)
+	if err != nil {
+		return badRequest(err)
+	}
+	fields, err := parseFields(r)
+	if err != nil {
+		return badRequest(err)
+	}
+
+	destinations, err := storeFromContext(r.Context()).List(r.Context())
+	if err != nil {
+		return fmt.Errorf("list destinations: %w", err)
+	}
+
+	neighbors := ranking.Nearest(origin, destinations, limit)
+	views := make([]types.DestinationView, len(neighbors))
+	for i, n := range neighbors {
+		views[i] = newDestinationView(n.Destination, view)
+		km := math.Round(n.DistanceKm*10) / 10
+		views[i].DistanceKm = &km
+	}
+	writeFields(w, r, http.StatusOK, types.DestinationsResponse{
+		Destinations: views,
+		Total:        len(views),
+	}, fields)
+	return nil
+}
+
+// parseCoordinates reads the required lat and lon query parameters
+func parseCoordinates(r *http.Request) (types.Location, error) {
+	q := r.URL.Query()
+	lat, err := strconv.ParseFloat(q.Get("lat"), 64)
+	if err != nil {
+		return types.Location{}, fmt.Errorf("lat must be a number, got %q", q.Get("lat"))
+	}
+	lon, err := strconv.ParseFloat(q.Get("lon"), 64)
+	if err != nil {
+		return types.Location{}, fmt.Errorf("lon must be a number, got %q", q.Get("lon"))
+	}
+	loc := types.Location{Lat: lat, Lon: lon}
+	return loc, loc.Validate()
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// nearestList decodes a nearest response. DestinationView can't be decoded
+// directly, since the embedded Destination's UnmarshalJSON takes over.
+type nearestList struct {
+	Destinations []struct {
+		ID         string   `json:"id"`
+		DistanceKm *float64 `json:"distance_km"`
+	} `json:"destinations"`
+}
+
+func TestGetNearestDestinations(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	// From Geneva
+	tests := []struct {
+		name, query string
+		want        []string
+	}{
+		{"default limit", "lat=46.2&lon=6.15", []string{"zermatt", "lofoten", "tamarindo", "tokyo"}},
+		{"limit", "lat=46.2&lon=6.15&limit=2", []string{"zermatt", "lofoten"}},
+		{"from Tokyo", "lat=35.68&lon=139.69&limit=1", []string{"tokyo"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got nearestList
+			decodeData(t, serve(router, http.MethodGet, "/api/destinations/nearest?"+tt.query, ""), http.StatusOK, &got)
+			ids := make([]string, len(got.Destinations))
+			for i, d := range got.Destinations {
+				ids[i] = d.ID
+				if d.DistanceKm == nil {
+					t.Fatalf("%s has no distance_km", d.ID)
+				}
+				if i > 0 && *d.DistanceKm < *got.Destinations[i-1].DistanceKm {
+					t.Errorf("%s at %g km follows %g km", d.ID, *d.DistanceKm, *got.Destinations[i-1].DistanceKm)
+				}
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("nearest = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetNearestDestinationsDistance(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	var got nearestList
+	decodeData(t, serve(router, http.MethodGet, "/api/destinations/nearest?lat=46.02&lon=7.75&limit=1", ""), http.StatusOK, &got)
+	if d := got.Destinations[0]; d.ID != "zermatt" || *d.DistanceKm != 0 {
+		t.Errorf("nearest to Zermatt = %s at %g km, want zermatt at 0", d.ID, *d.DistanceKm)
+	}
+}
+
+func TestGetNearestDestinationsBoundaries(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	for _, query := range []string{"lat=90&lon=180", "lat=-90&lon=-180"} {
+		t.Run(query, func(t *testing.T) {
+			var got resultList
+			decodeData(t, serve(router, http.MethodGet, "/api/destinations/nearest?"+query, ""), http.StatusOK, &got)
+			if len(got.Destinations) == 0 {
+				t.Error("no destinations near a valid boundary coordinate")
+			}
+		})
+	}
+}
+
+func TestGetNearestDestinationsRejects(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	tests := []struct {
+		name, query string
+		// wantMsg is a substring of the expected error message
+		wantMsg string
+	}{
+		{"missing lat", "lon=6.15", `lat must be a number, got ""`},
+		{"missing lon", "lat=46.2", `lon must be a number, got ""`},
+		{"lat not a number", "lat=north&lon=6.15", `lat must be a number, got "north"`},
+		{"lat out of range", "lat=91&lon=6.15", "lat must be between -90 and 90, got 91"},
+		{"lon out of range", "lat=46.2&lon=-180.5", "lon must be between -180 and 180, got -180.5"},
+		{"NaN", "lat=NaN&lon=6.15", "lat must be between -90 and 90, got NaN"},
+		{"lat just outside", "lat=-90.01&lon=6.15", "lat must be between -90 and 90, got -90.01"},
+		{"lon just outside", "lat=46.2&lon=180.01", "lon must be between -180 and 180, got 180.01"},
+		{"negative limit", "lat=46.2&lon=6.15&limit=-1", "limit must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := decodeError(t, serve(router, http.MethodGet, "/api/destinations/nearest?"+tt.query, ""), http.StatusBadRequest)
+			if !strings.Contains(got.Message, tt.wantMsg) {
+				t.Errorf("message = %q, want it to contain %q", got.Message, tt.wantMsg)
+			}
+		})
+	}
+}
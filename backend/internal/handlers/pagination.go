@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/simonryrie/otherwhere/internal/config"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// maxLimit caps the page size of endpoints without configurable page sizes
+const maxLimit = 100
+
+// page is a validated limit/offset window into a result list
+type page struct {
+	limit  int
+	offset int
+}
+
+// newPage validates a requested window, applying limits' default to a zero
+// limit and capping oversized ones at its max
+func newPage(limit, offset int, limits config.PageLimits) (page, error) {
+	if errs := pageErrors(limit, offset); len(errs) > 0 {
+		return page{}, errs[0]
+	}
+	if limit == 0 {
+		limit = limits.Default
+	}
+	return page{limit: min(limit, limits.Max), offset: offset}, nil
+}
+
+// pageErrors returns every problem with a requested window
+func pageErrors(limit, offset int) []error {
+	var errs []error
+	if limit < 0 {
+		errs = append(errs, errors.New("limit must not be negative"))
+	}
+	if offset < 0 {
+		errs = append(errs, errors.New("offset must not be negative"))
+	}
+	return errs
+}
+
+// meta describes the page for a result list of the given total size
+func (p page) meta(total int) *types.PageMeta {
+	return &types.PageMeta{Total: total, Limit: p.limit, Offset: p.offset}
+}
+
+// parsePage reads the limit and offset query parameters
+func parsePage(r *http.Request, limits config.PageLimits) (page, error) {
+	limit, err := intParam(r, "limit")
+	if err != nil {
+		return page{}, err
+	}
+	offset, err := intParam(r, "offset")
+	if err != nil {
+		return page{}, err
+	}
+	return newPage(limit, offset, limits)
+}
+
+// intParam parses an optional integer query parameter, returning 0 when absent
+func intParam(r *http.Request, name string) (int, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, errors.New(name + " must be an integer")
+	}
+	return v, nil
+}
+
+// boolParam parses an optional boolean query parameter, returning false when absent
+func boolParam(r *http.Request, name string) (bool, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return false, nil
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, errors.New(name + " must be true or false")
+	}
+	return v, nil
+}
+
+// floatParam parses an optional numeric query parameter, returning def when absent
+func floatParam(r *http.Request, name string, def float64) (float64, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return def, nil
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, errors.New(name + " must be a number")
+	}
+	return v, nil
+}
+
+// paginate returns the slice of items covered by the page. An offset past
+// the end yields an empty, non-nil slice so it still encodes as [].
+func paginate[T any](items []T, p page) []T {
+	if p.offset >= len(items) {
+		return []T{}
+	}
+	end := min(p.offset+p.limit, len(items))
+	return items[p.offset:end]
+}
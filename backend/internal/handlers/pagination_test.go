@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/config"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestPaginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	tests := []struct {
+		name string
+		p    page
+		want []int
+	}{
+		{"first page", page{limit: 2}, []int{1, 2}},
+		{"middle page", page{limit: 2, offset: 2}, []int{3, 4}},
+		{"last page is short", page{limit: 2, offset: 4}, []int{5}},
+		{"offset at total", page{limit: 2, offset: 5}, []int{}},
+		{"offset past total", page{limit: 2, offset: 50}, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := paginate(items, tt.p)
+			if got == nil || !slices.Equal(got, tt.want) {
+				t.Errorf("paginate = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOffsetPastTotal(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	tests := []struct {
+		method, target, body string
+		total                int
+	}{
+		{http.MethodGet, "/api/destinations?offset=100", "", 4},
+		{http.MethodGet, "/api/destinations?continent=Europe&offset=2", "", 2},
+		{http.MethodPost, "/api/search", `{"offset":100}`, 4},
+		{http.MethodPost, "/api/search", `{"query":"beach","offset":100}`, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target+" "+tt.body, func(t *testing.T) {
+			var got resultList
+			decodeData(t, serve(router, tt.method, tt.target, tt.body), http.StatusOK, &got)
+			if got.Destinations == nil || len(got.Destinations) != 0 {
+				t.Errorf("destinations = %v, want []", got.ids())
+			}
+			if got.Total != tt.total {
+				t.Errorf("total = %d, want %d", got.Total, tt.total)
+			}
+		})
+	}
+}
+
+func TestNegativePaging(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	for _, target := range []string{"/api/destinations?offset=-1", "/api/destinations?limit=-1"} {
+		decodeError(t, serve(router, http.MethodGet, target, ""), http.StatusBadRequest)
+	}
+	decodeError(t, serve(router, http.MethodPost, "/api/search", `{"offset":-1}`), http.StatusBadRequest)
+}
+
+func TestNewPage(t *testing.T) {
+	limits := config.PageLimits{Default: 20, Max: 100}
+	tests := []struct {
+		limit, offset int
+		want          page
+	}{
+		{0, 0, page{limit: 20}},
+		{5, 10, page{limit: 5, offset: 10}},
+		{100, 0, page{limit: 100}},
+		{1000, 0, page{limit: 100}},
+	}
+	for _, tt := range tests {
+		if got, err := newPage(tt.limit, tt.offset, limits); err != nil || got != tt.want {
+			t.Errorf("newPage(%d, %d) = %+v, %v; want %+v", tt.limit, tt.offset, got, err, tt.want)
+		}
+	}
+}
+
+func TestResultCap(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.DestinationsPage = config.PageLimits{Default: 1, Max: 2}
+	cfg.SearchPage = config.PageLimits{Default: 1, Max: 2}
+	router := newTestRouter(cfg, testDestinations)
+	tests := []struct {
+		method, target, body string
+	}{
+		{http.MethodGet, "/api/destinations?limit=1000", ""},
+		{http.MethodPost, "/api/search", `{"limit":1000}`},
+		{http.MethodGet, "/api/search?limit=1000", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			var got resultList
+			decodeData(t, serve(router, tt.method, tt.target, tt.body), http.StatusOK, &got)
+			if len(got.Destinations) != 2 {
+				t.Errorf("returned %d destinations, want the cap of 2", len(got.Destinations))
+			}
+			if got.Total != len(testDestinations) {
+				t.Errorf("total = %d, want the uncapped %d", got.Total, len(testDestinations))
+			}
+			if got.Meta == nil || *got.Meta != (types.PageMeta{Total: len(testDestinations), Limit: 2}) {
+				t.Errorf("meta = %+v, want total %d, limit 2, offset 0", got.Meta, len(testDestinations))
+			}
+		})
+	}
+}
+
+func TestPageLimitsPerEndpoint(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.DestinationsPage = config.PageLimits{Default: 1, Max: 2}
+	cfg.SearchPage = config.PageLimits{Default: 2, Max: 3}
+	cfg.SimilarPage = config.PageLimits{Default: 1, Max: 2}
+	router := newTestRouter(cfg, testDestinations)
+	tests := []struct {
+		name, method, target, body string
+		// wantLimit is the effective limit, which is also the page size
+		// since every endpoint here has more results than that
+		wantLimit int
+	}{
+		{"destinations default", http.MethodGet, "/api/destinations", "", 1},
+		{"destinations requested", http.MethodGet, "/api/destinations?limit=2", "", 2},
+		{"destinations clamped", http.MethodGet, "/api/destinations?limit=50", "", 2},
+		{"search default", http.MethodPost, "/api/search", `{}`, 2},
+		{"search clamped", http.MethodPost, "/api/search", `{"limit":50}`, 3},
+		{"search query string default", http.MethodGet, "/api/search", "", 2},
+		{"search query string clamped", http.MethodGet, "/api/search?limit=50", "", 3},
+		{"similar default", http.MethodGet, "/api/destinations/zermatt/similar", "", 1},
+		{"similar clamped", http.MethodGet, "/api/destinations/zermatt/similar?limit=50", "", 2},
+		{"more-like default", http.MethodPost, "/api/search/more-like", `{"ids":["zermatt"]}`, 1},
+		{"more-like clamped", http.MethodPost, "/api/search/more-like", `{"ids":["zermatt"],"limit":50}`, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got resultList
+			decodeData(t, serve(router, tt.method, tt.target, tt.body), http.StatusOK, &got)
+			if len(got.Destinations) != tt.wantLimit {
+				t.Errorf("returned %d destinations, want %d", len(got.Destinations), tt.wantLimit)
+			}
+			if got.Meta == nil || got.Meta.Limit != tt.wantLimit {
+				t.Errorf("meta = %+v, want limit %d", got.Meta, tt.wantLimit)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// redacted reports whether responses hide the feature key. Redacted features
+// still take part in filtering and ranking.
+func (h *Handler) redacted(key string) bool {
+	return slices.Contains(h.redactedFeatures, key)
+}
+
+// redactKeys drops the redacted features from a map keyed by feature
+func (h *Handler) redactKeys(m map[string]float64) map[string]float64 {
+	maps.DeleteFunc(m, func(key string, _ float64) bool { return h.redacted(key) })
+	return m
+}
+
+// redactFacets drops the facets of redacted features
+func (h *Handler) redactFacets(facets []types.FeatureFacet) []types.FeatureFacet {
+	return slices.DeleteFunc(facets, func(f types.FeatureFacet) bool { return h.redacted(f.Key) })
+}
+
+// redactComparisons drops the comparison rows of redacted features
+func (h *Handler) redactComparisons(rows []types.FeatureComparison) []types.FeatureComparison {
+	return slices.DeleteFunc(rows, func(c types.FeatureComparison) bool { return h.redacted(c.Key) })
+}
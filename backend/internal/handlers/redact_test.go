@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// redactedRouter serves testDestinations with gdp_per_capita and
+// development_level redacted
+func redactedRouter(t *testing.T) http.Handler {
+	t.Helper()
+	cfg := testConfig(t)
+	cfg.RedactedFeatures = []string{"gdp_per_capita", "development_level"}
+	return newTestRouter(cfg, testDestinations)
+}
+
+func TestRedactedFeaturesAbsent(t *testing.T) {
+	router := redactedRouter(t)
+	tests := []struct {
+		name, method, target, body string
+	}{
+		{"destination", http.MethodGet, "/api/destinations/tokyo", ""},
+		{"list", http.MethodGet, "/api/destinations", ""},
+		{"search with breakdowns and facets", http.MethodPost, "/api/search?explain=true&facets=true", `{"query":"ski"}`},
+		{"similar", http.MethodGet, "/api/destinations/zermatt/similar", ""},
+		{"compare", http.MethodPost, "/api/compare", `{"ids":["tokyo","zermatt"]}`},
+		{"continent stats", http.MethodGet, "/api/stats/continents", ""},
+		{"csv", http.MethodGet, "/api/destinations.csv", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(router, tt.method, tt.target, tt.body)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d; body: %s", rec.Code, rec.Body)
+			}
+			body := rec.Body.String()
+			for _, key := range []string{"gdp_per_capita", "development_level"} {
+				if strings.Contains(body, key) {
+					t.Errorf("response mentions redacted %s: %s", key, body)
+				}
+			}
+			// Unredacted features are still there
+			if !strings.Contains(body, "nightlife_density") {
+				t.Errorf("response lost unredacted features: %s", body)
+			}
+		})
+	}
+}
+
+func TestRedactedFeaturesStillRank(t *testing.T) {
+	redacted := redactedRouter(t)
+	open := newTestRouter(testConfig(t), testDestinations)
+	tests := []struct {
+		name, body string
+		want       []string
+	}{
+		// Only lofoten and zermatt are affluent enough, lofoten nearer the bound
+		{"filtered on", `{"constraints":{"gdp_per_capita":{"min":0.85}}}`, []string{"lofoten", "zermatt"}},
+		{"ranked on", `{"feature_vector":{"gdp_per_capita":1,"development_level":1}}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got, want resultList
+			decodeData(t, serve(redacted, http.MethodPost, "/api/search", tt.body), http.StatusOK, &got)
+			decodeData(t, serve(open, http.MethodPost, "/api/search", tt.body), http.StatusOK, &want)
+			if !slices.Equal(got.ids(), want.ids()) {
+				t.Errorf("redacted order = %v, want the unredacted %v", got.ids(), want.ids())
+			}
+			if tt.want != nil && !slices.Equal(got.ids(), tt.want) {
+				t.Errorf("results = %v, want %v", got.ids(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactedDerivedFields(t *testing.T) {
+	tests := []struct {
+		redacted []string
+		// gone and features are the top-level fields and features that
+		// must be absent
+		gone, features []string
+	}{
+		{[]string{"avg_temp_c"}, []string{"temperature"}, []string{"avg_temp_c", "monthly_temp_c"}},
+		{[]string{"coast_distance_km"}, []string{"is_coastal"}, []string{"coast_distance_km"}},
+		{[]string{"wikipedia_pageviews"}, []string{"tier"}, []string{"wikipedia_pageviews"}},
+		{[]string{"tourism_density"}, []string{"tier"}, []string{"tourism_density"}},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.redacted, ","), func(t *testing.T) {
+			cfg := testConfig(t)
+			cfg.RedactedFeatures = tt.redacted
+			router := newTestRouter(cfg, testDestinations)
+			var obj, features map[string]json.RawMessage
+			decodeData(t, serve(router, http.MethodGet, "/api/destinations/tokyo", ""), http.StatusOK, &obj)
+			if err := json.Unmarshal(obj["features"], &features); err != nil {
+				t.Fatalf("decode features: %v", err)
+			}
+			for _, field := range tt.gone {
+				if _, ok := obj[field]; ok {
+					t.Errorf("%s present with %v redacted", field, tt.redacted)
+				}
+			}
+			for _, key := range tt.features {
+				if _, ok := features[key]; ok {
+					t.Errorf("features.%s present with %v redacted", key, tt.redacted)
+				}
+			}
+			if _, ok := obj["name"]; !ok {
+				t.Error("unrelated fields dropped too")
+			}
+		})
+	}
+}
+
+// TestRedactedFeaturesMissing checks redacted keys are dropped from
+// missing_features too
+func TestRedactedFeaturesMissing(t *testing.T) {
+	dests := slices.Clone(testDestinations)
+	dests[2].MissingFeatures = []string{"gdp_per_capita", "elevation"}
+	cfg := testConfig(t)
+	cfg.RedactedFeatures = []string{"gdp_per_capita"}
+	var got types.DestinationView
+	decodeData(t, serve(newTestRouter(cfg, dests), http.MethodGet, "/api/destinations/"+dests[2].ID, ""), http.StatusOK, &got)
+	if !slices.Equal(got.MissingFeatures, []string{"elevation"}) {
+		t.Errorf("missing_features = %q, want only elevation", got.MissingFeatures)
+	}
+}
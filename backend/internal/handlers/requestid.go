@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// RequestIDHeader is the response header echoing the request's ID
+const RequestIDHeader = "X-Request-ID"
+
+// EchoRequestID sets RequestIDHeader to the ID assigned by chi's RequestID
+// middleware, which must run first
+func EchoRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := middleware.GetReqID(r.Context()); id != "" {
+			w.Header().Set(RequestIDHeader, id)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// envelope wraps every API response body, keeping the payload under data
+type envelope struct {
+	Data any                 `json:"data"`
+	Meta *types.ResponseMeta `json:"meta,omitempty"`
+}
+
+// responseMeta describes the request a response answers; nil when the
+// request has no ID
+func responseMeta(r *http.Request) *types.ResponseMeta {
+	id := middleware.GetReqID(r.Context())
+	if id == "" {
+		return nil
+	}
+	return &types.ResponseMeta{RequestID: id}
+}
+
+// writeData writes v as the data of an API response envelope
+func writeData(w http.ResponseWriter, r *http.Request, status int, v any) {
+	// Destination names and descriptions follow Accept-Language
+	w.Header().Add("Vary", "Accept-Language")
+	writeJSON(w, status, envelope{Data: v, Meta: responseMeta(r)})
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestIDEchoed(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	tests := []struct {
+		name, method, target, body string
+		status                     int
+	}{
+		{"data", http.MethodGet, "/api/destinations/tokyo", "", http.StatusOK},
+		{"handler error", http.MethodGet, "/api/destinations/atlantis", "", http.StatusNotFound},
+		{"unknown route", http.MethodGet, "/api/nowhere", "", http.StatusNotFound},
+		{"method not allowed", http.MethodDelete, "/api/search", "", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(router, tt.method, tt.target, tt.body)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			var body struct {
+				Meta struct {
+					RequestID string `json:"request_id"`
+				} `json:"meta"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			header := rec.Header().Get(RequestIDHeader)
+			if header == "" || body.Meta.RequestID != header {
+				t.Errorf("meta.request_id = %q, %s = %q; want them equal and set", body.Meta.RequestID, RequestIDHeader, header)
+			}
+		})
+	}
+}
+
+func TestRequestIDFromClient(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	req := httptest.NewRequest(http.MethodGet, "/api/destinations/tokyo", nil)
+	req.Header.Set(RequestIDHeader, "support-1234")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if got := rec.Header().Get(RequestIDHeader); got != "support-1234" {
+		t.Errorf("%s = %q, want the client's ID", RequestIDHeader, got)
+	}
+	if !strings.Contains(rec.Body.String(), `"request_id":"support-1234"`) {
+		t.Errorf("body doesn't carry the client's ID: %s", rec.Body)
+	}
+}
+
+func TestResponseWithoutRequestID(t *testing.T) {
+	// Without chi's RequestID middleware there is no ID, so no meta
+	rec := httptest.NewRecorder()
+	writeData(rec, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, "ok")
+	if got := strings.TrimSpace(rec.Body.String()); got != `{"data":"ok"}` {
+		t.Errorf("body = %s, want {\"data\":\"ok\"}", got)
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+
+	"golang.org/x/text/language"
+
+	"github.com/simonryrie/otherwhere/internal/ranking"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// parseUnits reads the units query parameter, defaulting to Celsius
+func parseUnits(r *http.Request) (types.TemperatureUnit, error) {
+	switch unit := types.TemperatureUnit(strings.ToLower(r.URL.Query().Get("units"))); unit {
+	case "", types.Celsius:
+		return types.Celsius, nil
+	case types.Fahrenheit:
+		return types.Fahrenheit, nil
+	default:
+		return "", fmt.Errorf("units must be %q or %q", types.Celsius, types.Fahrenheit)
+	}
+}
+
+// viewOptions controls how destinations are rendered in a response
+type viewOptions struct {
+	unit types.TemperatureUnit
+	// placeholderImage stands in for destinations without images
+	placeholderImage string
+	// coastalKm sets the IsCoastal threshold
+	coastalKm float64
+	// tiers sets the Tier thresholds
+	tiers ranking.TierThresholds
+	// redacted are the feature keys left out of rendered destinations
+	redacted []string
+	// languages are the client's preferred languages for names and
+	// descriptions, most preferred first
+	languages []language.Tag
+}
+
+// viewOptions reads the request's rendering options, filling in server defaults
+func (h *Handler) viewOptions(r *http.Request) (viewOptions, error) {
+	unit, err := parseUnits(r)
+	if err != nil {
+		return viewOptions{}, err
+	}
+	return viewOptions{
+		unit:             unit,
+		placeholderImage: h.placeholderImage,
+		coastalKm:        h.coastalKm,
+		tiers:            h.tiers,
+		redacted:         h.redactedFeatures,
+		languages:        parseLanguages(r),
+	}, nil
+}
+
+// newDestinationView builds the response representation of d
+func newDestinationView(d types.Destination, opts viewOptions) types.DestinationView {
+	if len(d.Images) == 0 && opts.placeholderImage != "" {
+		d.Images = []types.Image{{URL: opts.placeholderImage}}
+	}
+	d.Name = localize(d.Name, d.Names, opts.languages)
+	if d.Description != nil {
+		desc := localize(*d.Description, d.Descriptions, opts.languages)
+		d.Description = &desc
+	}
+	return types.DestinationView{
+		Destination: d,
+		Temperature: types.Temperature{
+			Avg:  displayTemp(ranking.CelsiusFromNormalized(d.Features.AvgTempC), opts.unit),
+			Unit: opts.unit,
+		},
+		IsCoastal: ranking.IsCoastal(d, opts.coastalKm),
+		Tier:      opts.tiers.Tier(d),
+
+		RedactedFeatures: opts.redacted,
+	}
+}
+
+// displayTemp converts a °C value to unit, rounded to one decimal place
+func displayTemp(celsius float64, unit types.TemperatureUnit) float64 {
+	if unit == types.Fahrenheit {
+		celsius = celsius*9/5 + 32
+	}
+	return math.Round(celsius*10) / 10
+}
+
+// newDestinationViews builds response representations for a list of destinations
+func newDestinationViews(dests []types.Destination, opts viewOptions) []types.DestinationView {
+	views := make([]types.DestinationView, len(dests))
+	for i, d := range dests {
+		views[i] = newDestinationView(d, opts)
+	}
+	return views
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestDisplayTemp(t *testing.T) {
+	tests := []struct {
+		celsius float64
+		unit    types.TemperatureUnit
+		want    float64
+	}{
+		{20, types.Celsius, 20},
+		{16.24, types.Celsius, 16.2},
+		{0, types.Fahrenheit, 32},
+		{-40, types.Fahrenheit, -40},
+		{100, types.Fahrenheit, 212},
+		{16.2, types.Fahrenheit, 61.2},
+	}
+	for _, tt := range tests {
+		if got := displayTemp(tt.celsius, tt.unit); got != tt.want {
+			t.Errorf("displayTemp(%g, %s) = %g, want %g", tt.celsius, tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestUnits(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	tests := []struct {
+		query string
+		want  types.Temperature
+	}{
+		// Tokyo's normalized 0.52 is 16.2°C on the -15 to 45 °C scale
+		{"", types.Temperature{Avg: 16.2, Unit: types.Celsius}},
+		{"?units=c", types.Temperature{Avg: 16.2, Unit: types.Celsius}},
+		{"?units=F", types.Temperature{Avg: 61.2, Unit: types.Fahrenheit}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var got struct {
+				Temperature types.Temperature `json:"temperature"`
+				Features    struct {
+					AvgTempC float64 `json:"avg_temp_c"`
+				} `json:"features"`
+			}
+			decodeData(t, serve(router, http.MethodGet, "/api/destinations/tokyo"+tt.query, ""), http.StatusOK, &got)
+			if got.Temperature != tt.want {
+				t.Errorf("temperature = %+v, want %+v", got.Temperature, tt.want)
+			}
+			if got.Features.AvgTempC != 0.52 {
+				t.Errorf("features.avg_temp_c = %g, want the stored 0.52", got.Features.AvgTempC)
+			}
+		})
+	}
+
+	decodeError(t, serve(router, http.MethodGet, "/api/destinations/tokyo?units=k", ""), http.StatusBadRequest)
+	decodeError(t, serve(router, http.MethodPost, "/api/search?units=k", `{}`), http.StatusBadRequest)
+}
+
+func TestUnitsLeaveConstraintsAlone(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	// 0.58 is 20°C; read as Fahrenheit it would let in everywhere
+	body := `{"constraints":{"avg_temp_c":{"min":0.58}}}`
+	var celsius, fahrenheit resultList
+	decodeData(t, serve(router, http.MethodPost, "/api/search", body), http.StatusOK, &celsius)
+	decodeData(t, serve(router, http.MethodPost, "/api/search?units=f", body), http.StatusOK, &fahrenheit)
+	if want := []string{"tamarindo"}; !slices.Equal(celsius.ids(), want) || !slices.Equal(fahrenheit.ids(), want) {
+		t.Errorf("results = %v in °C and %v in °F, want %v for both", celsius.ids(), fahrenheit.ids(), want)
+	}
+}
+
+func TestPlaceholderImage(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.PlaceholderImageURL = "https://cdn.example.com/placeholder.png"
+	withImage := types.Destination{ID: "lisbon", Name: "Lisbon", Active: true, Images: []types.Image{{URL: "https://example.com/lisbon.jpg"}}}
+	router := newTestRouter(cfg, append([]types.Destination{withImage}, testDestinations...))
+
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"lisbon", "https://example.com/lisbon.jpg"},
+		{"tokyo", "https://cdn.example.com/placeholder.png"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			var got struct {
+				Images []types.Image `json:"images"`
+			}
+			decodeData(t, serve(router, http.MethodGet, "/api/destinations/"+tt.id, ""), http.StatusOK, &got)
+			if len(got.Images) != 1 || got.Images[0].URL != tt.want {
+				t.Errorf("images = %+v, want just %s", got.Images, tt.want)
+			}
+		})
+	}
+}
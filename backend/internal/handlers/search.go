@@ -0,0 +1,513 @@
+package handlers
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/simonryrie/otherwhere/internal/ranking"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// maxQueryLength caps the free-text query to keep tokenizing cheap
+const maxQueryLength = 500
+
+// maxExcludeIDs caps how many destinations a search can exclude
+const maxExcludeIDs = 100
+
+// validateSearchRequest checks a decoded search request before it's
+// executed, returning the first of its searchRequestErrors
+func validateSearchRequest(req types.SearchRequest, maxComplexity int) error {
+	if errs := searchRequestErrors(req, maxComplexity); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// searchRequestErrors returns every problem with a decoded search request,
+// including a ranking.Complexity over maxComplexity. Constraints, weights,
+// and avoid terms are checked one feature at a time, in key order, so each
+// bad one is reported.
+func searchRequestErrors(req types.SearchRequest, maxComplexity int) []error {
+	var errs []error
+	if n := utf8.RuneCountInString(req.Query); n > maxQueryLength {
+		errs = append(errs, fmt.Errorf("query must be at most %d characters, got %d", maxQueryLength, n))
+	}
+	if len(req.Exclude) > maxExcludeIDs {
+		errs = append(errs, fmt.Errorf("exclude must list at most %d destinations, got %d", maxExcludeIDs, len(req.Exclude)))
+	}
+	if req.MinScore != nil && (*req.MinScore < 0 || *req.MinScore > 1 || math.IsNaN(*req.MinScore)) {
+		errs = append(errs, fmt.Errorf("min_score must be between 0 and 1, got %g", *req.MinScore))
+	}
+	if n := ranking.Complexity(req); n > maxComplexity {
+		errs = append(errs, fmt.Errorf("search is too complex: %d constraints, groups, weights, and avoid terms, at most %d allowed", n, maxComplexity))
+	}
+	if req.Preset != "" {
+		if _, err := ranking.PresetByName(req.Preset); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if req.FeatureVector != nil {
+		if req.Query != "" || req.Preset != "" {
+			errs = append(errs, fmt.Errorf("feature_vector can't be combined with query or preset"))
+		}
+		if err := ranking.ValidateFeatureVector(*req.FeatureVector); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	parsed, err := ranking.ParseQuery(req.Query)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if req.Constraints != nil {
+		valid := true
+		for _, key := range slices.Sorted(maps.Keys(*req.Constraints)) {
+			if err := ranking.ValidateConstraints(types.SearchConstraints{key: (*req.Constraints)[key]}); err != nil {
+				errs = append(errs, err)
+				valid = false
+			}
+		}
+		// Conflicts with the query only mean something between valid bounds
+		if valid && parsed != nil {
+			if _, err := ranking.MergeConstraints(parsed, *req.Constraints); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if req.Where != nil {
+		if err := ranking.ValidateConstraintGroup(*req.Where); err != nil {
+			errs = append(errs, fmt.Errorf("where: %w", err))
+		}
+	}
+	weightsValid := true
+	for _, key := range slices.Sorted(maps.Keys(req.Weights)) {
+		if _, err := ranking.NormalizeWeights(map[string]float64{key: req.Weights[key]}); err != nil {
+			errs = append(errs, err)
+			weightsValid = false
+		}
+	}
+	// Each weight can be valid on its own while the set sums to zero
+	if weightsValid {
+		if _, err := ranking.NormalizeWeights(req.Weights); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(req.Avoid)) {
+		if _, err := ranking.AvoidVector(map[string]float64{key: req.Avoid[key]}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := ranking.ValidateFilters(req.Filters); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// resolveAliases rewrites feature aliases in req's constraints, where
+// groups, weights, and avoid terms to feature keys, returning the first of
+// its aliasErrors
+func (h *Handler) resolveAliases(req *types.SearchRequest) error {
+	if errs := h.aliasErrors(req); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// aliasErrors rewrites feature aliases in req to feature keys, returning an
+// error for each of constraints, where, weights, and avoid that names
+// something neither a key nor an alias. Those parts are dropped from req so
+// later checks don't report the same names again.
+func (h *Handler) aliasErrors(req *types.SearchRequest) []error {
+	var errs []error
+	if req.Constraints != nil {
+		c, err := ranking.ResolveKeys(h.aliases, *req.Constraints)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("constraints: %w", err))
+			req.Constraints = nil
+		} else {
+			resolved := types.SearchConstraints(c)
+			req.Constraints = &resolved
+		}
+	}
+	if req.Where != nil {
+		if err := ranking.ResolveGroup(h.aliases, req.Where); err != nil {
+			errs = append(errs, fmt.Errorf("where: %w", err))
+			req.Where = nil
+		}
+	}
+	var err error
+	if req.Weights, err = ranking.ResolveKeys(h.aliases, req.Weights); err != nil {
+		errs = append(errs, fmt.Errorf("weights: %w", err))
+	}
+	if req.Avoid, err = ranking.ResolveKeys(h.aliases, req.Avoid); err != nil {
+		errs = append(errs, fmt.Errorf("avoid: %w", err))
+	}
+	return errs
+}
+
+// clampSearchRequest clamps the constraint bounds of req onto the normalized
+// scale when the handler is configured to, logging the features it changed
+func (h *Handler) clampSearchRequest(r *http.Request, req *types.SearchRequest) {
+	if !h.clampConstraints {
+		return
+	}
+	var clamped []string
+	if req.Constraints != nil {
+		clamped = ranking.ClampConstraints(*req.Constraints)
+	}
+	if req.Where != nil {
+		clamped = append(clamped, ranking.ClampConstraintGroup(req.Where)...)
+	}
+	if len(clamped) > 0 {
+		requestLogger(r).Warn("clamped out-of-range search constraints", "features", clamped)
+	}
+}
+
+// Search ranks destinations by similarity to the vibe in the JSON body
+func (h *Handler) Search(w http.ResponseWriter, r *http.Request) error {
+	var req types.SearchRequest
+	if err := decodeStrictJSON(r, &req); err != nil {
+		return err
+	}
+	return h.search(w, r, req)
+}
+
+// SearchQuery is Search with the request expressed as query parameters
+// (see parseSearchQuery), so searches can be linked and cached
+func (h *Handler) SearchQuery(w http.ResponseWriter, r *http.Request) error {
+	req, err := parseSearchQuery(r)
+	if err != nil {
+		return badRequest(err)
+	}
+	return h.search(w, r, req)
+}
+
+// search runs a decoded search request and writes its results
+func (h *Handler) search(w http.ResponseWriter, r *http.Request, req types.SearchRequest) error {
+	start := time.Now()
+	logger := requestLogger(r)
+
+	if err := h.resolveAliases(&req); err != nil {
+		return badRequest(err)
+	}
+	h.clampSearchRequest(r, &req)
+	if err := validateSearchRequest(req, h.maxComplexity); err != nil {
+		return badRequest(err)
+	}
+
+	p, err := newPage(req.Limit, req.Offset, h.searchPage)
+	if err != nil {
+		return badRequest(err)
+	}
+	view, err := h.viewOptions(r)
+	if err != nil {
+		return badRequest(err)
+	}
+	fields, err := parseFields(r)
+	if err != nil {
+		return badRequest(err)
+	}
+	// intParam reads an absent month as 0, so an explicit 0 is checked apart
+	month, err := intParam(r, "month")
+	if err != nil || month < 0 || month > 12 || (month == 0 && r.URL.Query().Get("month") != "") {
+		return badRequestf("month must be between 1 and 12")
+	}
+	explain, err := boolParam(r, "explain")
+	if err != nil {
+		return badRequest(err)
+	}
+	summary, err := boolParam(r, "summary")
+	if err != nil {
+		return badRequest(err)
+	}
+	facets, err := boolParam(r, "facets")
+	if err != nil {
+		return badRequest(err)
+	}
+	mountain, err := boolParam(r, "mountain")
+	if err != nil {
+		return badRequest(err)
+	}
+	elevation, err := elevationConstraints(r)
+	if err != nil {
+		return badRequest(err)
+	}
+	coastal, err := boolParam(r, "coastal")
+	if err != nil {
+		return badRequest(err)
+	}
+	tier, err := tierParam(r)
+	if err != nil {
+		return badRequest(err)
+	}
+	comfort, err := boolParam(r, "comfort")
+	if err != nil {
+		return badRequest(err)
+	}
+	collapse, err := boolParam(r, "collapse")
+	if err != nil {
+		return badRequest(err)
+	}
+	diversify, err := boolParam(r, "diversify")
+	if err != nil {
+		return badRequest(err)
+	}
+	lambda, err := floatParam(r, "lambda", ranking.DefaultDiversityLambda)
+	if err != nil {
+		return badRequest(err)
+	}
+	if err := ranking.ValidateLambda(lambda); err != nil {
+		return badRequest(err)
+	}
+	seed, err := seedParam(r)
+	if err != nil {
+		return badRequest(err)
+	}
+	scoreScale := r.URL.Query().Get("score_scale")
+	if scoreScale != "" && scoreScale != ranking.ScoreScaleMinMax {
+		return badRequestf("score_scale must be %s", ranking.ScoreScaleMinMax)
+	}
+	metricName := r.URL.Query().Get("metric")
+	metric, err := ranking.MetricByName(metricName)
+	if err != nil {
+		return badRequest(err)
+	}
+	if explain && metricName != "" && metricName != ranking.DefaultMetric {
+		return badRequestf("explain is only supported with the %s metric", ranking.DefaultMetric)
+	}
+
+	constraints, err := ranking.ParseQuery(req.Query)
+	if err != nil {
+		return badRequest(err)
+	}
+	extra := []types.SearchConstraints{elevation}
+	if req.Constraints != nil {
+		extra = append(extra, *req.Constraints)
+	}
+	if mountain {
+		profile, _ := ranking.ProfileConstraints("mountain")
+		extra = append(extra, profile)
+	}
+	for _, c := range extra {
+		if constraints, err = ranking.MergeConstraints(constraints, c); err != nil {
+			return badRequest(err)
+		}
+	}
+
+	// An explicit vector replaces the one the constraints would target;
+	// the constraints still filter
+	query := ranking.QueryFromConstraints(constraints)
+	if req.FeatureVector != nil {
+		query = *req.FeatureVector
+	}
+	// Only the features the constraints or preset target count for metrics
+	// that compare targeted features alone; a feature vector targets all
+	targeted := slices.Collect(maps.Keys(constraints))
+	weightsByKey := req.Weights
+	if req.Preset != "" {
+		preset, _ := ranking.PresetByName(req.Preset)
+		weightsByKey = ranking.ApplyPreset(preset, &query, constraints, req.Weights)
+		targeted = slices.AppendSeq(targeted, maps.Keys(preset.Targets))
+	}
+	var constrained []bool
+	if req.FeatureVector == nil {
+		constrained = ranking.ConstrainedMask(targeted...)
+	}
+	weights, err := ranking.NormalizeWeights(weightsByKey)
+	if err != nil {
+		return badRequest(err)
+	}
+	avoid, err := ranking.AvoidVector(req.Avoid)
+	if err != nil {
+		return badRequest(err)
+	}
+
+	destinations, err := storeFromContext(r.Context()).List(r.Context())
+	if err != nil {
+		return fmt.Errorf("list destinations: %w", err)
+	}
+
+	// Rank and filter on the requested month's climate instead of the annual average
+	if month != 0 {
+		destinations = ranking.WithMonthTemp(destinations, month)
+	}
+
+	destinations = ranking.ApplyFilters(destinations, req.Filters)
+	destinations = ranking.FilterByConstraints(destinations, constraints)
+	destinations = ranking.FilterByGroup(destinations, req.Where)
+	destinations = ranking.FilterByTags(destinations, types.NormalizeTags(req.Tags), types.NormalizeTags(req.AnyTags))
+	destinations = ranking.ExcludeIDs(destinations, dedupe(req.Exclude))
+	if coastal {
+		destinations = ranking.FilterCoastal(destinations, h.coastalKm)
+	}
+	if tier != "" {
+		destinations = ranking.FilterByTier(destinations, tier, h.tiers)
+	}
+
+	scorer := ranking.Scorer{
+		Query:        query,
+		Weights:      weights,
+		Metric:       metric,
+		Constrained:  constrained,
+		Avoid:        avoid,
+		AvoidPenalty: h.avoidPenalty,
+		Workers:      h.scoringWorkers,
+	}
+	// Queries without vibe keywords are most likely place names
+	if !ranking.HasKeywords(req.Query) {
+		scorer.Text = req.Query
+		scorer.TextBlend = h.textBlend
+	}
+	// Asking for hot or cold places, by keyword or constraint, overrides the bias
+	if comfort && !ranking.ConstrainsTemperature(constraints, req.Where) {
+		scorer.Comfort = &h.comfort
+	}
+	_, span := tracer.Start(r.Context(), "ranking.Rank", trace.WithAttributes(attribute.Int("ranking.candidates", len(destinations))))
+	results := scorer.Rank(destinations)
+	span.End()
+	if strings.TrimSpace(req.Query) == "" && len(constraints) == 0 && req.Preset == "" && req.FeatureVector == nil {
+		ranking.OrderByPopularity(results, h.popularity)
+	}
+	if req.MinScore != nil {
+		results = ranking.FilterByScore(results, *req.MinScore)
+	}
+	if seed != nil {
+		ranking.ShuffleTies(results, *seed)
+	}
+	if collapse {
+		results = ranking.Collapse(results, h.collapseRadiusKm, h.collapseMinSimilarity, h.popularity)
+	}
+	if diversify {
+		results = ranking.Diversify(results, lambda)
+	}
+	// Scaled after the min_score cut so the whole result set, not a page, spans [0, 1]
+	if scoreScale == ranking.ScoreScaleMinMax {
+		ranking.ScaleMinMax(results)
+	}
+	pageResults := paginate(results, p)
+
+	logger.Info("search",
+		"query", req.Query,
+		"constraint_count", len(constraints),
+		"filters", activeFilters(req.Filters),
+		"result_count", len(results),
+		"elapsed_ms", time.Since(start).Milliseconds(),
+	)
+
+	if wantsGeoJSON(r) {
+		writeJSONAs(w, http.StatusOK, geoJSONContentType, newFeatureCollection(pageResults, len(results)))
+		return nil
+	}
+
+	views := make([]types.DestinationView, len(pageResults))
+	for i, res := range pageResults {
+		views[i] = newDestinationView(res.Destination, view)
+		views[i].Score = &res.Score
+		views[i].CollapsedCount = res.Collapsed
+		if explain {
+			views[i].ScoreBreakdown = h.redactKeys(scorer.Breakdown(res.Destination))
+		}
+		if summary {
+			views[i].Summary = scorer.Summary(res.Destination, h.redactedFeatures)
+		}
+	}
+	resp := types.SearchResponse{
+		Destinations: views,
+		Total:        len(results),
+		Meta:         p.meta(len(results)),
+	}
+	// Facets describe the same set Total counts, after min_score and collapse
+	if facets {
+		matched := make([]types.Destination, len(results))
+		for i, res := range results {
+			matched[i] = res.Destination
+		}
+		resp.Facets = h.redactFacets(ranking.Facets(matched))
+	}
+	writeFields(w, r, http.StatusOK, resp, fields)
+	return nil
+}
+
+// seedParam reads the optional seed query parameter that shuffles equally
+// scored results; nil keeps them in their stable order
+func seedParam(r *http.Request) (*uint64, error) {
+	raw := r.URL.Query().Get("seed")
+	if raw == "" {
+		return nil, nil
+	}
+	seed, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("seed must be a non-negative integer")
+	}
+	return &seed, nil
+}
+
+// tierParam reads the optional tier query parameter; empty means any tier
+func tierParam(r *http.Request) (types.PopularityTier, error) {
+	raw := r.URL.Query().Get("tier")
+	if raw == "" {
+		return "", nil
+	}
+	return ranking.ParseTier(raw)
+}
+
+// elevationConstraints reads the min_elevation and max_elevation query
+// parameters, in metres, as a constraint on the normalized elevation feature
+func elevationConstraints(r *http.Request) (types.SearchConstraints, error) {
+	scale := ranking.FeatureRanges["elevation"]
+	var fc types.FeatureConstraint
+	for _, p := range []struct {
+		name  string
+		bound **float64
+	}{{"min_elevation", &fc.Min}, {"max_elevation", &fc.Max}} {
+		raw := r.URL.Query().Get(p.name)
+		if raw == "" {
+			continue
+		}
+		m, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(m) || math.IsInf(m, 0) {
+			return nil, fmt.Errorf("%s must be a finite number of metres", p.name)
+		}
+		v := scale.Scale(m)
+		*p.bound = &v
+	}
+	if fc.Min == nil && fc.Max == nil {
+		return nil, nil
+	}
+	return types.SearchConstraints{"elevation": fc}, nil
+}
+
+// activeFilters names the geographic filters set on a request. Only the
+// filter kinds are logged, not their values, so user locations stay out of
+// the logs.
+func activeFilters(f *types.GeographicFilters) []string {
+	active := []string{}
+	if f == nil {
+		return active
+	}
+	if f.Continent != nil {
+		active = append(active, "continent")
+	}
+	if f.Country != nil {
+		active = append(active, "country")
+	}
+	if f.Region != nil {
+		active = append(active, "region")
+	}
+	if f.Near != nil {
+		active = append(active, "near")
+	}
+	if f.BoundingBox != nil {
+		active = append(active, "bbox")
+	}
+	return active
+}
@@ -0,0 +1,1060 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/ranking"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestSearchRejectsBadBodies(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	tests := []struct {
+		name string
+		body string
+		// wantMsg is a substring of the expected error message
+		wantMsg string
+	}{
+		{"empty body", "", "request body is required"},
+		{"malformed JSON", `{"query":`, "request body is not valid JSON"},
+		{"wrong type", `{"query":5}`, "field query must be of type string"},
+		{"min above max", `{"constraints":{"skiing_score":{"min":0.8,"max":0.2}}}`, "contradictory constraints on skiing_score"},
+		{"out of range", `{"constraints":{"nature_ratio":{"max":1.5}}}`, "constraint on nature_ratio must be between 0 and 1, got 1.5"},
+		{"empty where group", `{"where":{"any":[{}]}}`, "where: constraint group must set constraints, all, or any"},
+		{"avoid too strong", `{"query":"beach","avoid":{"tourism_density":2}}`, "avoid strength for tourism_density must be between 0 and 1"},
+		{"unknown features", `{"constraints":{"llama_density":{"min":0.5},"zebra_count":{"max":0.5}}}`, "llama_density, zebra_count"},
+		{"query too long", `{"query":"` + strings.Repeat("a", maxQueryLength+1) + `"}`, "query must be at most 500 characters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := decodeError(t, serve(router, http.MethodPost, "/api/search", tt.body), http.StatusBadRequest)
+			if got.Code != codeBadRequest || !strings.Contains(got.Message, tt.wantMsg) {
+				t.Errorf("error = %s %q, want %s containing %q", got.Code, got.Message, codeBadRequest, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestSearchFallsBackToNames(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"zermat", "zermatt"},
+		{"tokio", "tokyo"},
+		{"Lofotn", "lofoten"},
+		{"costa rica", "tamarindo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var got resultList
+			decodeData(t, serve(router, http.MethodPost, "/api/search", `{"query":"`+tt.query+`"}`), http.StatusOK, &got)
+			if len(got.Destinations) == 0 || got.Destinations[0].ID != tt.want {
+				t.Errorf("results for %q = %v, want %s first", tt.query, got.ids(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchMonth(t *testing.T) {
+	// Sydney is warm in December and Oslo in July; Lima has only an annual
+	// average, which applies to every month
+	dests := []types.Destination{
+		{ID: "sydney", Name: "Sydney", Active: true, Features: types.DestinationFeatures{
+			AvgTempC: 0.55, MonthlyTempC: [12]float64{0.72, 0.72, 0.68, 0.6, 0.5, 0.45, 0.42, 0.45, 0.52, 0.58, 0.64, 0.7},
+		}},
+		{ID: "oslo", Name: "Oslo", Active: true, Features: types.DestinationFeatures{
+			AvgTempC: 0.3, MonthlyTempC: [12]float64{0.05, 0.06, 0.15, 0.3, 0.45, 0.55, 0.6, 0.57, 0.45, 0.3, 0.15, 0.08},
+		}},
+		{ID: "lima", Name: "Lima", Active: true, Features: types.DestinationFeatures{AvgTempC: 0.6}},
+	}
+	router := newTestRouter(testConfig(t), dests)
+	tests := []struct {
+		month string
+		want  []string
+	}{
+		{"12", []string{"lima", "sydney"}},
+		{"7", []string{"lima", "oslo"}},
+		{"", []string{"lima"}},
+	}
+	for _, tt := range tests {
+		t.Run("month="+tt.month, func(t *testing.T) {
+			var got resultList
+			decodeData(t, serve(router, http.MethodPost, "/api/search?month="+tt.month, `{"query":"warm"}`), http.StatusOK, &got)
+			ids := got.ids()
+			slices.Sort(ids)
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("warm in month %s = %v, want %v", tt.month, ids, tt.want)
+			}
+		})
+	}
+
+	for _, month := range []string{"0", "13", "dec"} {
+		t.Run("month="+month, func(t *testing.T) {
+			decodeError(t, serve(router, http.MethodPost, "/api/search?month="+month, `{"query":"warm"}`), http.StatusBadRequest)
+		})
+	}
+}
+
+func TestSearchExplain(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	tests := []struct {
+		target string
+		want   bool
+	}{
+		{"/api/search", false},
+		{"/api/search?explain=false", false},
+		{"/api/search?explain=true", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			var got resultList
+			decodeData(t, serve(router, http.MethodPost, tt.target, `{"query":"ski"}`), http.StatusOK, &got)
+			for _, d := range got.Destinations {
+				if (d.ScoreBreakdown != nil) != tt.want {
+					t.Fatalf("%s breakdown = %v, want present %t", d.ID, d.ScoreBreakdown, tt.want)
+				}
+				if !tt.want {
+					continue
+				}
+				var sum float64
+				for _, v := range d.ScoreBreakdown {
+					sum += v
+				}
+				if math.Abs(sum-*d.Score) > 1e-9 {
+					t.Errorf("%s breakdown sums to %v, score is %v", d.ID, sum, *d.Score)
+				}
+			}
+		})
+	}
+
+	got := decodeError(t, serve(router, http.MethodPost, "/api/search?explain=true&metric=euclidean", `{"query":"ski"}`), http.StatusBadRequest)
+	if !strings.Contains(got.Message, "explain is only supported") {
+		t.Errorf("message = %q, want explain rejected for other metrics", got.Message)
+	}
+}
+
+// captureLogs routes the default logger to a buffer for the rest of the
+// test and returns a function decoding the records logged so far
+func captureLogs(t *testing.T) func() []map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return func() []map[string]any {
+		var records []map[string]any
+		dec := json.NewDecoder(&buf)
+		for dec.More() {
+			var rec map[string]any
+			if err := dec.Decode(&rec); err != nil {
+				t.Fatalf("decode log record: %v", err)
+			}
+			records = append(records, rec)
+		}
+		return records
+	}
+}
+
+func TestSearchLogsParameters(t *testing.T) {
+	logs := captureLogs(t)
+	router := newTestRouter(testConfig(t), testDestinations)
+	body := `{"query":"ski","filters":{"continent":"Europe"},"constraints":{"hiking_score":{"min":0.5}}}`
+	rec := serve(router, http.MethodPost, "/api/search", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; body: %s", rec.Code, rec.Body)
+	}
+
+	var search map[string]any
+	for _, r := range logs() {
+		if r["msg"] == "search" {
+			search = r
+		}
+	}
+	if search == nil {
+		t.Fatal("no search log record")
+	}
+	tests := []struct {
+		attr string
+		want any
+	}{
+		{"query", "ski"},
+		// ski bounds skiing_score and avg_temp_c, plus the explicit hiking_score
+		{"constraint_count", 3.0},
+		{"filters", []any{"continent"}},
+		{"result_count", 1.0},
+		{"request_id", rec.Header().Get("X-Request-Id")},
+	}
+	for _, tt := range tests {
+		if got := search[tt.attr]; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s = %#v, want %#v", tt.attr, got, tt.want)
+		}
+	}
+	if _, ok := search["elapsed_ms"]; !ok {
+		t.Error("no elapsed_ms attribute")
+	}
+	if _, ok := search["constraints"]; ok {
+		t.Error("request body logged")
+	}
+}
+
+func TestSearchExclude(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	tests := []struct {
+		name      string
+		exclude   string
+		limit     int
+		wantTotal int
+	}{
+		{"nothing excluded", `[]`, 2, 4},
+		{"one excluded", `["tokyo"]`, 2, 3},
+		{"duplicates count once", `["tokyo","tokyo","zermatt"]`, 1, 2},
+		{"unknown IDs ignored", `["atlantis","lofoten"]`, 10, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var excluded []string
+			if err := json.Unmarshal([]byte(tt.exclude), &excluded); err != nil {
+				t.Fatal(err)
+			}
+			body := fmt.Sprintf(`{"query":"","exclude":%s,"limit":%d}`, tt.exclude, tt.limit)
+			var got resultList
+			decodeData(t, serve(router, http.MethodPost, "/api/search", body), http.StatusOK, &got)
+			if got.Total != tt.wantTotal {
+				t.Errorf("total = %d, want %d", got.Total, tt.wantTotal)
+			}
+			if want := min(tt.limit, tt.wantTotal); len(got.Destinations) != want {
+				t.Errorf("page has %d results, want %d", len(got.Destinations), want)
+			}
+			for _, id := range got.ids() {
+				if slices.Contains(excluded, id) {
+					t.Errorf("excluded %s in results %v", id, got.ids())
+				}
+			}
+		})
+	}
+}
+
+func TestSearchTags(t *testing.T) {
+	dests := []types.Destination{
+		{ID: "kyoto", Name: "Kyoto", Active: true, Tags: []string{"unesco", "food", "temples"}},
+		{ID: "bali", Name: "Bali", Active: true, Tags: []string{"honeymoon", "beach", "temples"}},
+		{ID: "maldives", Name: "Maldives", Active: true, Tags: []string{"honeymoon", "beach"}},
+		{ID: "hanoi", Name: "Hanoi", Active: true, Tags: []string{"food", "budget"}},
+	}
+	router := newTestRouter(testConfig(t), dests)
+	tests := []struct {
+		name string
+		// body and query set the same filter on POST and GET /api/search
+		body, query string
+		want        []string
+	}{
+		{"all tags", `"tags":["honeymoon","temples"]`, "tags=honeymoon,temples", []string{"bali"}},
+		{"any tag", `"any_tags":["unesco","budget"]`, "any_tags=unesco,budget", []string{"hanoi", "kyoto"}},
+		{"all and any", `"tags":["food"],"any_tags":["budget","beach"]`, "tags=food&any_tags=budget,beach", []string{"hanoi"}},
+		{"normalized query tags", `"tags":[" Beach ","HONEYMOON"]`, "tags=+Beach+,HONEYMOON", []string{"bali", "maldives"}},
+		{"tag on no destination", `"tags":["ski"]`, "tags=ski", []string{}},
+		{"any tag on no destination", `"any_tags":["ski","surf"]`, "any_tags=ski,surf", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, rec := range []*httptest.ResponseRecorder{
+				serve(router, http.MethodPost, "/api/search", `{`+tt.body+`}`),
+				serve(router, http.MethodGet, "/api/search?"+tt.query, ""),
+			} {
+				var got resultList
+				decodeData(t, rec, http.StatusOK, &got)
+				ids := got.ids()
+				slices.Sort(ids)
+				if !slices.Equal(ids, tt.want) || got.Total != len(tt.want) {
+					t.Errorf("results = %v (total %d), want %v", ids, got.Total, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchMetric(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	for _, metric := range []string{"cosine", "euclidean"} {
+		t.Run(metric, func(t *testing.T) {
+			var got resultList
+			decodeData(t, serve(router, http.MethodPost, "/api/search?metric="+metric, `{"query":"ski"}`), http.StatusOK, &got)
+			if len(got.Destinations) == 0 || got.Destinations[0].ID != "zermatt" {
+				t.Errorf("%s ranks %v, want zermatt first", metric, got.ids())
+			}
+		})
+	}
+	got := decodeError(t, serve(router, http.MethodPost, "/api/search?metric=manhattan", `{"query":"ski"}`), http.StatusBadRequest)
+	if got.Message != "metric must be one of cosine, euclidean" {
+		t.Errorf("message = %q", got.Message)
+	}
+}
+
+func TestSearchFacets(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	body := `{"filters":{"continent":"Europe"},"limit":1}`
+
+	var got struct {
+		Facets []types.FeatureFacet `json:"facets"`
+	}
+	decodeData(t, serve(router, http.MethodPost, "/api/search?facets=true", body), http.StatusOK, &got)
+	// Zermatt and Lofoten both match, though the page holds one of them
+	i := slices.IndexFunc(got.Facets, func(f types.FeatureFacet) bool { return f.Key == "population" })
+	if i < 0 {
+		t.Fatalf("no population facet in %+v", got.Facets)
+	}
+	if f := got.Facets[i]; f.Min != 0.02 || f.Max != 0.05 || math.Abs(f.Mean-0.035) > 1e-9 {
+		t.Errorf("population facet = %+v, want min 0.02, max 0.05, mean 0.035", f)
+	}
+
+	// Only Tokyo clears the cutoff, so the facets describe it alone
+	decodeData(t, serve(router, http.MethodPost, "/api/search?facets=true", `{"query":"tokyo","min_score":0.5}`), http.StatusOK, &got)
+	i = slices.IndexFunc(got.Facets, func(f types.FeatureFacet) bool { return f.Key == "population" })
+	if i < 0 {
+		t.Fatalf("no population facet in %+v", got.Facets)
+	}
+	if f := got.Facets[i]; f.Min != 1 || f.Max != 1 || f.Mean != 1 {
+		t.Errorf("population facet with min_score = %+v, want Tokyo's 1 throughout", f)
+	}
+
+	rec := serve(router, http.MethodPost, "/api/search", body)
+	if strings.Contains(rec.Body.String(), `"facets"`) {
+		t.Errorf("facets included without facets=true: %s", rec.Body)
+	}
+}
+
+func TestSearchCoastal(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	var got struct {
+		Destinations []struct {
+			ID        string `json:"id"`
+			IsCoastal bool   `json:"is_coastal"`
+		} `json:"destinations"`
+	}
+	decodeData(t, serve(router, http.MethodPost, "/api/search", `{}`), http.StatusOK, &got)
+	if len(got.Destinations) != len(testDestinations) {
+		t.Fatalf("got %d destinations, want %d", len(got.Destinations), len(testDestinations))
+	}
+	// Zermatt is 300km inland; the rest sit within the 10km default
+	for _, d := range got.Destinations {
+		if want := d.ID != "zermatt"; d.IsCoastal != want {
+			t.Errorf("%s is_coastal = %t, want %t", d.ID, d.IsCoastal, want)
+		}
+	}
+
+	var coastal resultList
+	decodeData(t, serve(router, http.MethodPost, "/api/search?coastal=true", `{}`), http.StatusOK, &coastal)
+	if ids := coastal.ids(); slices.Contains(ids, "zermatt") || len(ids) != 3 {
+		t.Errorf("coastal=true returned %v, want the three coastal destinations", ids)
+	}
+
+	bad := decodeError(t, serve(router, http.MethodPost, "/api/search?coastal=maybe", `{}`), http.StatusBadRequest)
+	if bad.Code != codeBadRequest {
+		t.Errorf("code = %s, want %s", bad.Code, codeBadRequest)
+	}
+}
+
+func TestSearchWhere(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	// (coastal AND population above 0.5) OR wikipedia_pageviews above 0.5
+	body := `{"where":{"any":[
+		{"all":[{"constraints":{"coast_distance_km":{"max":0.02}}},{"constraints":{"population":{"min":0.5}}}]},
+		{"constraints":{"wikipedia_pageviews":{"min":0.5}}}
+	]}}`
+	var got resultList
+	decodeData(t, serve(router, http.MethodPost, "/api/search", body), http.StatusOK, &got)
+	ids := got.ids()
+	slices.Sort(ids)
+	if !slices.Equal(ids, []string{"tokyo", "zermatt"}) {
+		t.Errorf("where matched %v, want [tokyo zermatt]", ids)
+	}
+}
+
+func TestSearchDiversify(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	var got resultList
+	decodeData(t, serve(router, http.MethodPost, "/api/search?diversify=true&lambda=0.3", `{}`), http.StatusOK, &got)
+	if got.Total != len(testDestinations) || len(got.Destinations) != len(testDestinations) {
+		t.Errorf("diversify returned %v of %d, want every destination", got.ids(), got.Total)
+	}
+	tests := []struct{ query, wantMsg string }{
+		{"diversify=true&lambda=2", "lambda must be between 0 and 1, got 2"},
+		{"diversify=true&lambda=lots", "lambda must be a number"},
+		{"diversify=sometimes", "diversify"},
+	}
+	for _, tt := range tests {
+		bad := decodeError(t, serve(router, http.MethodPost, "/api/search?"+tt.query, `{}`), http.StatusBadRequest)
+		if !strings.Contains(bad.Message, tt.wantMsg) {
+			t.Errorf("%s: message = %q, want it to contain %q", tt.query, bad.Message, tt.wantMsg)
+		}
+	}
+}
+
+func TestSearchEmptyQueryOrdersByPopularity(t *testing.T) {
+	tests := []struct {
+		popularity string
+		want       []string
+	}{
+		{"", []string{"tokyo", "zermatt", "tamarindo", "lofoten"}},
+		{"population", []string{"tokyo", "tamarindo", "zermatt", "lofoten"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.popularity, func(t *testing.T) {
+			cfg := testConfig(t)
+			cfg.PopularityFeature = tt.popularity
+			router := newTestRouter(cfg, testDestinations)
+			// The order must be the same on every call
+			for range 3 {
+				var got resultList
+				decodeData(t, serve(router, http.MethodPost, "/api/search", `{"query":"  "}`), http.StatusOK, &got)
+				if ids := got.ids(); !slices.Equal(ids, tt.want) {
+					t.Fatalf("empty search order = %v, want %v", ids, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchElevation(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	// Zermatt sits at 2000m, Lofoten 500m, Tokyo and Tamarindo 50m
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"min_elevation=1500", []string{"zermatt"}},
+		{"max_elevation=100", []string{"tamarindo", "tokyo"}},
+		{"min_elevation=200&max_elevation=1000", []string{"lofoten"}},
+		{"min_elevation=3000", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var got resultList
+			decodeData(t, serve(router, http.MethodPost, "/api/search?"+tt.query, `{}`), http.StatusOK, &got)
+			ids := got.ids()
+			slices.Sort(ids)
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("matched %v, want %v", ids, tt.want)
+			}
+		})
+	}
+
+	for _, query := range []string{"min_elevation=high", "max_elevation=NaN", "min_elevation=Inf", "min_elevation=-Inf"} {
+		got := decodeError(t, serve(router, http.MethodPost, "/api/search?"+query, `{}`), http.StatusBadRequest)
+		if !strings.Contains(got.Message, "must be a finite number of metres") {
+			t.Errorf("%s: message = %q", query, got.Message)
+		}
+	}
+	if rec := serve(router, http.MethodPost, "/api/search?min_elevation=1000&max_elevation=500", `{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("min above max: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestSearchMountain(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	var got resultList
+	decodeData(t, serve(router, http.MethodPost, "/api/search?mountain=true", `{}`), http.StatusOK, &got)
+	if ids := got.ids(); !slices.Equal(ids, []string{"zermatt"}) {
+		t.Errorf("mountain=true matched %v, want [zermatt]", ids)
+	}
+	// It narrows alongside the body's own constraints
+	decodeData(t, serve(router, http.MethodPost, "/api/search?mountain=true", `{"constraints":{"skiing_score":{"max":0.5}}}`), http.StatusOK, &got)
+	if len(got.Destinations) != 0 {
+		t.Errorf("mountain=true with no skiing matched %v", got.ids())
+	}
+	if rec := serve(router, http.MethodPost, "/api/search?mountain=maybe", `{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("mountain=maybe: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestSearchComfort(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	// Tokyo is 16°C and Tamarindo 28°C; Zermatt and Lofoten are near freezing
+	var got resultList
+	decodeData(t, serve(router, http.MethodPost, "/api/search?comfort=true", `{}`), http.StatusOK, &got)
+	if ids := got.ids(); !slices.Equal(ids, []string{"tokyo", "tamarindo", "zermatt", "lofoten"}) {
+		t.Errorf("comfort=true order = %v, want temperate places first", ids)
+	}
+
+	// Asking for the cold, by keyword or constraint, turns the bias off
+	for _, body := range []string{`{"query":"cold"}`, `{"constraints":{"avg_temp_c":{"max":0.33}}}`} {
+		var plain, biased resultList
+		decodeData(t, serve(router, http.MethodPost, "/api/search", body), http.StatusOK, &plain)
+		decodeData(t, serve(router, http.MethodPost, "/api/search?comfort=true", body), http.StatusOK, &biased)
+		if len(biased.Destinations) == 0 || !reflect.DeepEqual(plain, biased) {
+			t.Errorf("%s: comfort=true gave %v, want the unbiased %v", body, biased.ids(), plain.ids())
+		}
+	}
+	if rec := serve(router, http.MethodPost, "/api/search?comfort=sometimes", `{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("comfort=sometimes: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestSearchMinScore(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	// The name match puts Tokyo at 0.7 and the rest below 0.2
+	tests := []struct {
+		minScore  string
+		wantTotal int
+	}{
+		{"0", 4},
+		{"0.12", 2},
+		{"0.5", 1},
+		// Nothing reaches a perfect score, so the list is empty
+		{"1", 0},
+	}
+	prev := len(testDestinations)
+	for _, tt := range tests {
+		t.Run(tt.minScore, func(t *testing.T) {
+			var got resultList
+			decodeData(t, serve(router, http.MethodPost, "/api/search", `{"query":"tokyo","min_score":`+tt.minScore+`}`), http.StatusOK, &got)
+			if got.Total != tt.wantTotal || len(got.Destinations) != tt.wantTotal {
+				t.Errorf("total %d with %d results, want %d", got.Total, len(got.Destinations), tt.wantTotal)
+			}
+			if got.Total > prev {
+				t.Errorf("raising min_score grew the results from %d to %d", prev, got.Total)
+			}
+			prev = got.Total
+			if got.Destinations == nil {
+				t.Error("destinations is null, want []")
+			}
+		})
+	}
+
+	for _, bad := range []string{"-0.1", "1.5"} {
+		got := decodeError(t, serve(router, http.MethodPost, "/api/search", `{"query":"tokyo","min_score":`+bad+`}`), http.StatusBadRequest)
+		if !strings.Contains(got.Message, "min_score must be between 0 and 1, got "+bad) {
+			t.Errorf("min_score %s: message = %q", bad, got.Message)
+		}
+	}
+}
+
+func TestSearchCollapse(t *testing.T) {
+	// Zermatt's region, listed 11km away with the same features
+	region := testDestinations[1]
+	region.ID, region.Name, region.Type = "matterhorn-region", "Matterhorn Region", types.Region
+	region.Location.Lat += 0.1
+	region.Features.WikipediaPageviews = 0.2
+	dests := append(slices.Clone(testDestinations), region)
+	router := newTestRouter(testConfig(t), dests)
+
+	// Collapsing is off by default
+	var plain resultList
+	decodeData(t, serve(router, http.MethodPost, "/api/search", `{"query":"ski"}`), http.StatusOK, &plain)
+	if ids := plain.ids(); !slices.Contains(ids, "matterhorn-region") || !slices.Contains(ids, "zermatt") {
+		t.Fatalf("without collapse got %v, want both listings", ids)
+	}
+
+	var got struct {
+		Destinations []struct {
+			ID             string `json:"id"`
+			CollapsedCount int    `json:"collapsed_count"`
+		} `json:"destinations"`
+		Total int `json:"total"`
+	}
+	decodeData(t, serve(router, http.MethodPost, "/api/search?collapse=true", `{"query":"ski"}`), http.StatusOK, &got)
+	if got.Total != plain.Total-1 {
+		t.Errorf("total = %d, want %d", got.Total, plain.Total-1)
+	}
+	collapsed := map[string]int{}
+	for _, d := range got.Destinations {
+		collapsed[d.ID] = d.CollapsedCount
+	}
+	if _, ok := collapsed["matterhorn-region"]; ok {
+		t.Error("the less popular duplicate survived collapsing")
+	}
+	if n, ok := collapsed["zermatt"]; !ok || n != 1 {
+		t.Errorf("zermatt collapsed_count = %d (listed %t), want 1", n, ok)
+	}
+}
+
+func TestSearchPreset(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	tests := []struct {
+		name, body string
+		want       []string
+	}{
+		// Each preset ranks the destination with its vibe first
+		{"adventure", `{"preset":"adventure"}`, []string{"lofoten", "zermatt", "tamarindo", "tokyo"}},
+		{"relaxation", `{"preset":"relaxation"}`, []string{"tamarindo", "lofoten", "zermatt", "tokyo"}},
+		{"culture", `{"preset":"culture"}`, []string{"tokyo", "zermatt", "tamarindo", "lofoten"}},
+		{"party", `{"preset":"Party"}`, []string{"tokyo", "tamarindo", "zermatt", "lofoten"}},
+		{
+			"combined with a constraint",
+			`{"preset":"party","constraints":{"avg_temp_c":{"min":0.6}}}`,
+			[]string{"tamarindo"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got resultList
+			decodeData(t, serve(router, http.MethodPost, "/api/search", tt.body), http.StatusOK, &got)
+			if !slices.Equal(got.ids(), tt.want) {
+				t.Errorf("results = %v, want %v", got.ids(), tt.want)
+			}
+		})
+	}
+
+	var get resultList
+	decodeData(t, serve(router, http.MethodGet, "/api/search?preset=adventure", ""), http.StatusOK, &get)
+	if get.ids()[0] != "lofoten" {
+		t.Errorf("GET preset=adventure ranks %v, want lofoten first", get.ids())
+	}
+
+	got := decodeError(t, serve(router, http.MethodPost, "/api/search", `{"preset":"nap"}`), http.StatusBadRequest)
+	if !strings.Contains(got.Message, `unknown preset "nap"`) {
+		t.Errorf("message = %q, want the unknown preset named", got.Message)
+	}
+}
+
+func TestSearchSeedShufflesTies(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	search := func(target string) []string {
+		var got resultList
+		decodeData(t, serve(router, http.MethodPost, target, `{}`), http.StatusOK, &got)
+		return got.ids()
+	}
+
+	// An empty search scores everything 0, so it's all one tie group
+	stable := search("/api/search")
+	if again := search("/api/search"); !slices.Equal(again, stable) {
+		t.Errorf("unseeded order changed from %v to %v", stable, again)
+	}
+	var varied bool
+	for seed := range 20 {
+		target := fmt.Sprintf("/api/search?seed=%d", seed)
+		got := search(target)
+		if again := search(target); !slices.Equal(again, got) {
+			t.Errorf("seed %d: %v then %v, want the same order", seed, got, again)
+		}
+		varied = varied || !slices.Equal(got, stable)
+	}
+	if !varied {
+		t.Error("no seed changed the order of tied results")
+	}
+
+	// Distinct scores keep their order under any seed
+	var scored resultList
+	decodeData(t, serve(router, http.MethodPost, "/api/search?seed=3", `{"query":"tokyo"}`), http.StatusOK, &scored)
+	if want := []string{"tokyo", "tamarindo", "lofoten", "zermatt"}; !slices.Equal(scored.ids(), want) {
+		t.Errorf("seeded scored search = %v, want %v", scored.ids(), want)
+	}
+
+	for _, seed := range []string{"-1", "lucky"} {
+		got := decodeError(t, serve(router, http.MethodPost, "/api/search?seed="+seed, `{}`), http.StatusBadRequest)
+		if got.Message != "seed must be a non-negative integer" {
+			t.Errorf("seed=%s: message = %q", seed, got.Message)
+		}
+	}
+}
+
+func TestSearchClampConstraints(t *testing.T) {
+	body := `{"constraints":{"nature_ratio":{"min":-1,"max":0.7}}}`
+	tests := []struct {
+		name, method, target, body string
+		clamp                      bool
+		wantStatus                 int
+		want                       []string
+	}{
+		{"rejected by default", http.MethodPost, "/api/search", body, false, http.StatusBadRequest, nil},
+		{"clamped", http.MethodPost, "/api/search", body, true, http.StatusOK, []string{"tamarindo", "tokyo"}},
+		{"clamped in a where group", http.MethodPost, "/api/search", `{"where":{"any":[{"constraints":{"nature_ratio":{"min":-1,"max":0.7}}}]}}`, true, http.StatusOK, []string{"tamarindo", "tokyo"}},
+		{"query string rejected by default", http.MethodGet, "/api/search?nature_ratio.min=2", "", false, http.StatusBadRequest, nil},
+		// Clamped to 1, which nothing reaches
+		{"query string clamped", http.MethodGet, "/api/search?nature_ratio.min=2", "", true, http.StatusOK, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLogs(t)
+			cfg := testConfig(t)
+			cfg.ClampConstraints = tt.clamp
+			rec := serve(newTestRouter(cfg, testDestinations), tt.method, tt.target, tt.body)
+			if tt.wantStatus == http.StatusBadRequest {
+				got := decodeError(t, rec, http.StatusBadRequest)
+				if !strings.Contains(got.Message, "nature_ratio") {
+					t.Errorf("message = %q, want nature_ratio named", got.Message)
+				}
+				return
+			}
+			var got resultList
+			decodeData(t, rec, tt.wantStatus, &got)
+			if ids := slices.Sorted(slices.Values(got.ids())); !slices.Equal(ids, tt.want) {
+				t.Errorf("results = %v, want %v", ids, tt.want)
+			}
+			var warned bool
+			for _, r := range logs() {
+				if r["msg"] == "clamped out-of-range search constraints" && r["level"] == "WARN" {
+					warned = reflect.DeepEqual(r["features"], []any{"nature_ratio"})
+				}
+			}
+			if !warned {
+				t.Error("no warning naming the clamped nature_ratio constraint")
+			}
+		})
+	}
+
+	cfg := testConfig(t)
+	cfg.ClampConstraints = true
+	var diagnosis types.DiagnoseResponse
+	decodeData(t, serve(newTestRouter(cfg, testDestinations), http.MethodPost, "/api/search/diagnose", body), http.StatusOK, &diagnosis)
+	if diagnosis.Matching != 2 {
+		t.Errorf("diagnose matching = %d, want the 2 the clamped constraint allows", diagnosis.Matching)
+	}
+}
+
+func TestSearchTier(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	var all struct {
+		Destinations []struct {
+			ID   string               `json:"id"`
+			Tier types.PopularityTier `json:"tier"`
+		} `json:"destinations"`
+	}
+	decodeData(t, serve(router, http.MethodPost, "/api/search", `{}`), http.StatusOK, &all)
+	want := map[string]types.PopularityTier{
+		"lofoten": types.TierHiddenGem, "tamarindo": types.TierPopular,
+		"zermatt": types.TierPopular, "tokyo": types.TierIconic,
+	}
+	for _, d := range all.Destinations {
+		if d.Tier != want[d.ID] {
+			t.Errorf("%s tier = %q, want %q", d.ID, d.Tier, want[d.ID])
+		}
+	}
+
+	tests := []struct {
+		method, target string
+		want           []string
+	}{
+		{http.MethodPost, "/api/search?tier=hidden_gem", []string{"lofoten"}},
+		{http.MethodPost, "/api/search?tier=iconic", []string{"tokyo"}},
+		{http.MethodGet, "/api/destinations?tier=hidden_gem", []string{"lofoten"}},
+		{http.MethodGet, "/api/destinations?tier=popular&sort=name", []string{"tamarindo", "zermatt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			body := ""
+			if tt.method == http.MethodPost {
+				body = `{}`
+			}
+			var got resultList
+			decodeData(t, serve(router, tt.method, tt.target, body), http.StatusOK, &got)
+			if !slices.Equal(got.ids(), tt.want) {
+				t.Errorf("results = %v, want %v", got.ids(), tt.want)
+			}
+		})
+	}
+
+	got := decodeError(t, serve(router, http.MethodGet, "/api/destinations?tier=hidden+gem", ""), http.StatusBadRequest)
+	if !strings.Contains(got.Message, "tier must be one of") {
+		t.Errorf("message = %q, want the valid tiers listed", got.Message)
+	}
+
+	cfg := testConfig(t)
+	cfg.TierHiddenGemBelow = 0.5
+	var gems resultList
+	decodeData(t, serve(newTestRouter(cfg, testDestinations), http.MethodGet, "/api/destinations?tier=hidden_gem&sort=name", ""), http.StatusOK, &gems)
+	if want := []string{"lofoten", "tamarindo"}; !slices.Equal(gems.ids(), want) {
+		t.Errorf("with a 0.5 threshold hidden gems = %v, want %v", gems.ids(), want)
+	}
+}
+
+func TestSearchFeatureVector(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	// vectorBody searches with the features of dest as the query vector
+	vectorBody := func(dest types.Destination, extra string) string {
+		vec, _ := json.Marshal(dest.Features)
+		return `{"feature_vector":` + string(vec) + extra + `}`
+	}
+	expected := func(dests []types.Destination, query types.DestinationFeatures) []string {
+		results := ranking.Scorer{Query: query}.Rank(dests)
+		ids := make([]string, len(results))
+		for i, res := range results {
+			ids[i] = res.Destination.ID
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name, body string
+		want       []string
+	}{
+		// The source ranks first and the rest by similarity to its vector
+		{"tamarindo's vector", vectorBody(testDestinations[0], ""), expected(testDestinations, testDestinations[0].Features)},
+		{"lofoten's vector", vectorBody(testDestinations[3], ""), expected(testDestinations, testDestinations[3].Features)},
+		{"with filters", vectorBody(testDestinations[2], `,"filters":{"continent":"Europe"}`), expected([]types.Destination{testDestinations[1], testDestinations[3]}, testDestinations[2].Features)},
+		{"with constraints", vectorBody(testDestinations[0], `,"constraints":{"skiing_score":{"min":0.2}}`), expected([]types.Destination{testDestinations[1], testDestinations[3]}, testDestinations[0].Features)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got resultList
+			decodeData(t, serve(router, http.MethodPost, "/api/search", tt.body), http.StatusOK, &got)
+			if !slices.Equal(got.ids(), tt.want) {
+				t.Errorf("results = %v, want %v", got.ids(), tt.want)
+			}
+			for i := 1; i < len(got.Destinations); i++ {
+				if *got.Destinations[i].Score > *got.Destinations[i-1].Score {
+					t.Errorf("result %d scores above the one before it", i)
+				}
+			}
+		})
+	}
+	if first := tests[0].want[0]; first != "tamarindo" {
+		t.Errorf("tamarindo's own vector ranks %s first", first)
+	}
+
+	rejects := []struct {
+		name, body, wantErr string
+	}{
+		{"above the scale", `{"feature_vector":{"nature_ratio":1.5}}`, "feature_vector nature_ratio must be between 0 and 1"},
+		{"below the scale", `{"feature_vector":{"avg_temp_c":-0.2}}`, "feature_vector avg_temp_c must be between 0 and 1"},
+		{"with a query", `{"query":"ski","feature_vector":{"skiing_score":1}}`, "feature_vector can't be combined with query or preset"},
+		{"with a preset", `{"preset":"party","feature_vector":{"skiing_score":1}}`, "feature_vector can't be combined with query or preset"},
+	}
+	for _, tt := range rejects {
+		t.Run(tt.name, func(t *testing.T) {
+			got := decodeError(t, serve(router, http.MethodPost, "/api/search", tt.body), http.StatusBadRequest)
+			if !strings.Contains(got.Message, tt.wantErr) {
+				t.Errorf("message = %q, want %q", got.Message, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSearchRejectsUnknownFields(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	tests := []struct {
+		name, target, body string
+		// wantErr is the expected message, "" for a 200
+		wantErr string
+	}{
+		{"misspelled field", "/api/search", `{"query":"ski","constrains":{"hiking_score":{"min":0.5}}}`, `unknown field "constrains"`},
+		{"misspelled nested field", "/api/search", `{"constraints":{"hiking_score":{"minimum":0.5}}}`, `unknown field "minimum"`},
+		{"misspelled filter", "/api/search", `{"filters":{"contnent":"Europe"}}`, `unknown field "contnent"`},
+		{"diagnose too", "/api/search/diagnose", `{"constrains":{}}`, `unknown field "constrains"`},
+		{"optional field spelled right", "/api/search", `{"query":"ski","min_score":0.1}`, ""},
+		{"optional fields left out", "/api/search", `{}`, ""},
+		{"every field can be null", "/api/search", `{"query":null,"constraints":null,"weights":null,"filters":null,"where":null}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(router, http.MethodPost, tt.target, tt.body)
+			if tt.wantErr == "" {
+				if rec.Code != http.StatusOK {
+					t.Errorf("status = %d, want 200; body: %s", rec.Code, rec.Body)
+				}
+				return
+			}
+			if got := decodeError(t, rec, http.StatusBadRequest); got.Message != tt.wantErr {
+				t.Errorf("message = %q, want %q", got.Message, tt.wantErr)
+			}
+		})
+	}
+
+	// Only search bodies are strict
+	if rec := serve(router, http.MethodPost, "/api/destinations/batch", `{"ids":["tokyo"],"expand":true}`); rec.Code != http.StatusOK {
+		t.Errorf("batch with an extra field = %d, want 200; body: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestSearchComplexityCap(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.MaxQueryComplexity = 5
+	router := newTestRouter(cfg, testDestinations)
+	tests := []struct {
+		name, method, target, body string
+		// wantOK is whether the search is within the cap
+		wantOK bool
+	}{
+		{
+			"just under the cap", http.MethodPost, "/api/search",
+			`{"constraints":{"hiking_score":{"min":0.1}},"weights":{"hiking_score":2,"nature_ratio":2},"avoid":{"nightlife_density":1}}`,
+			true,
+		},
+		{
+			"at the cap", http.MethodPost, "/api/search",
+			`{"constraints":{"hiking_score":{"min":0.1}},"weights":{"hiking_score":2,"nature_ratio":2},"avoid":{"nightlife_density":1,"tourism_density":1}}`,
+			true,
+		},
+		{
+			"just over the cap", http.MethodPost, "/api/search",
+			`{"constraints":{"hiking_score":{"min":0.1}},"weights":{"hiking_score":2,"nature_ratio":2},"avoid":{"nightlife_density":1,"tourism_density":1},"where":{"constraints":{}}}`,
+			false,
+		},
+		{
+			"over the cap in groups", http.MethodPost, "/api/search",
+			`{"where":{"any":[{"constraints":{"hiking_score":{"min":0.1}}},{"constraints":{"skiing_score":{"min":0.1}}},{"constraints":{}}]}}`,
+			false,
+		},
+		{
+			"over the cap in a query string", http.MethodGet,
+			"/api/search?hiking_score.min=0.1&skiing_score.min=0.1&elevation.max=0.9&hiking_score.weight=2&nature_ratio.weight=2&nightlife_density.avoid=1",
+			"", false,
+		},
+		{
+			"diagnose is capped too", http.MethodPost, "/api/search/diagnose",
+			`{"constraints":{"hiking_score":{"min":0.1},"skiing_score":{"min":0.1},"elevation":{"max":0.9},"nature_ratio":{"min":0.1},"avg_temp_c":{"min":0.1},"population":{"max":1}}}`,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(router, tt.method, tt.target, tt.body)
+			if tt.wantOK {
+				if rec.Code != http.StatusOK {
+					t.Errorf("status = %d, want 200; body: %s", rec.Code, rec.Body)
+				}
+				return
+			}
+			got := decodeError(t, rec, http.StatusBadRequest)
+			if !strings.Contains(got.Message, "search is too complex") || !strings.Contains(got.Message, "at most 5 allowed") {
+				t.Errorf("message = %q, want the cap named", got.Message)
+			}
+		})
+	}
+}
+
+func TestSearchSummary(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	tests := []struct {
+		target, body string
+		// want maps result IDs to their summaries; nil wants none at all
+		want map[string]string
+	}{
+		{"/api/search", `{"query":"beach party"}`, nil},
+		{"/api/search?summary=false", `{"query":"beach party"}`, nil},
+		{"/api/search?summary=true", `{"query":"beach party"}`, map[string]string{"tamarindo": "Coastal, water sports, lively nightlife"}},
+		{"/api/search?summary=true", `{"query":"ski"}`, map[string]string{"zermatt": "Cool, great skiing"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target+" "+tt.body, func(t *testing.T) {
+			var got resultList
+			decodeData(t, serve(router, http.MethodPost, tt.target, tt.body), http.StatusOK, &got)
+			for _, d := range got.Destinations {
+				if want := tt.want[d.ID]; d.Summary != want {
+					t.Errorf("%s summary = %q, want %q", d.ID, d.Summary, want)
+				}
+			}
+		})
+	}
+
+	decodeError(t, serve(router, http.MethodPost, "/api/search?summary=maybe", `{"query":"ski"}`), http.StatusBadRequest)
+}
+
+func TestSearchFeatureAliases(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.FeatureAliases = map[string]string{"powder": "skiing_score"}
+	router := newTestRouter(cfg, testDestinations)
+	tests := []struct {
+		name, method, target, body string
+		// canonical is the same search with feature keys only
+		canonical string
+	}{
+		{
+			"constraints", http.MethodPost, "/api/search",
+			`{"constraints":{"hiking":{"min":0.5},"altitude":{"max":0.5}}}`,
+			`{"constraints":{"hiking_score":{"min":0.5},"elevation":{"max":0.5}}}`,
+		},
+		{
+			"weights and avoid", http.MethodPost, "/api/search",
+			`{"constraints":{"warmth":{"min":0.2}},"weights":{"warmth":3},"avoid":{"crowds":1}}`,
+			`{"constraints":{"avg_temp_c":{"min":0.2}},"weights":{"avg_temp_c":3},"avoid":{"tourism_density":1}}`,
+		},
+		{
+			"where groups", http.MethodPost, "/api/search",
+			`{"where":{"any":[{"constraints":{"skiing":{"min":0.5}}},{"constraints":{"water_sports":{"min":0.5}}}]}}`,
+			`{"where":{"any":[{"constraints":{"skiing_score":{"min":0.5}}},{"constraints":{"water_sports_score":{"min":0.5}}}]}}`,
+		},
+		{
+			"configured alias", http.MethodPost, "/api/search",
+			`{"constraints":{"powder":{"min":0.5}}}`,
+			`{"constraints":{"skiing_score":{"min":0.5}}}`,
+		},
+		{
+			"query string", http.MethodGet, "/api/search?nightlife.min=0.5&warmth.weight=2", "",
+			`{"constraints":{"nightlife_density":{"min":0.5}},"weights":{"avg_temp_c":2}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got, want resultList
+			decodeData(t, serve(router, tt.method, tt.target, tt.body), http.StatusOK, &got)
+			decodeData(t, serve(router, http.MethodPost, "/api/search", tt.canonical), http.StatusOK, &want)
+			if len(want.Destinations) == 0 {
+				t.Fatal("canonical search matched nothing, so it can't tell the aliases apart")
+			}
+			if !slices.Equal(got.ids(), want.ids()) {
+				t.Errorf("results = %v, want %v as with feature keys", got.ids(), want.ids())
+			}
+		})
+	}
+}
+
+func TestSearchFeatureAliasesRejects(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	tests := []struct {
+		name, target, body, wantMsg string
+	}{
+		{"unknown constraint", "/api/search", `{"constraints":{"vibes":{"min":0.5}}}`, "constraints: unknown features: vibes (see GET /api/features for keys and aliases)"},
+		{"unknown weight", "/api/search", `{"constraints":{"hiking":{"min":0.5}},"weights":{"llamas":2}}`, "weights: unknown features: llamas"},
+		{"unknown avoid term", "/api/search", `{"avoid":{"vibes":1}}`, "avoid: unknown features: vibes"},
+		{"unknown in a group", "/api/search", `{"where":{"all":[{"constraints":{"vibes":{"min":0.5}}}]}}`, "where: unknown features: vibes"},
+		{"alias and key together", "/api/search", `{"weights":{"warmth":1,"avg_temp_c":2}}`, "weights: avg_temp_c and warmth both name feature avg_temp_c"},
+		{"diagnose", "/api/search/diagnose", `{"constraints":{"vibes":{"min":0.5}}}`, "constraints: unknown features: vibes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := decodeError(t, serve(router, http.MethodPost, tt.target, tt.body), http.StatusBadRequest)
+			if !strings.Contains(got.Message, tt.wantMsg) {
+				t.Errorf("message = %q, want it to contain %q", got.Message, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestSearchScoreScale(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	const body = `{"preset":"adventure"}`
+	var raw, scaled resultList
+	decodeData(t, serve(router, http.MethodPost, "/api/search", body), http.StatusOK, &raw)
+	decodeData(t, serve(router, http.MethodPost, "/api/search?score_scale=minmax", body), http.StatusOK, &scaled)
+
+	if !slices.Equal(scaled.ids(), raw.ids()) {
+		t.Fatalf("scaled order = %v, want the unscaled %v", scaled.ids(), raw.ids())
+	}
+	n := len(scaled.Destinations)
+	if n < 2 {
+		t.Fatalf("got %d results, want several to scale", n)
+	}
+	if top, bottom := *scaled.Destinations[0].Score, *scaled.Destinations[n-1].Score; top != 1 || bottom != 0 {
+		t.Errorf("scaled scores run %g to %g, want 1 to 0", top, bottom)
+	}
+	for i := 1; i < n; i++ {
+		if *scaled.Destinations[i].Score > *scaled.Destinations[i-1].Score {
+			t.Errorf("scaled scores out of order at %d", i)
+		}
+	}
+
+	// The whole result set is scaled, so a later page doesn't start at 1
+	var page resultList
+	decodeData(t, serve(router, http.MethodPost, "/api/search?score_scale=minmax", `{"preset":"adventure","offset":1}`), http.StatusOK, &page)
+	if got := *page.Destinations[0].Score; got != *scaled.Destinations[1].Score {
+		t.Errorf("second page starts at %g, want %g as on the first", got, *scaled.Destinations[1].Score)
+	}
+
+	got := decodeError(t, serve(router, http.MethodPost, "/api/search?score_scale=zscore", body), http.StatusBadRequest)
+	if got.Message != "score_scale must be minmax" {
+		t.Errorf("message = %q", got.Message)
+	}
+}
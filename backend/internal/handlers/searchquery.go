@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// parseSearchQuery translates the query string of GET /api/search into the
+// SearchRequest a POST would send:
+//
+//   - q is the free-text query and preset a vibe preset
+//   - <feature>.min and <feature>.max constrain a feature, <feature>.weight
+//     weighs it, and <feature>.avoid avoids it, all on the normalized scale
+//   - tags, any_tags, and exclude are comma-separated lists
+//   - limit, offset, and min_score page and cut off the results
+//   - continent, country, region, near, radius_km, and bbox filter by
+//     geography as on the other GET endpoints
+//
+// Parameters that shape the response, such as explain or month, are read
+// by the search itself just as they are for POST.
+func parseSearchQuery(r *http.Request) (types.SearchRequest, error) {
+	q := r.URL.Query()
+	req := types.SearchRequest{
+		Query:   q.Get("q"),
+		Preset:  q.Get("preset"),
+		Tags:    commaList(q.Get("tags")),
+		AnyTags: commaList(q.Get("any_tags")),
+		Exclude: commaList(q.Get("exclude")),
+	}
+
+	var err error
+	if req.Limit, err = intParam(r, "limit"); err != nil {
+		return types.SearchRequest{}, err
+	}
+	if req.Offset, err = intParam(r, "offset"); err != nil {
+		return types.SearchRequest{}, err
+	}
+	if q.Has("min_score") {
+		minScore, err := floatParam(r, "min_score", 0)
+		if err != nil {
+			return types.SearchRequest{}, err
+		}
+		req.MinScore = &minScore
+	}
+	if req.Filters, err = parseGeoFilters(r); err != nil {
+		return types.SearchRequest{}, err
+	}
+
+	// Sorted so the first malformed parameter reported is stable
+	names := make([]string, 0, len(q))
+	for name := range q {
+		if strings.Contains(name, ".") {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	constraints := types.SearchConstraints{}
+	for _, name := range names {
+		feature, op, _ := strings.Cut(name, ".")
+		v, err := strconv.ParseFloat(q.Get(name), 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return types.SearchRequest{}, fmt.Errorf("%s must be a finite number, got %q", name, q.Get(name))
+		}
+		switch op {
+		case "min":
+			fc := constraints[feature]
+			fc.Min = &v
+			constraints[feature] = fc
+		case "max":
+			fc := constraints[feature]
+			fc.Max = &v
+			constraints[feature] = fc
+		case "weight":
+			if req.Weights == nil {
+				req.Weights = map[string]float64{}
+			}
+			req.Weights[feature] = v
+		case "avoid":
+			if req.Avoid == nil {
+				req.Avoid = map[string]float64{}
+			}
+			req.Avoid[feature] = v
+		default:
+			return types.SearchRequest{}, fmt.Errorf("unknown search parameter %s: feature parameters end in .min, .max, .weight, or .avoid", name)
+		}
+	}
+	if len(constraints) > 0 {
+		req.Constraints = &constraints
+	}
+	return req, nil
+}
+
+// commaList splits a comma-separated parameter, dropping empty entries; an
+// empty parameter yields nil
+func commaList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	europe := types.Europe
+	tests := []struct {
+		name, query string
+		want        types.SearchRequest
+	}{
+		{"empty", "", types.SearchRequest{}},
+		{"representative",
+			"q=alpine+villages&skiing_score.min=0.7&skiing_score.max=1&population.weight=2&tourism_density.avoid=0.5" +
+				"&continent=Europe&tags=ski,+hiking,&exclude=zermatt&limit=5&offset=10&min_score=0.3",
+			types.SearchRequest{
+				Query: "alpine villages",
+				Constraints: &types.SearchConstraints{
+					"skiing_score": {Min: new(0.7), Max: new(1.0)},
+				},
+				Filters:  &types.GeographicFilters{Continent: &europe},
+				Weights:  map[string]float64{"population": 2},
+				Avoid:    map[string]float64{"tourism_density": 0.5},
+				Tags:     []string{"ski", "hiking"},
+				Exclude:  []string{"zermatt"},
+				MinScore: new(0.3),
+				Limit:    5,
+				Offset:   10,
+			}},
+		{"preset and any tags", "preset=beach-bum&any_tags=surf,diving",
+			types.SearchRequest{Preset: "beach-bum", AnyTags: []string{"surf", "diving"}}},
+		{"radius", "near=46.02,7.75&radius_km=50",
+			types.SearchRequest{Filters: &types.GeographicFilters{Near: &types.Location{Lat: 46.02, Lon: 7.75}, RadiusKm: 50}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSearchQuery(httptest.NewRequest(http.MethodGet, "/api/search?"+tt.query, nil))
+			if err != nil {
+				t.Fatalf("parseSearchQuery: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				gotJSON, _ := json.Marshal(got)
+				wantJSON, _ := json.Marshal(tt.want)
+				t.Errorf("parseSearchQuery = %s, want %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
+
+func TestParseSearchQueryRejects(t *testing.T) {
+	tests := []struct {
+		name, query string
+		// wantErr is a substring of the expected error
+		wantErr string
+	}{
+		{"not a number", "skiing_score.min=high", `skiing_score.min must be a finite number, got "high"`},
+		{"NaN", "skiing_score.max=NaN", `skiing_score.max must be a finite number, got "NaN"`},
+		{"infinite weight", "population.weight=Inf", `population.weight must be a finite number, got "Inf"`},
+		{"unknown suffix", "skiing_score.exactly=0.5", "unknown search parameter skiing_score.exactly"},
+		{"bad limit", "limit=lots", "limit"},
+		{"bad min_score", "min_score=high", "min_score must be a number"},
+		{"bad radius", "near=46,7&radius_km=far", "radius_km must be a number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseSearchQuery(httptest.NewRequest(http.MethodGet, "/api/search?"+tt.query, nil))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSearchQueryMatchesPost(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	var get, post resultList
+	decodeData(t, serve(router, http.MethodGet, "/api/search?skiing_score.min=0.5&continent=Europe&explain=true", ""), http.StatusOK, &get)
+	decodeData(t, serve(router, http.MethodPost, "/api/search?explain=true",
+		`{"constraints":{"skiing_score":{"min":0.5}},"filters":{"continent":"Europe"}}`), http.StatusOK, &post)
+	if !reflect.DeepEqual(get, post) || len(get.Destinations) == 0 {
+		t.Errorf("GET returned %v, POST %v; want the same results", get.ids(), post.ids())
+	}
+
+	for _, query := range []string{"skiing_score.min=high", "min_score=NaN", "skiing_score.min=1.5"} {
+		if rec := serve(router, http.MethodGet, "/api/search?"+query, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/simonryrie/otherwhere/internal/ranking"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// ValidateSearch checks a search body the way Search does without running
+// it. A valid body is echoed back normalized; an invalid one gets a 400
+// listing every problem rather than just the first, so query builders can
+// flag each field at once.
+func (h *Handler) ValidateSearch(w http.ResponseWriter, r *http.Request) error {
+	var req types.SearchRequest
+	if err := decodeStrictJSON(r, &req); err != nil {
+		return err
+	}
+	errs := h.aliasErrors(&req)
+	h.clampSearchRequest(r, &req)
+	errs = append(errs, searchRequestErrors(req, h.maxComplexity)...)
+	errs = append(errs, pageErrors(req.Limit, req.Offset)...)
+	if len(errs) > 0 {
+		return invalid(errs)
+	}
+
+	// searchRequestErrors has already parsed and merged these
+	constraints, _ := ranking.ParseQuery(req.Query)
+	if req.Constraints != nil {
+		constraints, _ = ranking.MergeConstraints(constraints, *req.Constraints)
+	}
+	req.Tags = types.NormalizeTags(req.Tags)
+	req.AnyTags = types.NormalizeTags(req.AnyTags)
+	req.Exclude = dedupe(req.Exclude)
+
+	writeData(w, r, http.StatusOK, types.SearchValidation{Request: req, Constraints: constraints})
+	return nil
+}
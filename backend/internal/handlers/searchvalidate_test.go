@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/ranking"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestValidateSearch(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	var got types.SearchValidation
+	body := `{"query":"warm beach","constraints":{"warmth":{"min":0.2}},"tags":["Surf"," surf "],"exclude":["tokyo","tokyo"]}`
+	decodeData(t, serve(router, http.MethodPost, "/api/search/validate", body), http.StatusOK, &got)
+
+	if got.Request.Constraints == nil || len(*got.Request.Constraints) != 1 || (*got.Request.Constraints)["avg_temp_c"].Min == nil {
+		t.Errorf("request constraints = %v, want warmth resolved to avg_temp_c", got.Request.Constraints)
+	}
+	if !slices.Equal(got.Request.Tags, []string{"surf"}) {
+		t.Errorf("tags = %q, want normalized [surf]", got.Request.Tags)
+	}
+	if !slices.Equal(got.Request.Exclude, []string{"tokyo"}) {
+		t.Errorf("exclude = %q, want the repeat dropped", got.Request.Exclude)
+	}
+	// The query's keywords are merged in, warm outranking the looser bound
+	keys := slices.Sorted(maps.Keys(got.Constraints))
+	if want := []string{"avg_temp_c", "coast_distance_km", "water_sports_score"}; !slices.Equal(keys, want) {
+		t.Errorf("merged constraints on %q, want %q", keys, want)
+	}
+	if warm := got.Constraints["avg_temp_c"]; warm.Min == nil || *warm.Min != 0.58 {
+		t.Errorf("avg_temp_c min = %v, want the keyword's 0.58", warm.Min)
+	}
+}
+
+func TestValidateSearchErrors(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	// zero sets every feature's weight to 0
+	zero := map[string]float64{}
+	for _, feat := range ranking.Features {
+		zero[feat.Key] = 0
+	}
+	zeroWeights, err := json.Marshal(map[string]any{"weights": zero})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, body string
+		want       []string
+	}{
+		{
+			"one of each",
+			`{"min_score":2,"preset":"chill","avoid":{"nightlife_density":3},"filters":{"near":{"lat":95,"lon":0},"radius_km":10}}`,
+			[]string{
+				"min_score must be between 0 and 1, got 2",
+				`unknown preset "chill", must be one of adventure, relaxation, culture, party`,
+				"avoid strength for nightlife_density must be between 0 and 1, got 3",
+				"near: lat must be between -90 and 90, got 95",
+			},
+		},
+		{
+			"every bad constraint and weight",
+			`{"constraints":{"skiing_score":{"min":0.8,"max":0.2},"hiking_score":{"min":2}},"weights":{"nature_ratio":-1,"hiking_score":-2}}`,
+			[]string{
+				"hiking_score",
+				"skiing_score",
+				"weight for hiking_score must not be negative",
+				"weight for nature_ratio must not be negative",
+			},
+		},
+		{
+			"unknown names in several places",
+			`{"constraints":{"vibes":{"min":0.1}},"weights":{"llamas":1},"avoid":{"crowds":0.5,"noise":1}}`,
+			[]string{
+				"constraints: unknown features: vibes",
+				"weights: unknown features: llamas",
+				"avoid: unknown features: noise",
+			},
+		},
+		{
+			"query conflicts with a constraint",
+			`{"query":"ski","constraints":{"skiing_score":{"max":0.1}},"min_score":-1}`,
+			[]string{
+				"min_score must be between 0 and 1, got -1",
+				"contradictory constraints on skiing_score: min 0.6 is greater than max 0.1",
+			},
+		},
+		{"weights all zero", string(zeroWeights), []string{"weights must not all be zero"}},
+		{
+			"negative page",
+			`{"min_score":2,"limit":-5,"offset":-1}`,
+			[]string{
+				"min_score must be between 0 and 1, got 2",
+				"limit must not be negative",
+				"offset must not be negative",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := decodeError(t, serve(router, http.MethodPost, "/api/search/validate", tt.body), http.StatusBadRequest)
+			if len(got.Errors) != len(tt.want) {
+				t.Fatalf("errors = %q, want %d of them", got.Errors, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(got.Errors[i], want) {
+					t.Errorf("error %d = %q, want it to mention %q", i, got.Errors[i], want)
+				}
+			}
+			if got.Message != strings.Join(got.Errors, "; ") {
+				t.Errorf("message = %q, want the errors joined", got.Message)
+			}
+		})
+	}
+
+	// Validate must reject whatever search would
+	for _, body := range []string{`{"limit":-5}`, `{"offset":-1}`} {
+		searchErr := decodeError(t, serve(router, http.MethodPost, "/api/search", body), http.StatusBadRequest)
+		validateErr := decodeError(t, serve(router, http.MethodPost, "/api/search/validate", body), http.StatusBadRequest)
+		if validateErr.Message != searchErr.Message {
+			t.Errorf("%s: validate says %q, search says %q", body, validateErr.Message, searchErr.Message)
+		}
+	}
+
+	t.Run("search reports the first", func(t *testing.T) {
+		got := decodeError(t, serve(router, http.MethodPost, "/api/search", `{"min_score":2,"preset":"chill"}`), http.StatusBadRequest)
+		if got.Message != "min_score must be between 0 and 1, got 2" || got.Errors != nil {
+			t.Errorf("search error = %q with %q, want only the first problem", got.Message, got.Errors)
+		}
+	})
+}
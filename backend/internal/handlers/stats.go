@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/simonryrie/otherwhere/internal/ranking"
+)
+
+// GetContinentStats reports each continent's destination count and feature
+// averages for overview dashboards
+func (h *Handler) GetContinentStats(w http.ResponseWriter, r *http.Request) error {
+	destinations, err := storeFromContext(r.Context()).List(r.Context())
+	if err != nil {
+		return fmt.Errorf("list destinations: %w", err)
+	}
+
+	stats := ranking.ContinentStats(destinations)
+	for i := range stats {
+		stats[i].FeatureMeans = h.redactKeys(stats[i].FeatureMeans)
+	}
+	writeData(w, r, http.StatusOK, stats)
+	return nil
+}
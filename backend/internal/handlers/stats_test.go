@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestGetContinentStats(t *testing.T) {
+	router := newTestRouter(testConfig(t), testDestinations)
+	var got []types.ContinentStats
+	decodeData(t, serve(router, http.MethodGet, "/api/stats/continents", ""), http.StatusOK, &got)
+
+	tests := []struct {
+		continent types.Continent
+		count     int
+		means     map[string]float64
+	}{
+		{types.Asia, 1, map[string]float64{"avg_temp_c": 0.52, "population": 1}},
+		{types.Europe, 2, map[string]float64{"avg_temp_c": 0.275, "skiing_score": 0.625, "hiking_score": 0.875}},
+		{types.NorthAmerica, 1, map[string]float64{"avg_temp_c": 0.72, "water_sports_score": 0.9}},
+	}
+	if len(got) != len(tests) {
+		t.Fatalf("got %d continents, want %d: %+v", len(got), len(tests), got)
+	}
+	for i, tt := range tests {
+		if got[i].Continent != tt.continent || got[i].Count != tt.count {
+			t.Errorf("continent %d = %s with %d, want %s with %d", i, got[i].Continent, got[i].Count, tt.continent, tt.count)
+		}
+		for key, want := range tt.means {
+			if math.Abs(got[i].FeatureMeans[key]-want) > 1e-9 {
+				t.Errorf("%s %s mean = %v, want %v", tt.continent, key, got[i].FeatureMeans[key], want)
+			}
+		}
+	}
+
+	rec := serve(router, http.MethodGet, "/api/stats/continents", "")
+	if rec.Header().Get("ETag") == "" {
+		t.Error("stats response has no ETag")
+	}
+}
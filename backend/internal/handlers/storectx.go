@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/simonryrie/otherwhere/internal/store"
+)
+
+// storeKey is the request context key holding the destination store
+type storeKey struct{}
+
+// WithStore makes s the destination store for every request it wraps.
+// Handlers read the store from the request context rather than holding it,
+// so the store serving a request can later be chosen per request.
+func WithStore(s store.DestinationStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), storeKey{}, s)))
+		})
+	}
+}
+
+// ActiveOnly narrows the request's store to active destinations, so public
+// responses and their totals leave inactive ones out. Requests with
+// include_inactive=true and the admin token see every destination; without
+// the token, or when none is configured, they get a 401.
+func ActiveOnly(adminToken string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			includeInactive, err := boolParam(r, "include_inactive")
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, codeBadRequest, err.Error())
+				return
+			}
+			if includeInactive {
+				if !hasToken(r, adminToken) {
+					unauthorized(w, r)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+			s := store.NewActiveStore(storeFromContext(r.Context()))
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), storeKey{}, store.DestinationStore(s))))
+		})
+	}
+}
+
+// storeFromContext returns the store WithStore attached to ctx. A missing
+// store means the route wasn't wrapped in WithStore, which is a wiring bug,
+// so it panics rather than failing the request quietly.
+func storeFromContext(ctx context.Context) store.DestinationStore {
+	s, ok := ctx.Value(storeKey{}).(store.DestinationStore)
+	if !ok {
+		panic("handlers: no destination store in request context; wrap the route in WithStore")
+	}
+	return s
+}
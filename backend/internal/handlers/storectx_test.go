@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/simonryrie/otherwhere/internal/store"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestHandlersReadStoreFromContext(t *testing.T) {
+	// One Handler serves two datasets, each chosen by its route's middleware
+	h := New(testConfig(t))
+	r := chi.NewRouter()
+	r.With(WithStore(store.NewMemoryStore(testDestinations[:1]))).Get("/a/destinations", Handle(h.GetDestinations))
+	r.With(WithStore(store.NewMemoryStore(testDestinations[1:]))).Get("/b/destinations", Handle(h.GetDestinations))
+
+	tests := []struct {
+		path      string
+		wantTotal int
+	}{
+		{"/a/destinations", 1},
+		{"/b/destinations", len(testDestinations) - 1},
+	}
+	for _, tt := range tests {
+		var got resultList
+		decodeData(t, serve(r, http.MethodGet, tt.path, ""), http.StatusOK, &got)
+		if got.Total != tt.wantTotal {
+			t.Errorf("%s: total = %d, want %d", tt.path, got.Total, tt.wantTotal)
+		}
+	}
+}
+
+func TestStoreFromContext(t *testing.T) {
+	s := store.NewMemoryStore([]types.Destination{})
+	ctx := context.WithValue(context.Background(), storeKey{}, store.DestinationStore(s))
+	if got := storeFromContext(ctx); got != s {
+		t.Errorf("storeFromContext = %v, want the attached store", got)
+	}
+}
+
+func TestStoreFromContextPanicsWhenMissing(t *testing.T) {
+	defer func() {
+		msg, _ := recover().(string)
+		if !strings.Contains(msg, "no destination store in request context") {
+			t.Errorf("recovered %q, want a panic naming the missing store", msg)
+		}
+	}()
+	storeFromContext(context.Background())
+	t.Error("storeFromContext returned without a store")
+}
+
+func TestHandlerWithoutStore(t *testing.T) {
+	// Handle turns the wiring bug's panic into a logged 500
+	h := New(testConfig(t))
+	r := chi.NewRouter()
+	r.Get("/destinations", Handle(h.GetDestinations))
+	got := decodeError(t, serve(r, http.MethodGet, "/destinations", ""), http.StatusInternalServerError)
+	if got.Code != codeInternal {
+		t.Errorf("code = %s, want %s", got.Code, codeInternal)
+	}
+}
+
+// activeRequest sends a GET for target, with the admin token when set
+func activeRequest(h http.Handler, target, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestActiveOnly(t *testing.T) {
+	dests := slices.Clone(testDestinations)
+	dests[2].Active = false // tokyo
+	cfg := testConfig(t)
+	cfg.AdminToken = "secret"
+	router := newTestRouter(cfg, dests)
+
+	tests := []struct {
+		name, target, token string
+		wantTotal           int
+		wantTokyo           bool
+	}{
+		{"public list", "/api/destinations", "", 3, false},
+		{"public search", "/api/search?avg_temp_c.min=0", "", 3, false},
+		{"admin list", "/api/destinations?include_inactive=true", "secret", 4, true},
+		{"admin search", "/api/search?avg_temp_c.min=0&include_inactive=true", "secret", 4, true},
+		{"token alone isn't enough", "/api/destinations", "secret", 3, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got resultList
+			decodeData(t, activeRequest(router, tt.target, tt.token), http.StatusOK, &got)
+			if got.Total != tt.wantTotal || len(got.Destinations) != tt.wantTotal {
+				t.Errorf("total = %d with %d destinations, want %d", got.Total, len(got.Destinations), tt.wantTotal)
+			}
+			if slices.Contains(got.ids(), "tokyo") != tt.wantTokyo {
+				t.Errorf("results = %v, want tokyo included %t", got.ids(), tt.wantTokyo)
+			}
+		})
+	}
+
+	t.Run("get", func(t *testing.T) {
+		decodeError(t, activeRequest(router, "/api/destinations/tokyo", ""), http.StatusNotFound)
+		var got types.DestinationView
+		decodeData(t, activeRequest(router, "/api/destinations/tokyo?include_inactive=true", "secret"), http.StatusOK, &got)
+		if got.ID != "tokyo" || got.Active {
+			t.Errorf("admin get = %s, active %t; want the inactive tokyo", got.ID, got.Active)
+		}
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		var got struct {
+			NotFound []string `json:"not_found"`
+		}
+		decodeData(t, serve(router, http.MethodPost, "/api/destinations/batch", `{"ids":["tokyo","zermatt"]}`), http.StatusOK, &got)
+		if !slices.Equal(got.NotFound, []string{"tokyo"}) {
+			t.Errorf("not_found = %v, want the inactive tokyo", got.NotFound)
+		}
+	})
+}
+
+func TestActiveOnlyRejects(t *testing.T) {
+	dests := slices.Clone(testDestinations)
+	dests[2].Active = false
+	cfg := testConfig(t)
+	cfg.AdminToken = "secret"
+	withToken := newTestRouter(cfg, dests)
+	cfg.AdminToken = ""
+	withoutToken := newTestRouter(cfg, dests)
+
+	tests := []struct {
+		name   string
+		router http.Handler
+		target string
+		token  string
+		status int
+	}{
+		{"no token", withToken, "/api/destinations?include_inactive=true", "", http.StatusUnauthorized},
+		{"wrong token", withToken, "/api/destinations?include_inactive=true", "guess", http.StatusUnauthorized},
+		{"none configured", withoutToken, "/api/destinations?include_inactive=true", "secret", http.StatusUnauthorized},
+		{"not a boolean", withToken, "/api/destinations?include_inactive=all", "secret", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := activeRequest(tt.router, tt.target, tt.token)
+			decodeError(t, rec, tt.status)
+			if tt.status == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") != "Bearer" {
+				t.Errorf("WWW-Authenticate = %q, want Bearer", rec.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
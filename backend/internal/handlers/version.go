@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/simonryrie/otherwhere/internal/store"
+)
+
+// DatasetVersionHeader carries the version of the dataset a response was
+// built from
+const DatasetVersionHeader = "X-Dataset-Version"
+
+// DatasetVersion sends the store's dataset version in X-Dataset-Version, so
+// clients caching destinations can tell when to refetch. The version is read
+// when the response starts rather than when the request arrives, so it
+// matches the data a handler listed even if a reload or cache refresh
+// happened in between. Stores that don't report a version send no header.
+func DatasetVersion(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&versionWriter{ResponseWriter: w, ctx: r.Context()}, r)
+	})
+}
+
+// datasetVersion reports the version of the store in ctx, or "" when it has
+// none
+func datasetVersion(ctx context.Context) string {
+	if v, ok := storeFromContext(ctx).(store.Versioner); ok {
+		return v.Version()
+	}
+	return ""
+}
+
+// versionWriter adds the dataset version header just before the response
+// starts
+type versionWriter struct {
+	http.ResponseWriter
+	ctx     context.Context
+	started bool
+}
+
+// start sets the header the first time the response is written to
+func (v *versionWriter) start() {
+	if v.started {
+		return
+	}
+	v.started = true
+	if version := datasetVersion(v.ctx); version != "" {
+		v.Header().Set(DatasetVersionHeader, version)
+	}
+}
+
+func (v *versionWriter) WriteHeader(status int) {
+	v.start()
+	v.ResponseWriter.WriteHeader(status)
+}
+
+func (v *versionWriter) Write(p []byte) (int, error) {
+	v.start()
+	return v.ResponseWriter.Write(p)
+}
+
+// FlushError starts the response, so streaming handlers get the header too
+func (v *versionWriter) FlushError() error {
+	v.start()
+	return http.NewResponseController(v.ResponseWriter).Flush()
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/store"
+)
+
+// healthVersion returns the dataset_version /health reports
+func healthVersion(t *testing.T, h http.Handler) string {
+	t.Helper()
+	var got healthResponse
+	rec := serve(h, http.MethodGet, "/health", "")
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("health = %d, %v; body: %s", rec.Code, err, rec.Body)
+	}
+	return got.DatasetVersion
+}
+
+func TestDatasetVersionHeader(t *testing.T) {
+	router, path := newReloadableRouter(t)
+	requests := []struct {
+		method, target, body string
+	}{
+		{http.MethodGet, "/api/destinations", ""},
+		{http.MethodGet, "/api/destinations/tokyo", ""},
+		{http.MethodGet, "/api/destinations?format=ndjson", ""},
+		{http.MethodPost, "/api/search", `{"query":"ski"}`},
+		// Errors come from the same dataset too
+		{http.MethodGet, "/api/destinations/atlantis", ""},
+	}
+	// versions sends every request twice and checks they all agree
+	versions := func() string {
+		t.Helper()
+		want := serve(router, http.MethodGet, "/api/destinations", "").Header().Get(DatasetVersionHeader)
+		if want == "" {
+			t.Fatal("no X-Dataset-Version header")
+		}
+		for range 2 {
+			for _, req := range requests {
+				if got := serve(router, req.method, req.target, req.body).Header().Get(DatasetVersionHeader); got != want {
+					t.Errorf("%s %s: version = %q, want %q", req.method, req.target, got, want)
+				}
+			}
+		}
+		if got := healthVersion(t, router); got != want {
+			t.Errorf("/health dataset_version = %q, want %q", got, want)
+		}
+		return want
+	}
+
+	v1 := versions()
+	if rec := adminRequest(router, "/api/admin/reload", "secret"); rec.Code != http.StatusOK {
+		t.Fatalf("reload status = %d; body: %s", rec.Code, rec.Body)
+	}
+	if v := versions(); v != v1 {
+		t.Errorf("reloading the same data changed the version from %q to %q", v1, v)
+	}
+
+	data, _ := json.Marshal(testDestinations[:2])
+	os.WriteFile(path, data, 0o600)
+	if rec := adminRequest(router, "/api/admin/reload", "secret"); rec.Code != http.StatusOK {
+		t.Fatalf("reload status = %d; body: %s", rec.Code, rec.Body)
+	}
+	if v := versions(); v == v1 {
+		t.Errorf("version still %q after reloading new data", v)
+	}
+}
+
+func TestDatasetVersionWithoutVersioner(t *testing.T) {
+	// Embedding only the interface hides MemoryStore's Version
+	s := struct{ store.DestinationStore }{store.NewMemoryStore(testDestinations)}
+	router := newTestRouterWithStore(testConfig(t), s)
+	rec := serve(router, http.MethodGet, "/api/destinations", "")
+	if _, ok := rec.Header()[DatasetVersionHeader]; ok || rec.Code != http.StatusOK {
+		t.Errorf("status %d with %s %q, want 200 and no header", rec.Code, DatasetVersionHeader, rec.Header().Get(DatasetVersionHeader))
+	}
+	if got := healthVersion(t, router); got != "" {
+		t.Errorf("/health dataset_version = %q, want none", got)
+	}
+}
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// newTestRouter serves a destination route and the metrics endpoint with
+// m's middleware, the way cmd/server mounts them
+func newTestRouter(m *Metrics) http.Handler {
+	r := chi.NewRouter()
+	r.Use(m.Middleware)
+	r.Method(http.MethodGet, Path, m.Handler())
+	r.Get("/api/destinations/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if chi.URLParam(r, "id") == "atlantis" {
+			http.NotFound(w, r)
+		}
+	})
+	return r
+}
+
+// scrape returns the metrics endpoint's exposition text
+func scrape(t *testing.T, h http.Handler) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("scrape status = %d", rec.Code)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	return string(body)
+}
+
+func TestMiddlewareCountsRequests(t *testing.T) {
+	h := newTestRouter(New())
+	for _, path := range []string{"/api/destinations/tokyo", "/api/destinations/kyoto", "/api/destinations/atlantis"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	body := scrape(t, h)
+
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"labelled by route pattern", `otherwhere_http_requests_total{method="GET",route="/api/destinations/{id}",status="200"} 2`},
+		{"counted by status", `otherwhere_http_requests_total{method="GET",route="/api/destinations/{id}",status="404"} 1`},
+		{"latency recorded", `otherwhere_http_request_duration_seconds_count{method="GET",route="/api/destinations/{id}"} 3`},
+	}
+	for _, tt := range tests {
+		if !strings.Contains(body, tt.line) {
+			t.Errorf("%s: missing %s", tt.name, tt.line)
+		}
+	}
+	if strings.Contains(body, "tokyo") {
+		t.Error("raw path used as a label")
+	}
+}
+
+func TestMetricsEndpointExcludesItself(t *testing.T) {
+	h := newTestRouter(New())
+	scrape(t, h)
+	if body := scrape(t, h); strings.Contains(body, `route="/metrics"`) {
+		t.Error("scrapes of /metrics are counted")
+	}
+}
@@ -0,0 +1,407 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/simonryrie/otherwhere/internal/ranking"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// Path is where the OpenAPI document is served
+const Path = "/openapi.json"
+
+// Error mirrors the structured error envelope written by the handlers
+type Error struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		// Errors lists each problem when a request has several
+		Errors []string `json:"errors,omitempty"`
+	} `json:"error"`
+	Meta *types.ResponseMeta `json:"meta,omitempty"`
+}
+
+// Spec returns the OpenAPI 3 document describing the API. Paths are
+// maintained by hand alongside the routes in cmd/server; component schemas
+// are generated from the Go types so they stay in sync with the JSON.
+var Spec = sync.OnceValue(func() map[string]any {
+	s := newSchemas()
+	errRef := s.ref(Error{})
+
+	// POST and GET /api/search share their response options and results
+	searchParams := []any{
+		unitsParam(),
+		fieldsParam(),
+		queryParam("month", "Rank on this month's temperature (1-12)", integer()),
+		queryParam("explain", "Include a per-feature score breakdown", boolean()),
+		queryParam("summary", "Include a short summary of up to three reasons each result matched", boolean()),
+		queryParam("facets", "Include each feature's min, max, and mean across all matches", boolean()),
+		queryParam("diversify", "Re-rank the top 50 results for variety (maximal marginal relevance)", boolean()),
+		queryParam("lambda", "Relevance share when diversifying, 0-1 (default 0.7; lower is more varied)", number()),
+		queryParam("min_elevation", "Lowest elevation in metres", number()),
+		queryParam("max_elevation", "Highest elevation in metres", number()),
+		queryParam("mountain", "Apply the mountain profile (as the query keyword does)", boolean()),
+		queryParam("coastal", "Keep only destinations flagged is_coastal", boolean()),
+		tierParam(),
+		queryParam("collapse", "Merge near-duplicate results, keeping the more popular one (see collapsed_count)", boolean()),
+		queryParam("comfort", "Penalize climates outside the comfortable band unless the query or constraints bound avg_temp_c", boolean()),
+		queryParam("seed", "Shuffle equally scored results reproducibly with this seed", integer()),
+		queryParam("score_scale", "minmax rescales scores so the best result scores 1 and the worst 0, keeping the order", map[string]any{"type": "string", "enum": []string{"minmax"}}),
+		queryParam("metric", "Similarity metric: cosine (default) or euclidean", map[string]any{"type": "string", "enum": []string{"cosine", "euclidean"}}),
+		queryParam("format", "Set to geojson for a GeoJSON FeatureCollection", str()),
+	}
+	searchOK := map[string]any{
+		"description": "Ranked destinations",
+		"content": map[string]any{
+			"application/json":     map[string]any{"schema": s.data(s.ref(types.SearchResponse{}))},
+			"application/geo+json": map[string]any{"schema": s.ref(types.FeatureCollection{})},
+		},
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       "Otherwhere API",
+			"version":     "0.1.0",
+			"description": "Vibe-based travel destination discovery.",
+		},
+		"paths": map[string]any{
+			"/health": map[string]any{
+				"get": operation("Readiness check including store connectivity", nil, nil, map[string]any{
+					"200": jsonResponse("Server and store are up, with the dataset_version API responses send in X-Dataset-Version", healthSchema()),
+					"503": jsonResponse("The store is unreachable", statusSchema()),
+				}),
+			},
+			"/livez": map[string]any{
+				"get": operation("Liveness check", nil, nil, map[string]any{
+					"200": jsonResponse("Server is up", statusSchema()),
+				}),
+			},
+			"/metrics": map[string]any{
+				"get": operation("Prometheus metrics", nil, nil, map[string]any{
+					"200": map[string]any{"description": "Metrics in the Prometheus text exposition format"},
+				}),
+			},
+			"/api/destinations": map[string]any{
+				"get": operation("List destinations", append([]any{
+					queryParam("limit", "Page size (default 20, max 100, unless configured otherwise)", integer()),
+					queryParam("offset", "Number of results to skip", integer()),
+					queryParam("sort", "name, population, or temp; prefix with - for descending", str()),
+					unitsParam(),
+					fieldsParam(),
+					tierParam(),
+					includeInactiveParam(),
+					queryParam("format", "ndjson streams every destination, one per line, ignoring limit and offset", map[string]any{"type": "string", "enum": []string{"ndjson"}}),
+				}, geoFilterParams()...), nil, map[string]any{
+					"200": map[string]any{
+						"description": "A page of destinations, or all of them as NDJSON",
+						"content": map[string]any{
+							"application/json":     map[string]any{"schema": s.data(s.ref(types.DestinationsResponse{}))},
+							"application/x-ndjson": map[string]any{"schema": s.ref(types.DestinationView{})},
+						},
+					},
+					"304": notModified(),
+					"400": jsonResponse("Invalid query parameters", errRef),
+					"401": jsonResponse("include_inactive without the admin token", errRef),
+				}),
+			},
+			"/api/destinations.csv": map[string]any{
+				"get": operation("Export destinations as CSV", append([]any{
+					queryParam("sort", "name, population, or temp; prefix with - for descending", str()),
+					tierParam(),
+				}, geoFilterParams()...), nil, map[string]any{
+					"200": map[string]any{
+						"description": "A header row, then one row per destination with normalized feature values",
+						"content":     map[string]any{"text/csv": map[string]any{"schema": str()}},
+					},
+					"400": jsonResponse("Invalid query parameters", errRef),
+				}),
+			},
+			"/api/destinations/{id}": map[string]any{
+				"get": operation("Get a destination", []any{idParam(), unitsParam(), fieldsParam(), includeInactiveParam()}, nil, map[string]any{
+					"200": s.dataResponse("The destination", s.ref(types.DestinationView{})),
+					"304": notModified(),
+					"400": jsonResponse("Invalid ID", errRef),
+					"401": jsonResponse("include_inactive without the admin token", errRef),
+					"404": jsonResponse("Destination not found", errRef),
+				}),
+			},
+			"/api/destinations/batch": map[string]any{
+				"post": operation("Get several destinations by ID", []any{unitsParam(), fieldsParam()}, s.ref(types.BatchRequest{}), map[string]any{
+					"200": s.dataResponse("The found destinations in request order and the IDs that were not found", s.ref(types.BatchResponse{})),
+					"400": jsonResponse("No IDs or more than 100", errRef),
+					"413": jsonResponse("Request body too large", errRef),
+					"415": jsonResponse("Request body is not application/json", errRef),
+				}),
+			},
+			"/api/destinations/random": map[string]any{
+				"get": operation("Get a random destination", append(geoFilterParams(), unitsParam(), fieldsParam()), nil, map[string]any{
+					"200": s.dataResponse("A randomly picked destination", s.ref(types.DestinationView{})),
+					"400": jsonResponse("Invalid filters", errRef),
+					"404": jsonResponse("No destinations match the filters", errRef),
+				}),
+			},
+			"/api/destinations/nearest": map[string]any{
+				"get": operation("Find the destinations closest to a point", []any{
+					map[string]any{"name": "lat", "in": "query", "required": true, "schema": number()},
+					map[string]any{"name": "lon", "in": "query", "required": true, "schema": number()},
+					queryParam("limit", "Number of results (default 5, max 100)", integer()),
+					unitsParam(),
+					fieldsParam(),
+				}, nil, map[string]any{
+					"200": s.dataResponse("Destinations nearest first, each with distance_km", s.ref(types.DestinationsResponse{})),
+					"400": jsonResponse("Missing or out-of-range coordinates", errRef),
+				}),
+			},
+			"/api/destinations/{id}/similar": map[string]any{
+				"get": operation("Find destinations with a similar vibe", []any{
+					idParam(),
+					queryParam("limit", "Number of results (default 5, max 100, unless configured otherwise)", integer()),
+					unitsParam(),
+					fieldsParam(),
+				}, nil, map[string]any{
+					"200": s.dataResponse("The most similar destinations", s.ref(types.DestinationsResponse{})),
+					"404": jsonResponse("Destination not found", errRef),
+				}),
+			},
+			"/api/destinations/{id}/best-month": map[string]any{
+				"get": operation("Suggest the best month to visit", []any{
+					idParam(),
+					unitsParam(),
+					queryParam("comfort_min", "Lower bound of the comfortable temperature range (default 18 °C)", number()),
+					queryParam("comfort_max", "Upper bound of the comfortable temperature range (default 26 °C)", number()),
+				}, nil, map[string]any{
+					"200": s.dataResponse("The suggested month and why", s.ref(types.BestMonthResponse{})),
+					"400": jsonResponse("Invalid comfort range", errRef),
+					"404": jsonResponse("Destination not found", errRef),
+					"422": jsonResponse("The destination has no monthly temperature data", errRef),
+				}),
+			},
+			"/api/features": map[string]any{
+				"get": operation("Describe searchable features", nil, nil, map[string]any{
+					"200": s.dataResponse("Feature metadata in vector order", s.schemaFor(reflect.TypeFor[[]types.FeatureMetadata]())),
+				}),
+			},
+			"/api/presets": map[string]any{
+				"get": operation("List vibe presets for search", nil, nil, map[string]any{
+					"200": s.dataResponse("Presets with their feature targets and weights", s.schemaFor(reflect.TypeFor[[]types.Preset]())),
+				}),
+			},
+			"/api/filters": map[string]any{
+				"get": operation("List available geographic filters", nil, nil, map[string]any{
+					"200": s.dataResponse("Continents, countries, and regions", s.ref(types.FilterOptions{})),
+				}),
+			},
+			"/api/stats/continents": map[string]any{
+				"get": operation("Summarize destinations by continent", nil, nil, map[string]any{
+					"200": s.dataResponse("Destination counts and feature means per continent, by name", s.schemaFor(reflect.TypeFor[[]types.ContinentStats]())),
+					"304": notModified(),
+				}),
+			},
+			"/api/search": map[string]any{
+				"post": operation("Search destinations by vibe", searchParams, s.ref(types.SearchRequest{}), map[string]any{
+					"200": searchOK,
+					"400": jsonResponse("Invalid search request", errRef),
+					"413": jsonResponse("Request body too large", errRef),
+					"415": jsonResponse("Request body is not application/json", errRef),
+				}),
+				"get": operation("Search destinations by vibe with the request as query parameters",
+					append(slices.Clone(searchParams), searchQueryParams()...), nil, map[string]any{
+						"200": searchOK,
+						"400": jsonResponse("Invalid search parameters", errRef),
+					}),
+			},
+			"/api/search/diagnose": map[string]any{
+				"post": operation("Explain which constraints limit a search", nil, s.ref(types.SearchRequest{}), map[string]any{
+					"200": s.dataResponse("How many candidates each feature constraint eliminates", s.ref(types.DiagnoseResponse{})),
+					"400": jsonResponse("Invalid search request", errRef),
+					"413": jsonResponse("Request body too large", errRef),
+					"415": jsonResponse("Request body is not application/json", errRef),
+				}),
+			},
+			"/api/search/validate": map[string]any{
+				"post": operation("Validate a search without running it", nil, s.ref(types.SearchRequest{}), map[string]any{
+					"200": s.dataResponse("The request normalized as the search would run it, with its effective constraints", s.ref(types.SearchValidation{})),
+					"400": jsonResponse("Invalid search request, with every problem listed in errors", errRef),
+					"413": jsonResponse("Request body too large", errRef),
+					"415": jsonResponse("Request body is not application/json", errRef),
+				}),
+			},
+			"/api/search/more-like": map[string]any{
+				"post": operation("Find destinations like several liked ones", []any{unitsParam(), fieldsParam()}, s.ref(types.MoreLikeRequest{}), map[string]any{
+					"200": s.dataResponse("Destinations ranked by similarity to the centroid of the liked ones, which are left out, and the liked IDs that were not found", s.ref(types.MoreLikeResponse{})),
+					"400": jsonResponse("No IDs or more than 20, or an invalid limit", errRef),
+					"404": jsonResponse("None of the liked destinations were found", errRef),
+					"413": jsonResponse("Request body too large", errRef),
+					"415": jsonResponse("Request body is not application/json", errRef),
+				}),
+			},
+			"/api/autocomplete": map[string]any{
+				"get": operation("Suggest destination names", []any{
+					queryParam("q", "Name prefix or fragment; fewer than 2 characters returns no suggestions", str()),
+				}, nil, map[string]any{
+					"200": s.dataResponse("Up to 10 suggestions, prefix matches first", s.schemaFor(reflect.TypeFor[[]types.Suggestion]())),
+					"400": jsonResponse("Query too long", errRef),
+				}),
+			},
+			"/api/admin/reload": map[string]any{
+				"post": withBearerAuth(operation("Reload the destination dataset", nil, nil, map[string]any{
+					"200": s.dataResponse("Destination counts before and after the reload", s.ref(types.ReloadResponse{})),
+					"401": jsonResponse("Missing or invalid admin token", errRef),
+					"422": jsonResponse("The store does not support reloading, or the new data is invalid", errRef),
+				})),
+			},
+			"/api/admin/coverage": map[string]any{
+				"get": withBearerAuth(operation("Report per-feature data coverage", nil, nil, map[string]any{
+					"200": s.dataResponse("Destinations with a non-zero value for each feature", s.ref(types.CoverageResponse{})),
+					"401": jsonResponse("Missing or invalid admin token", errRef),
+				})),
+			},
+			"/api/compare": map[string]any{
+				"post": operation("Compare destinations side by side", []any{unitsParam()}, s.ref(types.CompareRequest{}), map[string]any{
+					"200": s.dataResponse("The destinations and a per-feature comparison", s.ref(types.CompareResponse{})),
+					"400": jsonResponse("Too few or too many IDs", errRef),
+					"413": jsonResponse("Request body too large", errRef),
+					"415": jsonResponse("Request body is not application/json", errRef),
+					"404": jsonResponse("Some destinations were not found", errRef),
+				}),
+			},
+		},
+		"components": map[string]any{
+			"schemas": s.components,
+			"securitySchemes": map[string]any{
+				"adminToken": map[string]any{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+})
+
+// Handler serves the OpenAPI document as JSON
+func Handler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Spec())
+}
+
+func operation(summary string, params []any, body map[string]any, responses map[string]any) map[string]any {
+	op := map[string]any{"summary": summary, "responses": responses}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+	if body != nil {
+		op["requestBody"] = map[string]any{
+			"required": true,
+			"content":  map[string]any{"application/json": map[string]any{"schema": body}},
+		}
+	}
+	return op
+}
+
+func jsonResponse(description string, schema map[string]any) map[string]any {
+	return map[string]any{
+		"description": description,
+		"content":     map[string]any{"application/json": map[string]any{"schema": schema}},
+	}
+}
+
+// dataResponse is jsonResponse for payloads wrapped in the API envelope
+func (s *schemas) dataResponse(description string, schema map[string]any) map[string]any {
+	return jsonResponse(description, s.data(schema))
+}
+
+// data describes the envelope API payloads are wrapped in
+func (s *schemas) data(schema map[string]any) map[string]any {
+	return map[string]any{
+		"type":       "object",
+		"required":   []string{"data"},
+		"properties": map[string]any{"data": schema, "meta": s.ref(types.ResponseMeta{})},
+	}
+}
+
+func geoFilterParams() []any {
+	return []any{
+		queryParam("continent", "Only destinations on this continent", map[string]any{"type": "string", "enum": enumValues(types.AllContinents())}),
+		queryParam("country", "Only destinations in this country", str()),
+		queryParam("region", "Only destinations in this region", str()),
+		queryParam("near", "Center point as lat,lon (requires radius_km)", str()),
+		queryParam("radius_km", "Search radius around near", number()),
+		queryParam("bbox", "Bounding box as min_lat,min_lon,max_lat,max_lon", str()),
+	}
+}
+
+// searchQueryParams describe the SearchRequest fields GET /api/search takes
+// as query parameters
+func searchQueryParams() []any {
+	params := []any{
+		queryParam("q", "Free-text query", str()),
+		queryParam("preset", "Vibe preset to search for (see /api/presets)", str()),
+		queryParam("limit", "Maximum results per page", integer()),
+		queryParam("offset", "Results to skip", integer()),
+		queryParam("min_score", "Drop results scoring below this, 0-1", number()),
+		queryParam("tags", "Comma-separated tags every result must have", str()),
+		queryParam("any_tags", "Comma-separated tags results need at least one of", str()),
+		queryParam("exclude", "Comma-separated destination IDs to leave out", str()),
+	}
+	params = append(params, geoFilterParams()...)
+	for _, feat := range ranking.Features {
+		params = append(params,
+			queryParam(feat.Key+".min", "Lowest normalized "+feat.Key, number()),
+			queryParam(feat.Key+".max", "Highest normalized "+feat.Key, number()),
+			queryParam(feat.Key+".weight", "Ranking weight of "+feat.Key, number()),
+			queryParam(feat.Key+".avoid", "Avoidance strength for "+feat.Key+", 0-1", number()),
+		)
+	}
+	return params
+}
+
+func withBearerAuth(op map[string]any) map[string]any {
+	op["security"] = []any{map[string]any{"adminToken": []any{}}}
+	return op
+}
+
+func statusSchema() map[string]any {
+	return map[string]any{"type": "object", "properties": map[string]any{"status": map[string]any{"type": "string"}}}
+}
+
+func healthSchema() map[string]any {
+	return map[string]any{"type": "object", "properties": map[string]any{
+		"status":          map[string]any{"type": "string"},
+		"dataset_version": map[string]any{"type": "string"},
+	}}
+}
+
+func notModified() map[string]any {
+	return map[string]any{"description": "Unchanged since the ETag sent in If-None-Match"}
+}
+
+func queryParam(name, description string, schema map[string]any) map[string]any {
+	return map[string]any{"name": name, "in": "query", "description": description, "schema": schema}
+}
+
+func idParam() map[string]any {
+	return map[string]any{"name": "id", "in": "path", "required": true, "schema": str()}
+}
+
+func unitsParam() map[string]any {
+	return queryParam("units", "Temperature unit for display values", map[string]any{"type": "string", "enum": []string{"c", "f"}})
+}
+
+func tierParam() map[string]any {
+	return queryParam("tier", "Keep only destinations in this popularity tier", map[string]any{"type": "string", "enum": []string{"hidden_gem", "popular", "iconic"}})
+}
+
+func includeInactiveParam() map[string]any {
+	return queryParam("include_inactive", "Include inactive destinations; requires the admin bearer token", boolean())
+}
+
+func fieldsParam() map[string]any {
+	return queryParam("fields", "Comma-separated destination keys to include (id is always included)", str())
+}
+
+func str() map[string]any     { return map[string]any{"type": "string"} }
+func integer() map[string]any { return map[string]any{"type": "integer"} }
+func boolean() map[string]any { return map[string]any{"type": "boolean"} }
+func number() map[string]any  { return map[string]any{"type": "number"} }
@@ -0,0 +1,74 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// loadSpec parses the served document with an OpenAPI 3 parser
+func loadSpec(t *testing.T) *openapi3.T {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	Handler(rec, httptest.NewRequest(http.MethodGet, Path, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	doc, err := openapi3.NewLoader().LoadFromData(rec.Body.Bytes())
+	if err != nil {
+		t.Fatalf("load spec: %v", err)
+	}
+	return doc
+}
+
+func TestSpecValidates(t *testing.T) {
+	doc := loadSpec(t)
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("spec is invalid: %v", err)
+	}
+}
+
+func TestSpecDescribesRoutes(t *testing.T) {
+	doc := loadSpec(t)
+	tests := []struct {
+		path, method string
+	}{
+		{"/health", http.MethodGet},
+		{"/api/destinations", http.MethodGet},
+		{"/api/destinations/{id}", http.MethodGet},
+		{"/api/search", http.MethodPost},
+		{"/api/search", http.MethodGet},
+		{"/api/compare", http.MethodPost},
+	}
+	for _, tt := range tests {
+		item := doc.Paths.Find(tt.path)
+		if item == nil || item.GetOperation(tt.method) == nil {
+			t.Errorf("no %s %s operation", tt.method, tt.path)
+		}
+	}
+	for _, name := range []string{"DestinationView", "SearchRequest", "SearchResponse", "Error"} {
+		if doc.Components.Schemas[name] == nil {
+			t.Errorf("no %s component schema", name)
+		}
+	}
+}
+
+func TestGeneratedSchemasMatchJSON(t *testing.T) {
+	schema := loadSpec(t).Components.Schemas["SearchRequest"]
+	if schema == nil {
+		t.Fatal("no SearchRequest schema")
+	}
+	// Property names come from the json tags, so they match what's decoded
+	for _, name := range []string{"query", "constraints", "filters", "limit", "offset"} {
+		if schema.Value.Properties[name] == nil {
+			t.Errorf("SearchRequest has no %s property", name)
+		}
+	}
+	if _, err := json.Marshal(Spec()); err != nil {
+		t.Errorf("Spec doesn't encode: %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// enums lists the allowed values for named string types
+var enums = map[string][]string{
+	"Continent":       enumValues(types.AllContinents()),
+	"DestinationType": {"city", "region"},
+	"PopularityTier":  {"hidden_gem", "popular", "iconic"},
+	"TemperatureUnit": {"c", "f"},
+}
+
+// enumValues converts typed string constants for use in enums
+func enumValues[T ~string](values []T) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = string(v)
+	}
+	return out
+}
+
+// schemas generates JSON schemas for Go types, registering named structs
+// as reusable components
+type schemas struct {
+	components map[string]any
+}
+
+func newSchemas() *schemas {
+	return &schemas{components: map[string]any{}}
+}
+
+// ref returns a schema for v's type, adding struct types to the components
+// and referencing them by name
+func (s *schemas) ref(v any) map[string]any {
+	return s.schemaFor(reflect.TypeOf(v))
+}
+
+func (s *schemas) schemaFor(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return s.schemaFor(t.Elem())
+	case reflect.Struct:
+		name := t.Name()
+		if name == "" {
+			return s.structSchema(t)
+		}
+		if _, ok := s.components[name]; !ok {
+			// Reserve the name first so recursive types terminate
+			s.components[name] = nil
+			s.components[name] = s.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": s.schemaFor(t.Elem())}
+	case reflect.Array:
+		return map[string]any{"type": "array", "items": s.schemaFor(t.Elem()), "minItems": t.Len(), "maxItems": t.Len()}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": s.schemaFor(t.Elem())}
+	case reflect.String:
+		schema := map[string]any{"type": "string"}
+		if values, ok := enums[t.Name()]; ok {
+			schema["enum"] = values
+		}
+		return schema
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	default:
+		return map[string]any{}
+	}
+}
+
+// structSchema describes a struct's JSON encoding, flattening embedded
+// structs the way encoding/json does
+func (s *schemas) structSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	var required []string
+	s.addFields(t, properties, &required)
+
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func (s *schemas) addFields(t reflect.Type, properties map[string]any, required *[]string) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			s.addFields(field.Type, properties, required)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = s.schemaFor(field.Type)
+		if !strings.Contains(opts, "omitempty") && !strings.Contains(opts, "omitzero") && field.Type.Kind() != reflect.Pointer {
+			*required = append(*required, name)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package ranking
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// FeatureAliases maps alternative feature names, such as "warmth", to
+// feature keys such as "avg_temp_c"
+type FeatureAliases map[string]string
+
+// DefaultFeatureAliases holds the Aliases of every registered feature
+var DefaultFeatureAliases = func() FeatureAliases {
+	a, err := NewFeatureAliases(nil)
+	if err != nil {
+		panic("ranking: " + err.Error())
+	}
+	return a
+}()
+
+// NewFeatureAliases collects the Aliases of every feature in Features and
+// adds extra, which maps more names to feature keys. An alias must point at
+// a known feature and can't be a feature key or another feature's alias.
+func NewFeatureAliases(extra map[string]string) (FeatureAliases, error) {
+	a := FeatureAliases{}
+	add := func(alias, key string) error {
+		if _, ok := featuresByKey[alias]; ok {
+			return fmt.Errorf("alias %q is already a feature key", alias)
+		}
+		if _, ok := featuresByKey[key]; !ok {
+			return fmt.Errorf("alias %q names unknown feature %q", alias, key)
+		}
+		if prev, ok := a[alias]; ok && prev != key {
+			return fmt.Errorf("alias %q names both %s and %s", alias, prev, key)
+		}
+		a[alias] = key
+		return nil
+	}
+	for _, feat := range Features {
+		for _, alias := range feat.Aliases {
+			if err := add(alias, feat.Key); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// Sorted so the first conflict reported is stable
+	aliases := make([]string, 0, len(extra))
+	for alias := range extra {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		if err := add(alias, extra[alias]); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// For lists the aliases of the feature key, sorted
+func (a FeatureAliases) For(key string) []string {
+	var aliases []string
+	for alias, k := range a {
+		if k == key {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// Resolve returns the feature key name refers to, either directly or
+// through an alias
+func (a FeatureAliases) Resolve(name string) (string, bool) {
+	if _, ok := featuresByKey[name]; ok {
+		return name, true
+	}
+	key, ok := a[name]
+	return key, ok
+}
+
+// ResolveKeys returns m with every alias key replaced by its feature key.
+// Names that are neither are an error listing them all, as is naming one
+// feature twice, such as by both "warmth" and "avg_temp_c".
+func ResolveKeys[V any](a FeatureAliases, m map[string]V) (map[string]V, error) {
+	if m == nil {
+		return nil, nil
+	}
+	out := make(map[string]V, len(m))
+	names := make(map[string]string, len(m))
+	var unknown []string
+	for name, v := range m {
+		key, ok := a.Resolve(name)
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		if prev, ok := names[key]; ok {
+			first, second := min(prev, name), max(prev, name)
+			return nil, fmt.Errorf("%s and %s both name feature %s", first, second, key)
+		}
+		names[key] = name
+		out[key] = v
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown features: %s (see GET /api/features for keys and aliases)", strings.Join(unknown, ", "))
+	}
+	return out, nil
+}
+
+// ResolveGroup applies ResolveKeys to the constraints of every group in the
+// tree rooted at g, in place
+func ResolveGroup(a FeatureAliases, g *types.ConstraintGroup) error {
+	c, err := ResolveKeys(a, g.Constraints)
+	if err != nil {
+		return err
+	}
+	g.Constraints = c
+	for _, children := range [][]types.ConstraintGroup{g.All, g.Any} {
+		for i := range children {
+			if err := ResolveGroup(a, &children[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
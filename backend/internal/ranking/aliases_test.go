@@ -0,0 +1,129 @@
+package ranking
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestNewFeatureAliases(t *testing.T) {
+	tests := []struct {
+		name  string
+		extra map[string]string
+		// wantErr is a substring of the expected error, empty for none
+		wantErr string
+	}{
+		{"built-in only", nil, ""},
+		{"extra alias", map[string]string{"heat": "avg_temp_c"}, ""},
+		{"repeats a built-in", map[string]string{"warmth": "avg_temp_c"}, ""},
+		{"unknown feature", map[string]string{"llamas": "llama_density"}, `alias "llamas" names unknown feature "llama_density"`},
+		{"shadows a feature key", map[string]string{"elevation": "avg_temp_c"}, `alias "elevation" is already a feature key`},
+		{"takes a built-in", map[string]string{"warmth": "nightlife_density"}, `alias "warmth" names both avg_temp_c and nightlife_density`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := NewFeatureAliases(tt.extra)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewFeatureAliases: %v", err)
+			}
+			for alias, key := range tt.extra {
+				if got, _ := a.Resolve(alias); got != key {
+					t.Errorf("Resolve(%q) = %q, want %q", alias, got, key)
+				}
+			}
+		})
+	}
+}
+
+func TestFeatureAliasesFor(t *testing.T) {
+	a, err := NewFeatureAliases(map[string]string{"heat": "avg_temp_c"})
+	if err != nil {
+		t.Fatalf("NewFeatureAliases: %v", err)
+	}
+	if got, want := a.For("avg_temp_c"), []string{"heat", "temperature", "warmth"}; !slices.Equal(got, want) {
+		t.Errorf("For(avg_temp_c) = %q, want %q", got, want)
+	}
+	if got := DefaultFeatureAliases.For("avg_temp_c"); slices.Contains(got, "heat") {
+		t.Errorf("configured alias leaked into the defaults: %q", got)
+	}
+}
+
+func TestResolveKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		in   map[string]float64
+		want map[string]float64
+		// wantErr is the expected error, empty for none
+		wantErr string
+	}{
+		{"nil", nil, nil, ""},
+		{"feature keys", map[string]float64{"avg_temp_c": 1}, map[string]float64{"avg_temp_c": 1}, ""},
+		{
+			"aliases",
+			map[string]float64{"warmth": 2, "crowds": 0.5, "altitude": 1, "nightlife": 3},
+			map[string]float64{"avg_temp_c": 2, "tourism_density": 0.5, "elevation": 1, "nightlife_density": 3},
+			"",
+		},
+		{
+			"unknown names listed",
+			map[string]float64{"warmth": 1, "vibes": 1, "llamas": 1},
+			nil,
+			"unknown features: llamas, vibes (see GET /api/features for keys and aliases)",
+		},
+		{"one feature twice", map[string]float64{"warmth": 1, "avg_temp_c": 2}, nil, "avg_temp_c and warmth both name feature avg_temp_c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveKeys(DefaultFeatureAliases, tt.in)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveKeys: %v", err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("ResolveKeys = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveGroup(t *testing.T) {
+	bound := types.FeatureConstraint{Min: new(0.5)}
+	g := types.ConstraintGroup{
+		Constraints: types.SearchConstraints{"warmth": bound},
+		Any: []types.ConstraintGroup{
+			{Constraints: types.SearchConstraints{"skiing": bound}},
+			{All: []types.ConstraintGroup{{Constraints: types.SearchConstraints{"hiking": bound}}}},
+		},
+	}
+	if err := ResolveGroup(DefaultFeatureAliases, &g); err != nil {
+		t.Fatalf("ResolveGroup: %v", err)
+	}
+	for name, c := range map[string]types.SearchConstraints{
+		"avg_temp_c":   g.Constraints,
+		"skiing_score": g.Any[0].Constraints,
+		"hiking_score": g.Any[1].All[0].Constraints,
+	} {
+		if _, ok := c[name]; !ok || len(c) != 1 {
+			t.Errorf("constraints = %v, want only %s", c, name)
+		}
+	}
+
+	bad := types.ConstraintGroup{Any: []types.ConstraintGroup{{Constraints: types.SearchConstraints{"vibes": bound}}}}
+	if err := ResolveGroup(DefaultFeatureAliases, &bad); err == nil || !strings.Contains(err.Error(), "unknown features: vibes") {
+		t.Errorf("error = %v, want vibes reported", err)
+	}
+}
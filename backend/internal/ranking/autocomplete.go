@@ -0,0 +1,55 @@
+package ranking
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// MinSuggestQueryLength is the shortest query that produces suggestions
+const MinSuggestQueryLength = 2
+
+// Suggest returns up to n destinations whose names contain the query,
+// ignoring case and accents. Names starting with the query rank above other
+// substring matches, and more popular destinations come first within each.
+func Suggest(query string, dests []types.Destination, n int) []types.Destination {
+	q := foldText(query)
+	if len([]rune(q)) < MinSuggestQueryLength {
+		return []types.Destination{}
+	}
+
+	type match struct {
+		d      types.Destination
+		prefix bool
+	}
+	var matches []match
+	for _, d := range dests {
+		name := foldText(d.Name)
+		if strings.HasPrefix(name, q) {
+			matches = append(matches, match{d, true})
+		} else if strings.Contains(name, q) {
+			matches = append(matches, match{d, false})
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b match) int {
+		if a.prefix != b.prefix {
+			if a.prefix {
+				return -1
+			}
+			return 1
+		}
+		if c := cmp.Compare(b.d.Features.WikipediaPageviews, a.d.Features.WikipediaPageviews); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.d.Name, b.d.Name)
+	})
+
+	out := make([]types.Destination, 0, min(n, len(matches)))
+	for _, m := range matches[:min(n, len(matches))] {
+		out = append(out, m.d)
+	}
+	return out
+}
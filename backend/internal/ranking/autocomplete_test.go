@@ -0,0 +1,54 @@
+package ranking
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// suggestFixture has prefix and substring matches for "san" at varying
+// popularity
+var suggestFixture = []types.Destination{
+	{ID: "santiago", Name: "Santiago", Features: types.DestinationFeatures{WikipediaPageviews: 0.6}},
+	{ID: "san-sebastian", Name: "San Sebastián", Features: types.DestinationFeatures{WikipediaPageviews: 0.8}},
+	{ID: "busan", Name: "Busan", Features: types.DestinationFeatures{WikipediaPageviews: 0.9}},
+	{ID: "lausanne", Name: "Lausanne", Features: types.DestinationFeatures{WikipediaPageviews: 0.4}},
+	{ID: "sao-paulo", Name: "São Paulo", Features: types.DestinationFeatures{WikipediaPageviews: 0.95}},
+	{ID: "kyoto", Name: "Kyoto", Features: types.DestinationFeatures{WikipediaPageviews: 1}},
+}
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		query string
+		n     int
+		want  []string
+	}{
+		// Prefix matches first, then substring ones, each by popularity
+		{"san", 10, []string{"san-sebastian", "santiago", "busan", "lausanne"}},
+		{"SAN", 10, []string{"san-sebastian", "santiago", "busan", "lausanne"}},
+		{"san", 3, []string{"san-sebastian", "santiago", "busan"}},
+		{"sebastian", 10, []string{"san-sebastian"}},
+		{"sao", 10, []string{"sao-paulo"}},
+		{"são", 10, []string{"sao-paulo"}},
+		{"zz", 10, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.query, tt.n), func(t *testing.T) {
+			got := ids(Suggest(tt.query, suggestFixture, tt.n))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Suggest(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestShortQuery(t *testing.T) {
+	for _, q := range []string{"", "s", " ", "é"} {
+		got := Suggest(q, suggestFixture, 10)
+		if got == nil || len(got) != 0 {
+			t.Errorf("Suggest(%q) = %v, want an empty list", q, ids(got))
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package ranking
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefaultAvoidPenalty is how much of the score a fully avoided feature can
+// take away at strength 1
+const DefaultAvoidPenalty = 0.3
+
+// AvoidPenaltyKey labels the avoidance penalty in a score breakdown
+const AvoidPenaltyKey = "avoid_penalty"
+
+// AvoidVector turns per-feature avoidance strengths keyed by feature name
+// into a vector ordered like Features. Strengths must lie in [0, 1]; a nil
+// result means nothing is avoided.
+func AvoidVector(avoid map[string]float64) ([]float64, error) {
+	if len(avoid) == 0 {
+		return nil, nil
+	}
+
+	var unknown []string
+	for key, s := range avoid {
+		if _, ok := FeatureByKey(key); !ok {
+			unknown = append(unknown, key)
+			continue
+		}
+		if !(s >= 0 && s <= 1) {
+			return nil, fmt.Errorf("avoid strength for %s must be between 0 and 1, got %g", key, s)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown avoid features: %v", unknown)
+	}
+
+	vec := make([]float64, len(Features))
+	for i, feat := range Features {
+		vec[i] = avoid[feat.Key]
+	}
+	return vec, nil
+}
@@ -0,0 +1,87 @@
+package ranking
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestAvoidVector(t *testing.T) {
+	tests := []struct {
+		name  string
+		avoid map[string]float64
+		// wantErr is a substring of the expected error, "" for none
+		wantErr string
+	}{
+		{"none", nil, ""},
+		{"valid", map[string]float64{"tourism_density": 1, "nightlife_density": 0.5}, ""},
+		{"too strong", map[string]float64{"tourism_density": 1.5}, "avoid strength for tourism_density must be between 0 and 1, got 1.5"},
+		{"negative", map[string]float64{"tourism_density": -0.1}, "must be between 0 and 1"},
+		{"unknown", map[string]float64{"crowds_index": 1, "tourism_density": 1}, "unknown avoid features: [crowds_index]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vec, err := AvoidVector(tt.avoid)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("AvoidVector: %v", err)
+				}
+				if len(tt.avoid) == 0 && vec != nil {
+					t.Errorf("AvoidVector(nil) = %v, want nil", vec)
+				}
+				for i, feat := range Features {
+					if vec != nil && vec[i] != tt.avoid[feat.Key] {
+						t.Errorf("%s = %v, want %v", feat.Key, vec[i], tt.avoid[feat.Key])
+					}
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("AvoidVector error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAvoidDropsTouristyDestinations(t *testing.T) {
+	// Both match a beach query; the crowded one matches slightly better.
+	// Euclidean over the targeted features keeps tourism out of the match.
+	dests := []types.Destination{
+		{ID: "crowded", Features: types.DestinationFeatures{AvgTempC: 0.75, WaterSportsScore: 0.9, TourismDensity: 0.95}},
+		{ID: "quiet", Features: types.DestinationFeatures{AvgTempC: 0.7, WaterSportsScore: 0.8, TourismDensity: 0.1}},
+		{ID: "inland", Features: types.DestinationFeatures{AvgTempC: 0.3, HikingScore: 0.8, TourismDensity: 0.2}},
+	}
+	query := types.DestinationFeatures{AvgTempC: 0.75, WaterSportsScore: 0.9}
+	avoid, _ := AvoidVector(map[string]float64{"tourism_density": 1})
+	base := Scorer{Query: query, Metric: EuclideanSimilarity, Constrained: ConstrainedMask("avg_temp_c", "water_sports_score")}
+	withAvoid := func(penalty float64) Scorer {
+		s := base
+		s.Avoid, s.AvoidPenalty = avoid, penalty
+		return s
+	}
+
+	tests := []struct {
+		name   string
+		scorer Scorer
+		want   []string
+	}{
+		{"without avoid", base, []string{"crowded", "quiet", "inland"}},
+		{"avoiding tourism", withAvoid(DefaultAvoidPenalty), []string{"quiet", "crowded", "inland"}},
+		{"no penalty weight", withAvoid(0), []string{"crowded", "quiet", "inland"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := tt.scorer.Rank(dests)
+			if got := resultIDs(results); !slices.Equal(got, tt.want) {
+				t.Errorf("ranking = %v, want %v", got, tt.want)
+			}
+			for i := 1; i < len(results); i++ {
+				if results[i].Score > results[i-1].Score {
+					t.Errorf("scores out of order after penalties: %v then %v", results[i-1].Score, results[i].Score)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package ranking
+
+import (
+	"errors"
+	"math"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// Default comfortable temperature range for BestMonth, in °C
+const (
+	DefaultComfortMinC = 18.0
+	DefaultComfortMaxC = 26.0
+)
+
+// comfortFalloffC is how far outside the comfort range, in °C, a month's
+// comfort drops to zero
+const comfortFalloffC = 10.0
+
+// activityWeight scales how much a strong activity can outweigh comfort
+const activityWeight = 0.5
+
+// ErrNoSeasonalData is returned for destinations without monthly temperatures
+var ErrNoSeasonalData = errors.New("destination has no seasonal temperature data")
+
+// MonthPick is the month BestMonth chose and why
+type MonthPick struct {
+	Month int
+	TempC float64
+	// InComfortRange is set when the month's temperature is within the range
+	InComfortRange bool
+	// Skiing and WaterSports are set when that activity lifted the month
+	Skiing      bool
+	WaterSports bool
+}
+
+// BestMonth picks the most pleasant month (1-12) to visit d. Each month is
+// rated on how close its temperature is to [minC, maxC], plus a bonus for
+// snow-cold months at ski destinations and warm months at water sports ones.
+func BestMonth(d types.Destination, minC, maxC float64) (MonthPick, error) {
+	f := d.Features
+	if !HasSeasonalData(f) {
+		return MonthPick{}, ErrNoSeasonalData
+	}
+
+	var best MonthPick
+	bestScore := math.Inf(-1)
+	for m := 1; m <= 12; m++ {
+		t := CelsiusFromNormalized(MonthTemp(f, m))
+		ski := activityWeight * f.SkiingScore * rampDown(t, 0, 5)
+		swim := activityWeight * f.WaterSportsScore * rampUp(t, 20, 26)
+		score := comfort(t, minC, maxC) + ski + swim
+		if score > bestScore {
+			bestScore = score
+			best = MonthPick{
+				Month:          m,
+				TempC:          t,
+				InComfortRange: t >= minC && t <= maxC,
+				Skiing:         ski >= 0.1,
+				WaterSports:    swim >= 0.1,
+			}
+		}
+	}
+	return best, nil
+}
+
+// comfort is 1 within [minC, maxC], falling linearly to 0 over comfortFalloffC
+func comfort(t, minC, maxC float64) float64 {
+	dist := math.Max(minC-t, t-maxC)
+	if dist <= 0 {
+		return 1
+	}
+	return math.Max(0, 1-dist/comfortFalloffC)
+}
+
+// rampUp is 0 at or below lo, 1 at or above hi, and linear in between
+func rampUp(v, lo, hi float64) float64 {
+	return math.Min(math.Max((v-lo)/(hi-lo), 0), 1)
+}
+
+// rampDown is 1 at or below lo, 0 at or above hi, and linear in between
+func rampDown(v, lo, hi float64) float64 {
+	return 1 - rampUp(v, lo, hi)
+}
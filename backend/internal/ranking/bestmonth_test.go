@@ -0,0 +1,59 @@
+package ranking
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// seasonal builds a destination from monthly temperatures in °C
+func seasonal(id string, tempsC [12]float64, skiing, waterSports float64) types.Destination {
+	d := types.Destination{ID: id, Features: types.DestinationFeatures{SkiingScore: skiing, WaterSportsScore: waterSports}}
+	for m, c := range tempsC {
+		d.Features.MonthlyTempC[m] = (c - MinTempC) / (MaxTempC - MinTempC)
+	}
+	return d
+}
+
+func TestBestMonth(t *testing.T) {
+	lisbon := seasonal("lisbon", [12]float64{11, 12, 14, 16, 17, 21, 23, 24, 22, 18, 14, 12}, 0, 0)
+	zermatt := seasonal("zermatt", [12]float64{-7, -6, -2, 2, 6, 9, 11, 10, 7, 3, -2, -6}, 0.95, 0)
+	bali := seasonal("bali", [12]float64{26, 27, 28, 29, 30, 31, 31, 30, 29, 28, 27, 27}, 0, 0.9)
+
+	tests := []struct {
+		name       string
+		d          types.Destination
+		minC, maxC float64
+		want       MonthPick
+	}{
+		{"first comfortable month", lisbon, DefaultComfortMinC, DefaultComfortMaxC,
+			MonthPick{Month: 6, TempC: 21, InComfortRange: true}},
+		{"narrower range", lisbon, 23, 26, MonthPick{Month: 7, TempC: 23, InComfortRange: true}},
+		{"closest when none fit", lisbon, 30, 35, MonthPick{Month: 8, TempC: 24}},
+		{"snow outweighs a cool summer", zermatt, DefaultComfortMinC, DefaultComfortMaxC,
+			MonthPick{Month: 1, TempC: -7, Skiing: true}},
+		{"warm water", bali, DefaultComfortMinC, DefaultComfortMaxC,
+			MonthPick{Month: 1, TempC: 26, InComfortRange: true, WaterSports: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BestMonth(tt.d, tt.minC, tt.maxC)
+			if err != nil {
+				t.Fatalf("BestMonth: %v", err)
+			}
+			got.TempC = math.Round(got.TempC*100) / 100
+			if got != tt.want {
+				t.Errorf("BestMonth = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBestMonthWithoutSeasonalData(t *testing.T) {
+	annual := types.Destination{ID: "annual", Features: types.DestinationFeatures{AvgTempC: 0.6}}
+	if _, err := BestMonth(annual, DefaultComfortMinC, DefaultComfortMaxC); !errors.Is(err, ErrNoSeasonalData) {
+		t.Errorf("BestMonth error = %v, want ErrNoSeasonalData", err)
+	}
+}
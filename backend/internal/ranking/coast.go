@@ -0,0 +1,24 @@
+package ranking
+
+import "github.com/simonryrie/otherwhere/internal/types"
+
+// DefaultCoastalKm is the coast distance below which a destination counts as coastal
+const DefaultCoastalKm = 10.0
+
+// IsCoastal reports whether d lies less than thresholdKm from the coast. The
+// threshold is compared on the normalized scale, so a stored distance of
+// exactly the threshold is not coastal.
+func IsCoastal(d types.Destination, thresholdKm float64) bool {
+	return d.Features.CoastDistanceKm < FeatureRanges["coast_distance_km"].Scale(thresholdKm)
+}
+
+// FilterCoastal keeps the destinations less than thresholdKm from the coast
+func FilterCoastal(dests []types.Destination, thresholdKm float64) []types.Destination {
+	filtered := make([]types.Destination, 0, len(dests))
+	for _, d := range dests {
+		if IsCoastal(d, thresholdKm) {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered
+}
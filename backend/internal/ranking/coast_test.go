@@ -0,0 +1,43 @@
+package ranking
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// atCoastKm is a destination the given distance from the coast
+func atCoastKm(id string, km float64) types.Destination {
+	return types.Destination{ID: id, Features: types.DestinationFeatures{CoastDistanceKm: FeatureRanges["coast_distance_km"].Scale(km)}}
+}
+
+func TestIsCoastal(t *testing.T) {
+	tests := []struct {
+		name        string
+		km          float64
+		thresholdKm float64
+		want        bool
+	}{
+		{"on the coast", 0, DefaultCoastalKm, true},
+		{"just inside", 9.9, DefaultCoastalKm, true},
+		{"exactly at the threshold", 10, DefaultCoastalKm, false},
+		{"inland", 250, DefaultCoastalKm, false},
+		{"wider threshold", 40, 50, true},
+		{"zero threshold", 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsCoastal(atCoastKm("d", tt.km), tt.thresholdKm); got != tt.want {
+				t.Errorf("IsCoastal(%g km, threshold %g km) = %t, want %t", tt.km, tt.thresholdKm, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterCoastal(t *testing.T) {
+	dests := []types.Destination{atCoastKm("beach", 0), atCoastKm("boundary", 10), atCoastKm("near", 5), atCoastKm("inland", 300)}
+	if got := ids(FilterCoastal(dests, DefaultCoastalKm)); !slices.Equal(got, []string{"beach", "near"}) {
+		t.Errorf("FilterCoastal = %v, want [beach near]", got)
+	}
+}
@@ -0,0 +1,40 @@
+package ranking
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Default thresholds for treating two results as the same place
+const (
+	DefaultCollapseRadiusKm      = 25.0
+	DefaultCollapseMinSimilarity = 0.98
+)
+
+// Collapse merges near-duplicate results, such as a city listed alongside
+// its encompassing region: results within radiusKm of each other whose
+// feature vectors have at least minSimilarity cosine similarity. Each group
+// keeps its more popular destination, by the popularity feature, with that
+// destination's own score, and counts the others in Collapsed. The output is
+// sorted by descending score, stably.
+func Collapse(results []Result, radiusKm, minSimilarity float64, popularity Feature) []Result {
+	kept := make([]Result, 0, len(results))
+	for _, res := range results {
+		i := slices.IndexFunc(kept, func(k Result) bool {
+			return HaversineKm(k.Destination.Location, res.Destination.Location) <= radiusKm &&
+				CosineSimilarity(Vector(k.Destination.Features), Vector(res.Destination.Features)) >= minSimilarity
+		})
+		if i < 0 {
+			kept = append(kept, res)
+			continue
+		}
+
+		collapsed := kept[i].Collapsed + res.Collapsed + 1
+		if *popularity.Field(&res.Destination.Features) > *popularity.Field(&kept[i].Destination.Features) {
+			kept[i] = res
+		}
+		kept[i].Collapsed = collapsed
+	}
+	slices.SortStableFunc(kept, func(a, b Result) int { return cmp.Compare(b.Score, a.Score) })
+	return kept
+}
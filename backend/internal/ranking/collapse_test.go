@@ -0,0 +1,73 @@
+package ranking
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// duplicateOf returns d moved by the given offset in degrees, under a new
+// ID and with the given pageviews
+func duplicateOf(d types.Destination, id string, dLat, pageviews float64) types.Destination {
+	d.ID = id
+	d.Location.Lat += dLat
+	d.Features.WikipediaPageviews = pageviews
+	return d
+}
+
+func TestCollapse(t *testing.T) {
+	popularity, _ := FeatureByKey(DefaultPopularityFeature)
+	zermatt := fixture(t, "zermatt")
+	zermatt.Location = types.Location{Lat: 46.02, Lon: 7.75}
+	// About 11km north, with the same vibe but fewer pageviews
+	region := duplicateOf(zermatt, "zermatt-region", 0.1, 0.2)
+	// Same vibe, but 550km away
+	faraway := duplicateOf(zermatt, "faraway", 5, 0.6)
+	// Close by, but a beach town
+	nearby := fixture(t, "tamarindo")
+	nearby.ID, nearby.Location = "nearby", zermatt.Location
+
+	tests := []struct {
+		name          string
+		results       []Result
+		want          []string
+		wantCollapsed []int
+	}{
+		{"duplicate pair", []Result{{Destination: region, Score: 0.9}, {Destination: zermatt, Score: 0.8}},
+			[]string{"zermatt"}, []int{1}},
+		{"far apart", []Result{{Destination: zermatt, Score: 0.9}, {Destination: faraway, Score: 0.8}},
+			[]string{"zermatt", "faraway"}, []int{0, 0}},
+		{"different vibe", []Result{{Destination: zermatt, Score: 0.9}, {Destination: nearby, Score: 0.8}},
+			[]string{"zermatt", "nearby"}, []int{0, 0}},
+		{"three of a kind", []Result{
+			{Destination: region, Score: 0.9},
+			{Destination: nearby, Score: 0.85},
+			{Destination: duplicateOf(zermatt, "village", -0.1, 0.1), Score: 0.8},
+			{Destination: zermatt, Score: 0.7},
+		}, []string{"nearby", "zermatt"}, []int{0, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Collapse(tt.results, DefaultCollapseRadiusKm, DefaultCollapseMinSimilarity, popularity)
+			collapsed := make([]int, len(got))
+			for i, res := range got {
+				collapsed[i] = res.Collapsed
+			}
+			if ids := resultIDs(got); !slices.Equal(ids, tt.want) || !slices.Equal(collapsed, tt.wantCollapsed) {
+				t.Errorf("Collapse = %v collapsing %v, want %v collapsing %v", ids, collapsed, tt.want, tt.wantCollapsed)
+			}
+		})
+	}
+}
+
+func TestCollapseKeepsWinnerScore(t *testing.T) {
+	popularity, _ := FeatureByKey(DefaultPopularityFeature)
+	zermatt := fixture(t, "zermatt")
+	region := duplicateOf(zermatt, "zermatt-region", 0.1, 0.2)
+	got := Collapse([]Result{{Destination: region, Score: 0.9}, {Destination: zermatt, Score: 0.8}},
+		DefaultCollapseRadiusKm, DefaultCollapseMinSimilarity, popularity)
+	if got[0].Score != 0.8 {
+		t.Errorf("score = %g, want the kept destination's own 0.8", got[0].Score)
+	}
+}
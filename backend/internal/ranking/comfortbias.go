@@ -0,0 +1,56 @@
+package ranking
+
+import (
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// Default temperature band, in °C, the search comfort bias favours
+const (
+	DefaultComfortBiasMinC = 15.0
+	DefaultComfortBiasMaxC = 25.0
+)
+
+// DefaultComfortPenalty is how much of the score the comfort bias takes
+// from a destination far outside the band
+const DefaultComfortPenalty = 0.2
+
+// ComfortPenaltyKey labels the comfort bias in a score breakdown
+const ComfortPenaltyKey = "comfort_penalty"
+
+// ComfortBias penalizes destinations whose temperature is outside
+// [MinC, MaxC]. The penalty grows linearly with the distance from the band
+// and reaches Penalty comfortFalloffC degrees outside it.
+type ComfortBias struct {
+	MinC, MaxC float64
+	Penalty    float64
+}
+
+// penalty is the score subtracted for d's distance from the band
+func (c ComfortBias) penalty(d types.Destination) float64 {
+	if !isFinite(d.Features.AvgTempC) {
+		return 0
+	}
+	t := CelsiusFromNormalized(d.Features.AvgTempC)
+	return c.Penalty * (1 - comfort(t, c.MinC, c.MaxC))
+}
+
+// ConstrainsTemperature reports whether c or any group in g bounds the
+// temperature, in which case the user's explicit choice wins over a
+// comfort bias
+func ConstrainsTemperature(c types.SearchConstraints, g *types.ConstraintGroup) bool {
+	if _, ok := c["avg_temp_c"]; ok {
+		return true
+	}
+	if g == nil {
+		return false
+	}
+	if ConstrainsTemperature(g.Constraints, nil) {
+		return true
+	}
+	for _, sub := range append(g.All, g.Any...) {
+		if ConstrainsTemperature(nil, &sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package ranking
+
+import (
+	"math"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// defaultComfort is the comfort bias with its default band and penalty
+var defaultComfort = ComfortBias{MinC: DefaultComfortBiasMinC, MaxC: DefaultComfortBiasMaxC, Penalty: DefaultComfortPenalty}
+
+// atTempC is a destination with the given average temperature
+func atTempC(id string, c float64) types.Destination {
+	return types.Destination{ID: id, Features: types.DestinationFeatures{AvgTempC: (c - MinTempC) / (MaxTempC - MinTempC)}}
+}
+
+func TestComfortBiasPenalty(t *testing.T) {
+	tests := []struct {
+		name  string
+		tempC float64
+		want  float64
+	}{
+		{"inside the band", 20, 0},
+		{"at the lower edge", 15, 0},
+		{"at the upper edge", 25, 0},
+		{"halfway down the falloff", 30, DefaultComfortPenalty / 2},
+		{"past the falloff", 40, DefaultComfortPenalty},
+		{"freezing", -10, DefaultComfortPenalty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := defaultComfort.penalty(atTempC("d", tt.tempC)); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("penalty at %g°C = %g, want %g", tt.tempC, got, tt.want)
+			}
+		})
+	}
+	if got := defaultComfort.penalty(types.Destination{Features: types.DestinationFeatures{AvgTempC: math.NaN()}}); got != 0 {
+		t.Errorf("penalty without a temperature = %g, want 0", got)
+	}
+}
+
+func TestComfortBiasRanksTemperateHigher(t *testing.T) {
+	hot, temperate := fixture(t, "tamarindo"), fixture(t, "tamarindo")
+	hot.ID, hot.Features.AvgTempC = "hot", atTempC("", 40).Features.AvgTempC
+	temperate.ID, temperate.Features.AvgTempC = "temperate", atTempC("", 20).Features.AvgTempC
+	// The query matches the hot destination exactly
+	scorer := Scorer{Query: hot.Features}
+	dests := []types.Destination{hot, temperate}
+
+	if got := resultIDs(scorer.Rank(dests)); got[0] != "hot" {
+		t.Fatalf("unbiased ranking = %v, want hot first", got)
+	}
+	scorer.Comfort = &defaultComfort
+	if got := resultIDs(scorer.Rank(dests)); got[0] != "temperate" {
+		t.Errorf("biased ranking = %v, want temperate first", got)
+	}
+	breakdown := scorer.Breakdown(hot)
+	if got := breakdown[ComfortPenaltyKey]; math.Abs(got+DefaultComfortPenalty) > 1e-9 {
+		t.Errorf("breakdown %s = %g, want %g", ComfortPenaltyKey, got, -DefaultComfortPenalty)
+	}
+}
+
+func TestConstrainsTemperature(t *testing.T) {
+	temp := types.SearchConstraints{"avg_temp_c": {Max: new(0.3)}}
+	other := types.SearchConstraints{"skiing_score": {Min: new(0.6)}}
+	tests := []struct {
+		name        string
+		constraints types.SearchConstraints
+		where       *types.ConstraintGroup
+		want        bool
+	}{
+		{"none", nil, nil, false},
+		{"other feature", other, nil, false},
+		{"flat constraint", temp, nil, true},
+		{"group constraint", other, &types.ConstraintGroup{Constraints: temp}, true},
+		{"nested in any", nil, &types.ConstraintGroup{Any: []types.ConstraintGroup{
+			{Constraints: other},
+			{All: []types.ConstraintGroup{{Constraints: temp}}},
+		}}, true},
+		{"group without temperature", nil, &types.ConstraintGroup{All: []types.ConstraintGroup{{Constraints: other}}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ConstrainsTemperature(tt.constraints, tt.where); got != tt.want {
+				t.Errorf("ConstrainsTemperature = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
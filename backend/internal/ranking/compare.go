@@ -0,0 +1,36 @@
+package ranking
+
+import "github.com/simonryrie/otherwhere/internal/types"
+
+// Compare builds a per-feature comparison across dests. Each feature's
+// values are rescaled so the set's minimum maps to 0 and its maximum to 1;
+// when every destination has the same value they all map to 1.
+func Compare(dests []types.Destination) []types.FeatureComparison {
+	comparisons := make([]types.FeatureComparison, len(Features))
+	for i, feat := range Features {
+		c := types.FeatureComparison{
+			Key:        feat.Key,
+			Values:     make([]float64, len(dests)),
+			Normalized: make([]float64, len(dests)),
+		}
+		for j := range dests {
+			v := *feat.Field(&dests[j].Features)
+			c.Values[j] = v
+			if j == 0 || v < c.Min {
+				c.Min = v
+			}
+			if j == 0 || v > c.Max {
+				c.Max = v
+			}
+		}
+		for j, v := range c.Values {
+			if spread := c.Max - c.Min; spread > 0 {
+				c.Normalized[j] = (v - c.Min) / spread
+			} else {
+				c.Normalized[j] = 1
+			}
+		}
+		comparisons[i] = c
+	}
+	return comparisons
+}
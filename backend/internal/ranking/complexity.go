@@ -0,0 +1,35 @@
+package ranking
+
+import "github.com/simonryrie/otherwhere/internal/types"
+
+// DefaultMaxComplexity is the most complex search served by default, well
+// above what a person builds by hand
+const DefaultMaxComplexity = 100
+
+// Complexity scores the explicit terms of a search, which drive the cost of
+// filtering and scoring: one per feature constraint, at the top level or in
+// a where group, one per where group, including the root, and one per
+// weight and avoid entry. Query keywords are bounded by the query length
+// and not counted.
+func Complexity(req types.SearchRequest) int {
+	n := len(req.Weights) + len(req.Avoid)
+	if req.Constraints != nil {
+		n += len(*req.Constraints)
+	}
+	if req.Where != nil {
+		n += groupComplexity(*req.Where)
+	}
+	return n
+}
+
+// groupComplexity counts g, its constraints, and those of its descendants
+func groupComplexity(g types.ConstraintGroup) int {
+	n := 1 + len(g.Constraints)
+	for _, child := range g.All {
+		n += groupComplexity(child)
+	}
+	for _, child := range g.Any {
+		n += groupComplexity(child)
+	}
+	return n
+}
@@ -0,0 +1,47 @@
+package ranking
+
+import (
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestComplexity(t *testing.T) {
+	half := types.FeatureConstraint{Min: new(0.5)}
+	tests := []struct {
+		name string
+		req  types.SearchRequest
+		want int
+	}{
+		{"empty", types.SearchRequest{}, 0},
+		{"keywords aren't counted", types.SearchRequest{Query: "warm beach with nightlife"}, 0},
+		{
+			"constraints, weights, and avoid terms",
+			types.SearchRequest{
+				Constraints: &types.SearchConstraints{"hiking_score": half, "nature_ratio": half},
+				Weights:     map[string]float64{"hiking_score": 2},
+				Avoid:       map[string]float64{"nightlife_density": 1, "tourism_density": 0.5},
+			},
+			5,
+		},
+		{
+			// The root, two children, and one grandchild, plus four constraints
+			"nested groups",
+			types.SearchRequest{Where: &types.ConstraintGroup{
+				Constraints: types.SearchConstraints{"avg_temp_c": half},
+				Any: []types.ConstraintGroup{
+					{Constraints: types.SearchConstraints{"skiing_score": half}},
+					{All: []types.ConstraintGroup{{Constraints: types.SearchConstraints{"hiking_score": half, "elevation": half}}}},
+				},
+			}},
+			8,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Complexity(tt.req); got != tt.want {
+				t.Errorf("Complexity = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package ranking
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// ValidateConstraints reports constraints on unknown features, listing every
+// offending key, bounds outside the normalized scale or NaN, and constraints
+// whose min exceeds their max.
+//
+// Every feature's valid bounds are [0, 1]: Normalize clamps each source
+// range onto that scale, so temperatures outside -15 to 45 °C or any
+// population still land in it. Per-feature limits such as an unbounded
+// temperature or a non-negative population apply to raw measurements, which
+// constraints never carry; errors name the source range to relate a bound
+// back to them.
+func ValidateConstraints(c types.SearchConstraints) error {
+	var unknown []string
+	for key := range c {
+		if _, ok := FeatureByKey(key); !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown constraint features: %s", strings.Join(unknown, ", "))
+	}
+	for _, feat := range Features {
+		fc, ok := c[feat.Key]
+		if !ok {
+			continue
+		}
+		for _, bound := range []*float64{fc.Min, fc.Max} {
+			if bound != nil && !(*bound >= NormalizedMin && *bound <= NormalizedMax) {
+				return fmt.Errorf("constraint on %s must be between %g and %g%s, got %g",
+					feat.Key, NormalizedMin, NormalizedMax, sourceRange(feat), *bound)
+			}
+		}
+	}
+	return checkConstraints(c)
+}
+
+// ClampConstraints pulls bounds outside the normalized scale back onto it,
+// in place, and returns the sorted keys of the features it changed.
+// Constraints on unknown features are left for ValidateConstraints.
+func ClampConstraints(c types.SearchConstraints) []string {
+	var clamped []string
+	for key, fc := range c {
+		if _, ok := FeatureByKey(key); !ok {
+			continue
+		}
+		changed := false
+		for _, bound := range []**float64{&fc.Min, &fc.Max} {
+			if *bound == nil || math.IsNaN(**bound) {
+				continue
+			}
+			if v := math.Min(math.Max(**bound, NormalizedMin), NormalizedMax); v != **bound {
+				*bound = &v
+				changed = true
+			}
+		}
+		if changed {
+			c[key] = fc
+			clamped = append(clamped, key)
+		}
+	}
+	sort.Strings(clamped)
+	return clamped
+}
+
+// ClampConstraintGroup applies ClampConstraints to every group in the tree
+// rooted at g, returning the changed keys of all of them
+func ClampConstraintGroup(g *types.ConstraintGroup) []string {
+	clamped := ClampConstraints(g.Constraints)
+	for i := range g.All {
+		clamped = append(clamped, ClampConstraintGroup(&g.All[i])...)
+	}
+	for i := range g.Any {
+		clamped = append(clamped, ClampConstraintGroup(&g.Any[i])...)
+	}
+	return clamped
+}
+
+// ValidateFeatureVector reports query vector values outside the normalized
+// scale, naming the first offending feature in Features order
+func ValidateFeatureVector(v types.DestinationFeatures) error {
+	for _, feat := range Features {
+		if x := *feat.Field(&v); !(x >= NormalizedMin && x <= NormalizedMax) {
+			return fmt.Errorf("feature_vector %s must be between %g and %g%s, got %g",
+				feat.Key, NormalizedMin, NormalizedMax, sourceRange(feat), x)
+		}
+	}
+	return nil
+}
+
+// sourceRange describes the measurements a feature's normalized scale spans,
+// e.g. " (-15 to 45 °C)", or nothing for features already on [0, 1]
+func sourceRange(feat Feature) string {
+	r, ok := FeatureRanges[feat.Key]
+	if !ok || r == (Range{Min: NormalizedMin, Max: NormalizedMax}) {
+		return ""
+	}
+	return fmt.Sprintf(" (%g to %g %s)", r.Min, r.Max, feat.Unit)
+}
+
+// MatchesConstraints reports whether every known feature in c falls within
+// its bounds. A nil Min or Max leaves that side unbounded, and constraints on
+// unknown feature names are skipped.
+func MatchesConstraints(d types.Destination, c types.SearchConstraints) bool {
+	for key, fc := range c {
+		feat, ok := FeatureByKey(key)
+		if !ok {
+			continue
+		}
+		v := *feat.Field(&d.Features)
+		if fc.Min != nil && v < *fc.Min {
+			return false
+		}
+		if fc.Max != nil && v > *fc.Max {
+			return false
+		}
+	}
+	return true
+}
+
+// MaxGroupDepth caps how deeply constraint groups may nest
+const MaxGroupDepth = 5
+
+// ValidateConstraintGroup checks every node of g: each must set constraints,
+// all, or any, its constraints must be valid, and nesting may not exceed
+// MaxGroupDepth
+func ValidateConstraintGroup(g types.ConstraintGroup) error {
+	return validateGroup(g, 1)
+}
+
+func validateGroup(g types.ConstraintGroup, depth int) error {
+	if depth > MaxGroupDepth {
+		return fmt.Errorf("constraint groups may nest at most %d levels", MaxGroupDepth)
+	}
+	if len(g.Constraints) == 0 && len(g.All) == 0 && len(g.Any) == 0 {
+		return fmt.Errorf("constraint group must set constraints, all, or any")
+	}
+	if err := ValidateConstraints(g.Constraints); err != nil {
+		return err
+	}
+	for _, child := range slices.Concat(g.All, g.Any) {
+		if err := validateGroup(child, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MatchesGroup reports whether d satisfies the constraint tree rooted at g
+func MatchesGroup(d types.Destination, g types.ConstraintGroup) bool {
+	if !MatchesConstraints(d, g.Constraints) {
+		return false
+	}
+	for _, child := range g.All {
+		if !MatchesGroup(d, child) {
+			return false
+		}
+	}
+	if len(g.Any) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(g.Any, func(child types.ConstraintGroup) bool {
+		return MatchesGroup(d, child)
+	})
+}
+
+// FilterByGroup returns the destinations that satisfy g; a nil g keeps all
+func FilterByGroup(dests []types.Destination, g *types.ConstraintGroup) []types.Destination {
+	if g == nil {
+		return dests
+	}
+	out := make([]types.Destination, 0, len(dests))
+	for _, d := range dests {
+		if MatchesGroup(d, *g) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// FilterByConstraints returns the destinations that satisfy c
+func FilterByConstraints(dests []types.Destination, c types.SearchConstraints) []types.Destination {
+	out := make([]types.Destination, 0, len(dests))
+	for _, d := range dests {
+		if MatchesConstraints(d, c) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
@@ -0,0 +1,307 @@
+package ranking
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestValidateConstraints(t *testing.T) {
+	tests := []struct {
+		name        string
+		constraints types.SearchConstraints
+		// wantErr is a substring of the expected error, "" for none
+		wantErr string
+	}{
+		{"empty", types.SearchConstraints{}, ""},
+		{"valid", types.SearchConstraints{"skiing_score": {Min: new(0.5)}}, ""},
+		{"valid and unknown", types.SearchConstraints{
+			"skiing_score":  {Min: new(0.5)},
+			"llama_density": {Min: new(0.5)},
+		}, "unknown constraint features: llama_density"},
+		{"every unknown listed", types.SearchConstraints{
+			"zebra_count":   {Max: new(0.1)},
+			"llama_density": {Min: new(0.5)},
+		}, "unknown constraint features: llama_density, zebra_count"},
+		{"nature_ratio above 1", types.SearchConstraints{"nature_ratio": {Max: new(1.5)}},
+			"constraint on nature_ratio must be between 0 and 1, got 1.5"},
+		{"negative population", types.SearchConstraints{"population": {Min: new(-0.1)}},
+			"constraint on population must be between 0 and 1 (1000 to 4e+07 people), got -0.1"},
+		{"valid temperature", types.SearchConstraints{"avg_temp_c": {Min: new(0.5), Max: new(0.75)}}, ""},
+		{"temperature in degrees", types.SearchConstraints{"avg_temp_c": {Min: new(25.0)}},
+			"constraint on avg_temp_c must be between 0 and 1 (-15 to 45 °C), got 25"},
+		{"range endpoints", types.SearchConstraints{"hiking_score": {Min: new(0.0), Max: new(1.0)}}, ""},
+		{"NaN", types.SearchConstraints{"hiking_score": {Min: new(math.NaN())}},
+			"constraint on hiking_score must be between 0 and 1, got NaN"},
+		{"min above max", types.SearchConstraints{"skiing_score": {Min: new(0.8), Max: new(0.2)}},
+			"contradictory constraints on skiing_score"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateConstraints(tt.constraints)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("ValidateConstraints: %v", err)
+			case tt.wantErr != "" && err == nil:
+				t.Errorf("ValidateConstraints succeeded, want error containing %q", tt.wantErr)
+			case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+				t.Errorf("ValidateConstraints error = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFilterByConstraints(t *testing.T) {
+	dests := []types.Destination{
+		{ID: "alps", Features: types.DestinationFeatures{SkiingScore: 0.9, AvgTempC: 0.2}},
+		{ID: "coast", Features: types.DestinationFeatures{SkiingScore: 0.1, AvgTempC: 0.8}},
+		{ID: "hills", Features: types.DestinationFeatures{SkiingScore: 0.5, AvgTempC: 0.5}},
+	}
+	tests := []struct {
+		name        string
+		constraints types.SearchConstraints
+		want        []string
+	}{
+		{"none", types.SearchConstraints{}, []string{"alps", "coast", "hills"}},
+		{"min only", types.SearchConstraints{"skiing_score": {Min: new(0.5)}}, []string{"alps", "hills"}},
+		{"max only", types.SearchConstraints{"skiing_score": {Max: new(0.5)}}, []string{"coast", "hills"}},
+		{"both bounds inclusive", types.SearchConstraints{"skiing_score": {Min: new(0.5), Max: new(0.5)}}, []string{"hills"}},
+		{"every constraint must hold", types.SearchConstraints{
+			"skiing_score": {Min: new(0.4)},
+			"avg_temp_c":   {Min: new(0.4)},
+		}, []string{"hills"}},
+		{"unknown feature skipped", types.SearchConstraints{"llama_density": {Min: new(0.9)}}, []string{"alps", "coast", "hills"}},
+		{"nothing matches", types.SearchConstraints{"skiing_score": {Min: new(0.95)}}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, d := range FilterByConstraints(dests, tt.constraints) {
+				got = append(got, d.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("FilterByConstraints = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// beachOrSki is (coast_distance_km < 0.01 AND water_sports_score > 0.6) OR
+// (skiing_score > 0.7)
+var beachOrSki = types.ConstraintGroup{Any: []types.ConstraintGroup{
+	{All: []types.ConstraintGroup{
+		{Constraints: types.SearchConstraints{"coast_distance_km": {Max: new(0.01)}}},
+		{Constraints: types.SearchConstraints{"water_sports_score": {Min: new(0.6)}}},
+	}},
+	{Constraints: types.SearchConstraints{"skiing_score": {Min: new(0.7)}}},
+}}
+
+func TestMatchesGroup(t *testing.T) {
+	tests := []struct {
+		name                       string
+		coast, waterSports, skiing float64
+		want                       bool
+	}{
+		{"beach", 0.005, 0.8, 0, true},
+		{"ski resort", 0.6, 0, 0.9, true},
+		{"both", 0.005, 0.8, 0.9, true},
+		{"coastal without water sports", 0.005, 0.2, 0, false},
+		{"water sports inland", 0.4, 0.8, 0, false},
+		{"neither", 0.4, 0.2, 0.1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := types.Destination{Features: types.DestinationFeatures{
+				CoastDistanceKm: tt.coast, WaterSportsScore: tt.waterSports, SkiingScore: tt.skiing,
+			}}
+			if got := MatchesGroup(d, beachOrSki); got != tt.want {
+				t.Errorf("MatchesGroup = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesGroupCombinesLevels(t *testing.T) {
+	// A node's own constraints, All, and Any must all hold
+	g := beachOrSki
+	g.Constraints = types.SearchConstraints{"population": {Max: new(0.5)}}
+	d := types.Destination{Features: types.DestinationFeatures{SkiingScore: 0.9, Population: 0.8}}
+	if MatchesGroup(d, g) {
+		t.Error("a city matched a group capping population")
+	}
+	d.Features.Population = 0.2
+	if !MatchesGroup(d, g) {
+		t.Error("a small ski town didn't match")
+	}
+}
+
+func TestFilterByGroup(t *testing.T) {
+	dests := []types.Destination{
+		{ID: "beach", Features: types.DestinationFeatures{CoastDistanceKm: 0, WaterSportsScore: 0.9}},
+		{ID: "city", Features: types.DestinationFeatures{CoastDistanceKm: 0.3}},
+		{ID: "ski", Features: types.DestinationFeatures{CoastDistanceKm: 0.6, SkiingScore: 0.8}},
+	}
+	if got := ids(FilterByGroup(dests, &beachOrSki)); strings.Join(got, ",") != "beach,ski" {
+		t.Errorf("FilterByGroup = %v, want [beach ski]", got)
+	}
+	if got := FilterByGroup(dests, nil); len(got) != len(dests) {
+		t.Errorf("FilterByGroup(nil) kept %d, want %d", len(got), len(dests))
+	}
+}
+
+func TestValidateConstraintGroup(t *testing.T) {
+	deep := types.ConstraintGroup{Constraints: types.SearchConstraints{"skiing_score": {Min: new(0.5)}}}
+	for range MaxGroupDepth {
+		deep = types.ConstraintGroup{All: []types.ConstraintGroup{deep}}
+	}
+	tests := []struct {
+		name  string
+		group types.ConstraintGroup
+		// wantErr is a substring of the expected error, "" for none
+		wantErr string
+	}{
+		{"nested", beachOrSki, ""},
+		{"empty", types.ConstraintGroup{}, "constraint group must set constraints, all, or any"},
+		{"empty child", types.ConstraintGroup{Any: []types.ConstraintGroup{{}}}, "constraint group must set constraints, all, or any"},
+		{"invalid child", types.ConstraintGroup{All: []types.ConstraintGroup{
+			{Constraints: types.SearchConstraints{"llama_density": {Min: new(0.5)}}},
+		}}, "unknown constraint features: llama_density"},
+		{"too deep", deep, "constraint groups may nest at most 5 levels"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateConstraintGroup(tt.group)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("ValidateConstraintGroup: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("ValidateConstraintGroup error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// bounds reads a constraint's bounds, with nil as NaN so it can be compared
+func bounds(fc types.FeatureConstraint) [2]float64 {
+	out := [2]float64{math.NaN(), math.NaN()}
+	for i, b := range []*float64{fc.Min, fc.Max} {
+		if b != nil {
+			out[i] = *b
+		}
+	}
+	return out
+}
+
+func TestClampConstraints(t *testing.T) {
+	tests := []struct {
+		name        string
+		constraints types.SearchConstraints
+		want        types.SearchConstraints
+		wantClamped []string
+	}{
+		{
+			"min above the scale",
+			types.SearchConstraints{"nature_ratio": {Min: new(2.0)}},
+			types.SearchConstraints{"nature_ratio": {Min: new(1.0)}},
+			[]string{"nature_ratio"},
+		},
+		{
+			"both bounds outside",
+			types.SearchConstraints{"nature_ratio": {Min: new(-0.5), Max: new(3.0)}, "hiking_score": {Max: new(1.5)}},
+			types.SearchConstraints{"nature_ratio": {Min: new(0.0), Max: new(1.0)}, "hiking_score": {Max: new(1.0)}},
+			[]string{"hiking_score", "nature_ratio"},
+		},
+		{
+			"in range",
+			types.SearchConstraints{"nature_ratio": {Min: new(0.2), Max: new(0.8)}},
+			types.SearchConstraints{"nature_ratio": {Min: new(0.2), Max: new(0.8)}},
+			nil,
+		},
+		{
+			// Left for ValidateConstraints to reject
+			"unknown feature",
+			types.SearchConstraints{"llama_density": {Min: new(5.0)}},
+			types.SearchConstraints{"llama_density": {Min: new(5.0)}},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clamped := ClampConstraints(tt.constraints)
+			if !slices.Equal(clamped, tt.wantClamped) {
+				t.Errorf("clamped = %v, want %v", clamped, tt.wantClamped)
+			}
+			for key, want := range tt.want {
+				got, want := bounds(tt.constraints[key]), bounds(want)
+				if !sameFloat(got[0], want[0]) || !sameFloat(got[1], want[1]) {
+					t.Errorf("%s bounds = %v, want %v", key, got, want)
+				}
+			}
+		})
+	}
+
+	nan := types.SearchConstraints{"nature_ratio": {Min: new(math.NaN())}}
+	if clamped := ClampConstraints(nan); clamped != nil || !math.IsNaN(*nan["nature_ratio"].Min) {
+		t.Errorf("NaN bound clamped (%v), want it left for validation to reject", clamped)
+	}
+}
+
+// sameFloat is == that also treats two NaNs as equal
+func sameFloat(a, b float64) bool {
+	return a == b || (math.IsNaN(a) && math.IsNaN(b))
+}
+
+func TestClampConstraintGroup(t *testing.T) {
+	g := &types.ConstraintGroup{
+		Constraints: types.SearchConstraints{"nature_ratio": {Min: new(2.0)}},
+		Any: []types.ConstraintGroup{
+			{Constraints: types.SearchConstraints{"skiing_score": {Max: new(-1.0)}}},
+			{All: []types.ConstraintGroup{{Constraints: types.SearchConstraints{"hiking_score": {Min: new(1.2)}}}}},
+		},
+	}
+	clamped := ClampConstraintGroup(g)
+	if want := []string{"nature_ratio", "skiing_score", "hiking_score"}; !slices.Equal(clamped, want) {
+		t.Errorf("clamped = %v, want %v", clamped, want)
+	}
+	if *g.Constraints["nature_ratio"].Min != 1 || *g.Any[0].Constraints["skiing_score"].Max != 0 ||
+		*g.Any[1].All[0].Constraints["hiking_score"].Min != 1 {
+		t.Error("nested bounds not clamped in place")
+	}
+	if err := ValidateConstraintGroup(*g); err != nil {
+		t.Errorf("clamped group fails validation: %v", err)
+	}
+}
+
+func TestValidateFeatureVector(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       types.DestinationFeatures
+		wantErr string
+	}{
+		{"zero", types.DestinationFeatures{}, ""},
+		{"bounds", types.DestinationFeatures{AvgTempC: 1, SkiingScore: 0}, ""},
+		{"fixture", fixture(t, "zermatt").Features, ""},
+		{"above", types.DestinationFeatures{NatureRatio: 1.5}, "feature_vector nature_ratio must be between 0 and 1, got 1.5"},
+		{"below", types.DestinationFeatures{AvgTempC: -0.1}, "feature_vector avg_temp_c must be between 0 and 1 (-15 to 45 °C), got -0.1"},
+		{"NaN", types.DestinationFeatures{HikingScore: math.NaN()}, "feature_vector hiking_score must be between 0 and 1"},
+		// Monthly temperatures aren't part of the query vector
+		{"monthly ignored", types.DestinationFeatures{MonthlyTempC: [12]float64{5}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFeatureVector(tt.v)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateFeatureVector: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateFeatureVector error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package ranking
+
+import (
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// Diagnose explains how each feature constraint in c narrows dests, in
+// Features order. A constraint's Eliminated counts the destinations outside
+// its bounds; WouldAdd counts those failing only it, which loosening it
+// would bring back. The most limiting constraint has the highest WouldAdd,
+// then Eliminated. Constraints on unknown features are skipped.
+func Diagnose(dests []types.Destination, c types.SearchConstraints) types.DiagnoseResponse {
+	resp := types.DiagnoseResponse{
+		Candidates:  len(dests),
+		Constraints: []types.ConstraintDiagnosis{},
+	}
+
+	var feats []Feature
+	for _, feat := range Features {
+		if _, ok := c[feat.Key]; ok {
+			feats = append(feats, feat)
+			resp.Constraints = append(resp.Constraints, types.ConstraintDiagnosis{Feature: feat.Key})
+		}
+	}
+
+	failed := make([]int, 0, len(feats))
+	for _, d := range dests {
+		failed = failed[:0]
+		for i, feat := range feats {
+			if !MatchesConstraints(d, types.SearchConstraints{feat.Key: c[feat.Key]}) {
+				failed = append(failed, i)
+				resp.Constraints[i].Eliminated++
+			}
+		}
+		switch len(failed) {
+		case 0:
+			resp.Matching++
+		case 1:
+			resp.Constraints[failed[0]].WouldAdd++
+		}
+	}
+
+	best := -1
+	for i, cd := range resp.Constraints {
+		if cd.Eliminated > 0 && (best < 0 || limits(cd, resp.Constraints[best])) {
+			best = i
+		}
+	}
+	if best >= 0 {
+		resp.MostLimiting = resp.Constraints[best].Feature
+	}
+	return resp
+}
+
+// limits reports whether a is more limiting than b
+func limits(a, b types.ConstraintDiagnosis) bool {
+	if a.WouldAdd != b.WouldAdd {
+		return a.WouldAdd > b.WouldAdd
+	}
+	return a.Eliminated > b.Eliminated
+}
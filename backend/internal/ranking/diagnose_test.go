@@ -0,0 +1,74 @@
+package ranking
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestDiagnose(t *testing.T) {
+	tests := []struct {
+		name        string
+		constraints types.SearchConstraints
+		want        types.DiagnoseResponse
+	}{
+		{
+			// Only the ski resorts hike enough; tokyo is also too lively,
+			// so loosening hiking alone brings back just the beach towns
+			"one clear bottleneck",
+			types.SearchConstraints{
+				"avg_temp_c":        {Min: new(0.2)},
+				"hiking_score":      {Min: new(0.5)},
+				"nightlife_density": {Max: new(0.9)},
+			},
+			types.DiagnoseResponse{
+				Candidates: 5, Matching: 2, MostLimiting: "hiking_score",
+				Constraints: []types.ConstraintDiagnosis{
+					{Feature: "avg_temp_c"},
+					{Feature: "hiking_score", Eliminated: 3, WouldAdd: 2},
+					{Feature: "nightlife_density", Eliminated: 1},
+				},
+			},
+		},
+		{
+			"ties keep Features order",
+			types.SearchConstraints{
+				"water_sports_score": {Min: new(0.5)},
+				"skiing_score":       {Min: new(0.5)},
+			},
+			types.DiagnoseResponse{
+				Candidates: 5, MostLimiting: "skiing_score",
+				Constraints: []types.ConstraintDiagnosis{
+					{Feature: "skiing_score", Eliminated: 3, WouldAdd: 2},
+					{Feature: "water_sports_score", Eliminated: 3, WouldAdd: 2},
+				},
+			},
+		},
+		{
+			"nothing eliminated",
+			types.SearchConstraints{"avg_temp_c": {Min: new(0.1)}},
+			types.DiagnoseResponse{
+				Candidates: 5, Matching: 5,
+				Constraints: []types.ConstraintDiagnosis{{Feature: "avg_temp_c"}},
+			},
+		},
+		{
+			"unknown features skipped",
+			types.SearchConstraints{"llama_density": {Min: new(0.5)}},
+			types.DiagnoseResponse{Candidates: 5, Matching: 5, Constraints: []types.ConstraintDiagnosis{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Diagnose(vibeFixture, tt.constraints); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diagnose = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	got := Diagnose(nil, types.SearchConstraints{"hiking_score": {Min: new(0.5)}})
+	if got.Candidates != 0 || got.Matching != 0 || got.MostLimiting != "" || len(got.Constraints) != 1 {
+		t.Errorf("Diagnose(nil) = %+v, want one constraint eliminating nothing", got)
+	}
+}
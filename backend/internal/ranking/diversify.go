@@ -0,0 +1,62 @@
+package ranking
+
+import (
+	"fmt"
+	"math"
+)
+
+// DefaultDiversityLambda balances relevance against variety in Diversify
+const DefaultDiversityLambda = 0.7
+
+// DiversifyPool is how many top results Diversify re-ranks; the rest keep
+// their order after them
+const DiversifyPool = 50
+
+// ValidateLambda checks a diversity lambda is in [0, 1]
+func ValidateLambda(lambda float64) error {
+	if lambda < 0 || lambda > 1 || math.IsNaN(lambda) {
+		return fmt.Errorf("lambda must be between 0 and 1, got %g", lambda)
+	}
+	return nil
+}
+
+// Diversify reorders the top DiversifyPool of ranked results with maximal
+// marginal relevance: each pick maximizes lambda*score minus (1-lambda)
+// times its greatest feature similarity to the results already picked.
+// Lambda 1 keeps the ranking as is; lower values favor variety. Scores are
+// left unchanged, so diversified results are no longer sorted by score.
+func Diversify(results []Result, lambda float64) []Result {
+	pool := min(len(results), DiversifyPool)
+	candidates := make([]Result, pool)
+	copy(candidates, results[:pool])
+
+	vectors := make([][]float64, pool)
+	for i, res := range candidates {
+		vectors[i] = Vector(res.Destination.Features)
+	}
+	// maxSim[i] is candidate i's greatest similarity to a picked result
+	maxSim := make([]float64, pool)
+	picked := make([]bool, pool)
+
+	out := make([]Result, 0, len(results))
+	for range pool {
+		best, bestMMR := -1, math.Inf(-1)
+		for i := range candidates {
+			if picked[i] {
+				continue
+			}
+			// Ties go to the earlier, higher ranked candidate
+			if mmr := lambda*candidates[i].Score - (1-lambda)*maxSim[i]; mmr > bestMMR {
+				best, bestMMR = i, mmr
+			}
+		}
+		picked[best] = true
+		out = append(out, candidates[best])
+		for i := range candidates {
+			if !picked[i] {
+				maxSim[i] = max(maxSim[i], CosineSimilarity(vectors[i], vectors[best]))
+			}
+		}
+	}
+	return append(out, results[pool:]...)
+}
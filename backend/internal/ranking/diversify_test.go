@@ -0,0 +1,101 @@
+package ranking
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// clusteredResults ranks vibeFixture with both beach towns, then both ski
+// resorts, ahead of the city
+func clusteredResults(t *testing.T) []Result {
+	t.Helper()
+	continents := map[string]types.Continent{
+		"tamarindo": types.NorthAmerica, "tulum": types.NorthAmerica,
+		"zermatt": types.Europe, "verbier": types.Europe, "tokyo": types.Asia,
+	}
+	var out []Result
+	for i, id := range []string{"tamarindo", "tulum", "zermatt", "verbier", "tokyo"} {
+		d := fixture(t, id)
+		d.Continent = continents[id]
+		out = append(out, Result{Destination: d, Score: 0.95 - 0.02*float64(i)})
+	}
+	return out
+}
+
+// continentCount counts the distinct continents among the first n results
+func continentCount(results []Result, n int) int {
+	seen := map[types.Continent]bool{}
+	for _, res := range results[:n] {
+		seen[res.Destination.Continent] = true
+	}
+	return len(seen)
+}
+
+func TestDiversifySpansMoreContinents(t *testing.T) {
+	raw := clusteredResults(t)
+	diversified := Diversify(raw, 0.5)
+	for _, n := range []int{2, 3} {
+		if got, base := continentCount(diversified, n), continentCount(raw, n); got <= base {
+			t.Errorf("top %d spans %d continents diversified, %d raw; want more (order %v)", n, got, base, resultIDs(diversified))
+		}
+	}
+	if diversified[0].Destination.ID != "tamarindo" {
+		t.Errorf("first pick = %s, want the top result", diversified[0].Destination.ID)
+	}
+}
+
+func TestDiversifyKeepsEveryResult(t *testing.T) {
+	raw := clusteredResults(t)
+	got := resultIDs(Diversify(raw, 0.5))
+	want := resultIDs(raw)
+	slices.Sort(got)
+	slices.Sort(want)
+	if !slices.Equal(got, want) {
+		t.Errorf("Diversify returned %v, want a reordering of %v", got, want)
+	}
+}
+
+func TestDiversifyLambdaOneKeepsOrder(t *testing.T) {
+	raw := clusteredResults(t)
+	if got := resultIDs(Diversify(raw, 1)); !slices.Equal(got, resultIDs(raw)) {
+		t.Errorf("Diversify(lambda 1) = %v, want the original order", got)
+	}
+}
+
+func TestDiversifyLeavesTailAfterPool(t *testing.T) {
+	results := make([]Result, DiversifyPool+3)
+	for i := range results {
+		results[i] = Result{Destination: fixture(t, vibeFixture[i%len(vibeFixture)].ID), Score: 1 - float64(i)/100}
+	}
+	got := Diversify(results, 0.3)
+	for i := DiversifyPool; i < len(results); i++ {
+		if got[i].Score != results[i].Score {
+			t.Errorf("result %d has score %g, want %g left in place", i, got[i].Score, results[i].Score)
+		}
+	}
+	if len(Diversify(nil, 0.5)) != 0 {
+		t.Error("Diversify(nil) returned results")
+	}
+}
+
+func TestValidateLambda(t *testing.T) {
+	tests := []struct {
+		lambda  float64
+		wantErr bool
+	}{
+		{0, false},
+		{0.7, false},
+		{1, false},
+		{-0.1, true},
+		{1.5, true},
+		{math.NaN(), true},
+	}
+	for _, tt := range tests {
+		if err := ValidateLambda(tt.lambda); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateLambda(%g) = %v, want error %t", tt.lambda, err, tt.wantErr)
+		}
+	}
+}
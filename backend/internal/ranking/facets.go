@@ -0,0 +1,112 @@
+package ranking
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// Facets summarizes each feature's min, max, and mean across dests in a
+// single pass, ordered like Features. Features a destination is missing are
+// left out of that feature's summary, and a feature no destination has
+// reports zeros. An empty input yields an empty slice.
+func Facets(dests []types.Destination) []types.FeatureFacet {
+	if len(dests) == 0 {
+		return []types.FeatureFacet{}
+	}
+
+	facets := make([]types.FeatureFacet, len(Features))
+	for i, feat := range Features {
+		facets[i].Key = feat.Key
+	}
+	counts := make([]int, len(Features))
+	for _, d := range dests {
+		present := PresenceMask(d.MissingFeatures)
+		for i, v := range Vector(d.Features) {
+			if present != nil && present[i] == 0 {
+				continue
+			}
+			f := &facets[i]
+			if counts[i] == 0 || v < f.Min {
+				f.Min = v
+			}
+			if counts[i] == 0 || v > f.Max {
+				f.Max = v
+			}
+			f.Mean += v
+			counts[i]++
+		}
+	}
+	for i := range facets {
+		if counts[i] > 0 {
+			facets[i].Mean /= float64(counts[i])
+		}
+	}
+	return facets
+}
+
+// Coverage counts, in a single pass, the destinations in dests with a
+// non-zero value for each feature, ordered like Features. Sparse features
+// are data gaps that weaken ranking. An empty input reports 0%.
+func Coverage(dests []types.Destination) []types.FeatureCoverage {
+	coverage := make([]types.FeatureCoverage, len(Features))
+	for i, feat := range Features {
+		coverage[i].Key = feat.Key
+	}
+	for _, d := range dests {
+		for i, v := range Vector(d.Features) {
+			if v != 0 {
+				coverage[i].Populated++
+			}
+		}
+	}
+	if len(dests) > 0 {
+		for i := range coverage {
+			coverage[i].Percent = math.Round(1000*float64(coverage[i].Populated)/float64(len(dests))) / 10
+		}
+	}
+	return coverage
+}
+
+// ContinentStats counts the destinations on each continent and averages
+// their features in a single pass, sorted by continent name. Features a
+// destination is missing are left out of its continent's means, and
+// continents without destinations are omitted.
+func ContinentStats(dests []types.Destination) []types.ContinentStats {
+	type totals struct {
+		count  int
+		sums   []float64
+		counts []int
+	}
+	byContinent := map[types.Continent]*totals{}
+	for _, d := range dests {
+		t, ok := byContinent[d.Continent]
+		if !ok {
+			t = &totals{sums: make([]float64, len(Features)), counts: make([]int, len(Features))}
+			byContinent[d.Continent] = t
+		}
+		t.count++
+		present := PresenceMask(d.MissingFeatures)
+		for i, v := range Vector(d.Features) {
+			if present == nil || present[i] == 1 {
+				t.sums[i] += v
+				t.counts[i]++
+			}
+		}
+	}
+
+	stats := make([]types.ContinentStats, 0, len(byContinent))
+	for continent, t := range byContinent {
+		means := make(map[string]float64, len(Features))
+		for i, feat := range Features {
+			if t.counts[i] > 0 {
+				means[feat.Key] = t.sums[i] / float64(t.counts[i])
+			}
+		}
+		stats = append(stats, types.ContinentStats{Continent: continent, Count: t.count, FeatureMeans: means})
+	}
+	slices.SortFunc(stats, func(a, b types.ContinentStats) int { return cmp.Compare(a.Continent, b.Continent) })
+	return stats
+}
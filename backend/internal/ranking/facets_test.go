@@ -0,0 +1,163 @@
+package ranking
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestFacets(t *testing.T) {
+	dests := []types.Destination{
+		{ID: "a", Features: types.DestinationFeatures{AvgTempC: 0.2, SkiingScore: 0.9, Population: 0.5}},
+		{ID: "b", Features: types.DestinationFeatures{AvgTempC: 0.8, SkiingScore: 0.1, Population: 0.5}},
+		{ID: "c", Features: types.DestinationFeatures{AvgTempC: 0.5, SkiingScore: 0.2, Population: 0.5}},
+	}
+	facets := Facets(dests)
+	if len(facets) != len(Features) {
+		t.Fatalf("got %d facets, want one per feature (%d)", len(facets), len(Features))
+	}
+	byKey := map[string]types.FeatureFacet{}
+	for i, f := range facets {
+		if f.Key != Features[i].Key {
+			t.Errorf("facet %d = %s, want %s in Features order", i, f.Key, Features[i].Key)
+		}
+		byKey[f.Key] = f
+	}
+
+	tests := []struct {
+		key            string
+		min, max, mean float64
+	}{
+		{"avg_temp_c", 0.2, 0.8, 0.5},
+		{"skiing_score", 0.1, 0.9, 0.4},
+		{"population", 0.5, 0.5, 0.5},
+		{"hiking_score", 0, 0, 0},
+	}
+	for _, tt := range tests {
+		f := byKey[tt.key]
+		if math.Abs(f.Min-tt.min) > 1e-9 || math.Abs(f.Max-tt.max) > 1e-9 || math.Abs(f.Mean-tt.mean) > 1e-9 {
+			t.Errorf("%s = min %v, max %v, mean %v; want %v, %v, %v", tt.key, f.Min, f.Max, f.Mean, tt.min, tt.max, tt.mean)
+		}
+	}
+
+	if got := Facets(nil); got == nil || len(got) != 0 {
+		t.Errorf("Facets(nil) = %#v, want an empty slice", got)
+	}
+}
+
+func TestFacetsSkipMissing(t *testing.T) {
+	// b has no skiing data, so its 0 must not drag down skiing's min or mean
+	dests := []types.Destination{
+		{ID: "a", Features: types.DestinationFeatures{AvgTempC: 0.2, SkiingScore: 0.9}},
+		{ID: "b", Features: types.DestinationFeatures{AvgTempC: 0.8}, MissingFeatures: []string{"skiing_score"}},
+		{ID: "c", Features: types.DestinationFeatures{AvgTempC: 0.5, SkiingScore: 0.3}},
+		{ID: "d", Features: types.DestinationFeatures{AvgTempC: 0.5}, MissingFeatures: []string{"hiking_score"}},
+	}
+	hikingMissing := []types.Destination{dests[3]}
+
+	tests := []struct {
+		name           string
+		dests          []types.Destination
+		key            string
+		min, max, mean float64
+	}{
+		{"present everywhere", dests, "avg_temp_c", 0.2, 0.8, 0.5},
+		{"missing on one", dests[:3], "skiing_score", 0.3, 0.9, 0.6},
+		{"zero still counts", dests, "skiing_score", 0, 0.9, 0.4},
+		{"missing on all", hikingMissing, "hiking_score", 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := slices.IndexFunc(Features, func(f Feature) bool { return f.Key == tt.key })
+			f := Facets(tt.dests)[i]
+			if math.Abs(f.Min-tt.min) > 1e-9 || math.Abs(f.Max-tt.max) > 1e-9 || math.Abs(f.Mean-tt.mean) > 1e-9 {
+				t.Errorf("%s = min %v, max %v, mean %v; want %v, %v, %v", tt.key, f.Min, f.Max, f.Mean, tt.min, tt.max, tt.mean)
+			}
+		})
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	// Zero counts as missing, so b lacks skiing and c lacks skiing and hiking
+	dests := []types.Destination{
+		{ID: "a", Features: types.DestinationFeatures{AvgTempC: 0.2, SkiingScore: 0.9, HikingScore: 0.4}},
+		{ID: "b", Features: types.DestinationFeatures{AvgTempC: 0.8, HikingScore: 0.1}},
+		{ID: "c", Features: types.DestinationFeatures{AvgTempC: 0.5}},
+	}
+	coverage := Coverage(dests)
+	if len(coverage) != len(Features) {
+		t.Fatalf("got %d features, want one per feature (%d)", len(coverage), len(Features))
+	}
+	byKey := map[string]types.FeatureCoverage{}
+	for i, c := range coverage {
+		if c.Key != Features[i].Key {
+			t.Errorf("feature %d = %s, want %s in Features order", i, c.Key, Features[i].Key)
+		}
+		byKey[c.Key] = c
+	}
+
+	tests := []struct {
+		key       string
+		populated int
+		percent   float64
+	}{
+		{"avg_temp_c", 3, 100},
+		{"hiking_score", 2, 66.7},
+		{"skiing_score", 1, 33.3},
+		{"population", 0, 0},
+	}
+	for _, tt := range tests {
+		if c := byKey[tt.key]; c.Populated != tt.populated || c.Percent != tt.percent {
+			t.Errorf("%s = %d (%v%%), want %d (%v%%)", tt.key, c.Populated, c.Percent, tt.populated, tt.percent)
+		}
+	}
+
+	for _, c := range Coverage(nil) {
+		if c.Populated != 0 || c.Percent != 0 {
+			t.Errorf("Coverage(nil) %s = %d (%v%%), want 0", c.Key, c.Populated, c.Percent)
+		}
+	}
+}
+
+func TestContinentStats(t *testing.T) {
+	dests := []types.Destination{
+		{ID: "lisbon", Continent: types.Europe, Features: types.DestinationFeatures{AvgTempC: 0.6, SkiingScore: 0.1}},
+		{ID: "zermatt", Continent: types.Europe, Features: types.DestinationFeatures{AvgTempC: 0.2, SkiingScore: 0.9}},
+		// A missing feature is left out of the mean rather than counted as 0
+		{ID: "oslo", Continent: types.Europe, Features: types.DestinationFeatures{AvgTempC: 0.4}, MissingFeatures: []string{"skiing_score"}},
+		{ID: "tokyo", Continent: types.Asia, Features: types.DestinationFeatures{AvgTempC: 0.5, Population: 1}},
+	}
+	stats := ContinentStats(dests)
+
+	tests := []struct {
+		continent types.Continent
+		count     int
+		means     map[string]float64
+	}{
+		{types.Asia, 1, map[string]float64{"avg_temp_c": 0.5, "population": 1, "skiing_score": 0}},
+		{types.Europe, 3, map[string]float64{"avg_temp_c": 0.4, "skiing_score": 0.5, "population": 0}},
+	}
+	if len(stats) != len(tests) {
+		t.Fatalf("got %d continents, want %d without empty ones: %+v", len(stats), len(tests), stats)
+	}
+	for i, tt := range tests {
+		got := stats[i]
+		if got.Continent != tt.continent || got.Count != tt.count {
+			t.Errorf("continent %d = %s with %d, want %s with %d, by name", i, got.Continent, got.Count, tt.continent, tt.count)
+		}
+		for key, want := range tt.means {
+			if math.Abs(got.FeatureMeans[key]-want) > 1e-9 {
+				t.Errorf("%s %s mean = %v, want %v", tt.continent, key, got.FeatureMeans[key], want)
+			}
+		}
+		if len(got.FeatureMeans) != len(Features) {
+			t.Errorf("%s has %d means, want one per feature (%d)", tt.continent, len(got.FeatureMeans), len(Features))
+		}
+	}
+
+	if got := ContinentStats(nil); got == nil || len(got) != 0 {
+		t.Errorf("ContinentStats(nil) = %#v, want an empty slice", got)
+	}
+}
@@ -0,0 +1,148 @@
+package ranking
+
+import (
+	"slices"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// Temperature scale used by ingestion to normalize avg_temp_c to [0, 1]
+const (
+	MinTempC = -15.0
+	MaxTempC = 45.0
+)
+
+// CelsiusFromNormalized converts a normalized avg_temp_c value back to °C
+func CelsiusFromNormalized(v float64) float64 {
+	return MinTempC + v*(MaxTempC-MinTempC)
+}
+
+// Feature describes one entry of DestinationFeatures: its JSON key, an
+// accessor for its field, how it's scaled and weighed, and display metadata
+// for clients
+type Feature struct {
+	Key   string
+	Field func(f *types.DestinationFeatures) *float64
+
+	// Range is the source range raw values are normalized from
+	Range Range
+	// DefaultWeight is the feature's weight when a search doesn't set one
+	DefaultWeight float64
+	// Aliases are other names clients may use for the feature in
+	// constraints, weights, and avoid terms
+	Aliases []string
+
+	// Label is a human-readable name for the feature
+	Label string
+	// Unit is the unit of the underlying measurement before normalization
+	Unit string
+	// HigherIsMore is false when a higher value means less of the labelled
+	// quality (e.g. a larger coast distance is less coastal)
+	HigherIsMore bool
+}
+
+// FeatureRegistry is the single list of features that take part in
+// scoring, in vector order
+type FeatureRegistry []Feature
+
+// Features lists every feature in a fixed order, defining the layout of
+// feature vectors used for scoring. Scoring, normalization, validation,
+// facets, and the /api/features endpoint all read it, so adding a field to
+// DestinationFeatures only needs an entry here. Ranges match the scales used
+// by the Python ingestion (see docs/SCHEMA.md).
+var Features = FeatureRegistry{
+	{Key: "avg_temp_c", Label: "Warmth", Unit: "°C", HigherIsMore: true,
+		Range: Range{Min: MinTempC, Max: MaxTempC}, DefaultWeight: 1, Aliases: []string{"warmth", "temperature"},
+		Field: func(f *types.DestinationFeatures) *float64 { return &f.AvgTempC }},
+	{Key: "tourism_density", Label: "Tourist crowds", Unit: "POIs/km²", HigherIsMore: true,
+		Range: Range{Min: 0, Max: 100, Log: true}, DefaultWeight: 1, Aliases: []string{"crowds", "tourism"},
+		Field: func(f *types.DestinationFeatures) *float64 { return &f.TourismDensity }},
+	{Key: "wikipedia_pageviews", Label: "Popularity", Unit: "views/month", HigherIsMore: true,
+		Range: Range{Min: 3_000, Max: 1_200_000, Log: true}, DefaultWeight: 1, Aliases: []string{"popularity"},
+		Field: func(f *types.DestinationFeatures) *float64 { return &f.WikipediaPageviews }},
+	{Key: "accommodation_density", Label: "Places to stay", Unit: "lodgings/km²", HigherIsMore: true,
+		Range: Range{Min: 0, Max: 50, Log: true}, DefaultWeight: 1, Aliases: []string{"lodging"},
+		Field: func(f *types.DestinationFeatures) *float64 { return &f.AccommodationDensity }},
+	{Key: "population", Label: "Population", Unit: "people", HigherIsMore: true,
+		Range: Range{Min: 1_000, Max: 40_000_000, Log: true}, DefaultWeight: 1, Aliases: []string{"size"},
+		Field: func(f *types.DestinationFeatures) *float64 { return &f.Population }},
+	{Key: "coast_distance_km", Label: "Coastal", Unit: "km", HigherIsMore: false,
+		Range: Range{Min: 0, Max: 500}, DefaultWeight: 1, Aliases: []string{"coast_distance"},
+		Field: func(f *types.DestinationFeatures) *float64 { return &f.CoastDistanceKm }},
+	{Key: "nature_ratio", Label: "Nature", Unit: "ratio", HigherIsMore: true,
+		Range: Range{Min: 0, Max: 1}, DefaultWeight: 1, Aliases: []string{"nature", "greenery"},
+		Field: func(f *types.DestinationFeatures) *float64 { return &f.NatureRatio }},
+	{Key: "elevation", Label: "Elevation", Unit: "m", HigherIsMore: true,
+		Range: Range{Min: 0, Max: 5_000}, DefaultWeight: 1, Aliases: []string{"altitude"},
+		Field: func(f *types.DestinationFeatures) *float64 { return &f.Elevation }},
+	{Key: "skiing_score", Label: "Skiing", Unit: "score", HigherIsMore: true,
+		Range: Range{Min: 0, Max: 1}, DefaultWeight: 1, Aliases: []string{"skiing"},
+		Field: func(f *types.DestinationFeatures) *float64 { return &f.SkiingScore }},
+	{Key: "water_sports_score", Label: "Water sports", Unit: "score", HigherIsMore: true,
+		Range: Range{Min: 0, Max: 1}, DefaultWeight: 1, Aliases: []string{"water_sports"},
+		Field: func(f *types.DestinationFeatures) *float64 { return &f.WaterSportsScore }},
+	{Key: "hiking_score", Label: "Hiking", Unit: "score", HigherIsMore: true,
+		Range: Range{Min: 0, Max: 1}, DefaultWeight: 1, Aliases: []string{"hiking"},
+		Field: func(f *types.DestinationFeatures) *float64 { return &f.HikingScore }},
+	{Key: "wildlife_score", Label: "Wildlife", Unit: "score", HigherIsMore: true,
+		Range: Range{Min: 0, Max: 1}, DefaultWeight: 1, Aliases: []string{"wildlife"},
+		Field: func(f *types.DestinationFeatures) *float64 { return &f.WildlifeScore }},
+	{Key: "nightlife_density", Label: "Nightlife", Unit: "venues/km²", HigherIsMore: true,
+		Range: Range{Min: 0, Max: 50, Log: true}, DefaultWeight: 1, Aliases: []string{"nightlife"},
+		Field: func(f *types.DestinationFeatures) *float64 { return &f.NightlifeDensity }},
+	{Key: "development_level", Label: "Development", Unit: "index", HigherIsMore: true,
+		Range: Range{Min: 0, Max: 1}, DefaultWeight: 1, Aliases: []string{"development"},
+		Field: func(f *types.DestinationFeatures) *float64 { return &f.DevelopmentLevel }},
+	{Key: "gdp_per_capita", Label: "Affluence", Unit: "percentile", HigherIsMore: true,
+		Range: Range{Min: 0, Max: 1}, DefaultWeight: 1, Aliases: []string{"affluence"},
+		Field: func(f *types.DestinationFeatures) *float64 { return &f.GDPPerCapita }},
+}
+
+// Vector flattens features into a slice ordered by Features
+func Vector(f types.DestinationFeatures) []float64 {
+	v := make([]float64, len(Features))
+	for i, feat := range Features {
+		v[i] = *feat.Field(&f)
+	}
+	return v
+}
+
+// featuresByKey indexes Features by JSON key
+var featuresByKey = func() map[string]Feature {
+	m := make(map[string]Feature, len(Features))
+	for _, feat := range Features {
+		m[feat.Key] = feat
+	}
+	return m
+}()
+
+// FeatureRanges gives the source range of each feature, keyed like Features
+var FeatureRanges = func() map[string]Range {
+	m := make(map[string]Range, len(Features))
+	for _, feat := range Features {
+		m[feat.Key] = feat.Range
+	}
+	return m
+}()
+
+// PresenceMask returns a per-feature vector ordered like Features holding 0
+// for the missing keys and 1 elsewhere, or nil when nothing is missing.
+// Unknown keys are ignored.
+func PresenceMask(missing []string) []float64 {
+	if len(missing) == 0 {
+		return nil
+	}
+	mask := make([]float64, len(Features))
+	for i, feat := range Features {
+		if !slices.Contains(missing, feat.Key) {
+			mask[i] = 1
+		}
+	}
+	return mask
+}
+
+// FeatureByKey looks up a feature by its JSON key
+func FeatureByKey(key string) (Feature, bool) {
+	feat, ok := featuresByKey[key]
+	return feat, ok
+}
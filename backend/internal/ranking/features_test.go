@@ -0,0 +1,117 @@
+package ranking
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// TestFeaturesCoverDestinationFeatures checks that every scalar field of
+// DestinationFeatures has a registry entry, so a new field can't silently
+// sit out of scoring. MonthlyTempC is a series on avg_temp_c's scale, not a
+// feature of its own.
+func TestFeaturesCoverDestinationFeatures(t *testing.T) {
+	typ := reflect.TypeFor[types.DestinationFeatures]()
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if field.Type.Kind() != reflect.Float64 {
+			continue
+		}
+		key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if _, ok := FeatureByKey(key); !ok {
+			t.Errorf("DestinationFeatures.%s (%q) is missing from Features", field.Name, key)
+		}
+	}
+}
+
+// TestFeatureFieldsMatchKeys checks that each entry's Field accessor points
+// at the struct field tagged with its key
+func TestFeatureFieldsMatchKeys(t *testing.T) {
+	typ := reflect.TypeFor[types.DestinationFeatures]()
+	for _, feat := range Features {
+		var f types.DestinationFeatures
+		*feat.Field(&f) = 0.5
+
+		v := reflect.ValueOf(f)
+		for i := range typ.NumField() {
+			key, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+			if typ.Field(i).Type.Kind() != reflect.Float64 {
+				continue
+			}
+			got := v.Field(i).Float()
+			if key == feat.Key && got != 0.5 {
+				t.Errorf("%s: Field doesn't set DestinationFeatures.%s", feat.Key, typ.Field(i).Name)
+			}
+			if key != feat.Key && got != 0 {
+				t.Errorf("%s: Field sets DestinationFeatures.%s (%q)", feat.Key, typ.Field(i).Name, key)
+			}
+		}
+	}
+}
+
+func TestFeatureRegistryEntries(t *testing.T) {
+	seen := map[string]bool{}
+	for _, feat := range Features {
+		if seen[feat.Key] {
+			t.Errorf("%s: listed more than once", feat.Key)
+		}
+		seen[feat.Key] = true
+		if feat.Label == "" || feat.Unit == "" {
+			t.Errorf("%s: label and unit must be set, got %q and %q", feat.Key, feat.Label, feat.Unit)
+		}
+		if !(feat.Range.Min < feat.Range.Max) {
+			t.Errorf("%s: range min %g must be below max %g", feat.Key, feat.Range.Min, feat.Range.Max)
+		}
+		if feat.Range.Log && feat.Range.Min < 0 {
+			t.Errorf("%s: log range must not start below 0, got %g", feat.Key, feat.Range.Min)
+		}
+		if feat.DefaultWeight < 0 {
+			t.Errorf("%s: default weight must not be negative, got %g", feat.Key, feat.DefaultWeight)
+		}
+	}
+}
+
+func TestFeatureRangesMatchSchema(t *testing.T) {
+	// Features docs/SCHEMA.md describes as already normalized upstream
+	for _, key := range []string{"nature_ratio", "skiing_score", "water_sports_score", "hiking_score", "wildlife_score", "development_level", "gdp_per_capita"} {
+		feat, ok := FeatureByKey(key)
+		if !ok {
+			t.Fatalf("%s: not in Features", key)
+		}
+		if want := (Range{Min: 0, Max: 1}); feat.Range != want {
+			t.Errorf("%s: range = %+v, want %+v", key, feat.Range, want)
+		}
+	}
+}
+
+func TestFeatureRangesFollowRegistry(t *testing.T) {
+	if len(FeatureRanges) != len(Features) {
+		t.Errorf("FeatureRanges has %d entries, want %d", len(FeatureRanges), len(Features))
+	}
+	for _, feat := range Features {
+		if r, ok := FeatureRanges[feat.Key]; !ok || r != feat.Range {
+			t.Errorf("FeatureRanges[%s] = %+v, want the registry's %+v", feat.Key, r, feat.Range)
+		}
+	}
+}
+
+func TestPresenceMask(t *testing.T) {
+	if PresenceMask(nil) != nil {
+		t.Error("PresenceMask(nil) isn't nil")
+	}
+	mask := PresenceMask([]string{"skiing_score", "llama_density"})
+	if len(mask) != len(Features) {
+		t.Fatalf("mask has %d entries, want %d", len(mask), len(Features))
+	}
+	for i, feat := range Features {
+		want := 1.0
+		if feat.Key == "skiing_score" {
+			want = 0
+		}
+		if mask[i] != want {
+			t.Errorf("mask[%s] = %g, want %g", feat.Key, mask[i], want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package ranking
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// ApplyFilters returns the destinations matching every set geographic filter.
+// Country and region comparisons ignore case, and destinations without a
+// region never match a region filter.
+func ApplyFilters(dests []types.Destination, f *types.GeographicFilters) []types.Destination {
+	if f == nil {
+		return dests
+	}
+
+	out := make([]types.Destination, 0, len(dests))
+	for _, d := range dests {
+		if matchesFilters(d, f) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// ExcludeIDs returns the destinations whose IDs aren't in ids
+func ExcludeIDs(dests []types.Destination, ids []string) []types.Destination {
+	if len(ids) == 0 {
+		return dests
+	}
+
+	excluded := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		excluded[id] = true
+	}
+	out := make([]types.Destination, 0, len(dests))
+	for _, d := range dests {
+		if !excluded[d.ID] {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// FilterByTags returns the destinations carrying every tag in allTags and,
+// when anyTags is non-empty, at least one of anyTags. Tags must already be
+// normalized.
+func FilterByTags(dests []types.Destination, allTags, anyTags []string) []types.Destination {
+	if len(allTags) == 0 && len(anyTags) == 0 {
+		return dests
+	}
+
+	out := make([]types.Destination, 0, len(dests))
+	for _, d := range dests {
+		if hasAllTags(d.Tags, allTags) && (len(anyTags) == 0 || hasAnyTag(d.Tags, anyTags)) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+func hasAllTags(tags, want []string) bool {
+	for _, tag := range want {
+		if !slices.Contains(tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasAnyTag(tags, want []string) bool {
+	for _, tag := range want {
+		if slices.Contains(tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateFilters reports filters that can't be applied as given
+func ValidateFilters(f *types.GeographicFilters) error {
+	if f == nil {
+		return nil
+	}
+	if f.Continent != nil && !f.Continent.IsValid() {
+		return fmt.Errorf("unknown continent %q, must be one of %s", *f.Continent, continentList())
+	}
+	if f.Near != nil {
+		if err := f.Near.Validate(); err != nil {
+			return fmt.Errorf("near: %w", err)
+		}
+		if !(f.RadiusKm > 0 && isFinite(f.RadiusKm)) {
+			return errors.New("radius_km must be a positive number when near is set")
+		}
+	}
+	if b := f.BoundingBox; b != nil {
+		for _, corner := range []types.Location{{Lat: b.MinLat, Lon: b.MinLon}, {Lat: b.MaxLat, Lon: b.MaxLon}} {
+			if err := corner.Validate(); err != nil {
+				return fmt.Errorf("bbox: %w", err)
+			}
+		}
+		if b.MinLat > b.MaxLat {
+			return errors.New("bbox min_lat must not be greater than max_lat")
+		}
+	}
+	return nil
+}
+
+func matchesFilters(d types.Destination, f *types.GeographicFilters) bool {
+	if f.Continent != nil && d.Continent != *f.Continent {
+		return false
+	}
+	if f.Country != nil && !strings.EqualFold(d.Country, *f.Country) {
+		return false
+	}
+	if f.Region != nil && (d.Region == nil || !strings.EqualFold(*d.Region, *f.Region)) {
+		return false
+	}
+	if f.Near != nil && HaversineKm(*f.Near, d.Location) > f.RadiusKm {
+		return false
+	}
+	if f.BoundingBox != nil && !InBoundingBox(d.Location, *f.BoundingBox) {
+		return false
+	}
+	return true
+}
+
+// continentList formats the known continents for error messages
+func continentList() string {
+	names := make([]string, 0, len(types.AllContinents()))
+	for _, c := range types.AllContinents() {
+		names = append(names, string(c))
+	}
+	return strings.Join(names, ", ")
+}
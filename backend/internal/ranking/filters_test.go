@@ -0,0 +1,69 @@
+package ranking
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// placeFixture is a handful of destinations spread across continents
+var placeFixture = []types.Destination{
+	{ID: "lisbon", Country: "Portugal", Continent: types.Europe, Region: new("Lisbon"), Location: types.Location{Lat: 38.72, Lon: -9.14}},
+	{ID: "porto", Country: "Portugal", Continent: types.Europe, Region: new("Norte"), Location: types.Location{Lat: 41.15, Lon: -8.61}},
+	{ID: "algarve", Country: "Portugal", Continent: types.Europe, Location: types.Location{Lat: 37.02, Lon: -7.93}},
+	{ID: "kyoto", Country: "Japan", Continent: types.Asia, Region: new("Kansai"), Location: types.Location{Lat: 35.01, Lon: 135.77}},
+	{ID: "cusco", Country: "Peru", Continent: types.SouthAmerica, Region: new("Cusco"), Location: types.Location{Lat: -13.53, Lon: -71.97}},
+	{ID: "suva", Country: "Fiji", Continent: types.Oceania, Location: types.Location{Lat: -18.14, Lon: 178.44}},
+	{ID: "apia", Country: "Samoa", Continent: types.Oceania, Location: types.Location{Lat: -13.83, Lon: -171.76}},
+}
+
+// ids lists the IDs of dests in order
+func ids(dests []types.Destination) []string {
+	out := make([]string, len(dests))
+	for i, d := range dests {
+		out[i] = d.ID
+	}
+	return out
+}
+
+func TestApplyFilters(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters *types.GeographicFilters
+		want    []string
+	}{
+		{"nil", nil, ids(placeFixture)},
+		{"continent", &types.GeographicFilters{Continent: new(types.Oceania)}, []string{"suva", "apia"}},
+		{"continent with no matches", &types.GeographicFilters{Continent: new(types.Africa)}, []string{}},
+		{"country ignores case", &types.GeographicFilters{Country: new("portugal")}, []string{"lisbon", "porto", "algarve"}},
+		{"country and region", &types.GeographicFilters{Country: new("Portugal"), Region: new("norte")}, []string{"porto"}},
+		{"region from another country", &types.GeographicFilters{Country: new("Portugal"), Region: new("Kansai")}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(ApplyFilters(placeFixture, tt.filters)); !slices.Equal(got, tt.want) {
+				t.Errorf("ApplyFilters = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExcludeIDs(t *testing.T) {
+	tests := []struct {
+		name string
+		ids  []string
+		want []string
+	}{
+		{"none", nil, []string{"lisbon", "porto", "algarve", "kyoto", "cusco", "suva", "apia"}},
+		{"some", []string{"porto", "suva"}, []string{"lisbon", "algarve", "kyoto", "cusco", "apia"}},
+		{"unknown", []string{"atlantis"}, []string{"lisbon", "porto", "algarve", "kyoto", "cusco", "suva", "apia"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(ExcludeIDs(placeFixture, tt.ids)); !slices.Equal(got, tt.want) {
+				t.Errorf("ExcludeIDs(%v) = %v, want %v", tt.ids, got, tt.want)
+			}
+		})
+	}
+}
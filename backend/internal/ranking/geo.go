@@ -0,0 +1,62 @@
+package ranking
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// earthRadiusKm is the mean Earth radius used for great-circle distances
+const earthRadiusKm = 6371.0
+
+// HaversineKm returns the great-circle distance between two points in km
+func HaversineKm(a, b types.Location) float64 {
+	lat1 := a.Lat * math.Pi / 180
+	lat2 := b.Lat * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	// Rounding can push h fractionally outside [0, 1] for antipodal or
+	// polar points, which would make the square roots below NaN
+	h = math.Min(math.Max(h, 0), 1)
+
+	return 2 * earthRadiusKm * math.Atan2(math.Sqrt(h), math.Sqrt(1-h))
+}
+
+// InBoundingBox reports whether loc falls inside the box, wrapping longitude
+// when the box crosses the antimeridian
+func InBoundingBox(loc types.Location, b types.BoundingBox) bool {
+	if loc.Lat < b.MinLat || loc.Lat > b.MaxLat {
+		return false
+	}
+	if b.MinLon <= b.MaxLon {
+		return loc.Lon >= b.MinLon && loc.Lon <= b.MaxLon
+	}
+	return loc.Lon >= b.MinLon || loc.Lon <= b.MaxLon
+}
+
+// Neighbor is a destination and its distance from a point
+type Neighbor struct {
+	Destination types.Destination
+	DistanceKm  float64
+}
+
+// Nearest returns the n destinations closest to origin by great-circle
+// distance, nearest first. Equidistant destinations are ordered by ID.
+func Nearest(origin types.Location, dests []types.Destination, n int) []Neighbor {
+	neighbors := make([]Neighbor, len(dests))
+	for i, d := range dests {
+		neighbors[i] = Neighbor{Destination: d, DistanceKm: HaversineKm(origin, d.Location)}
+	}
+	slices.SortFunc(neighbors, func(a, b Neighbor) int {
+		if c := cmp.Compare(a.DistanceKm, b.DistanceKm); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Destination.ID, b.Destination.ID)
+	})
+	return neighbors[:min(n, len(neighbors))]
+}
@@ -0,0 +1,198 @@
+package ranking
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestHaversineKm(t *testing.T) {
+	tests := []struct {
+		name   string
+		a, b   types.Location
+		wantKm float64
+	}{
+		{"same point", types.Location{Lat: 38.72, Lon: -9.14}, types.Location{Lat: 38.72, Lon: -9.14}, 0},
+		{"lisbon to tokyo", types.Location{Lat: 38.72, Lon: -9.14}, types.Location{Lat: 35.68, Lon: 139.69}, 11140},
+		// One degree of longitude at the equator, either side of the antimeridian
+		{"across the antimeridian", types.Location{Lat: 0, Lon: 179.5}, types.Location{Lat: 0, Lon: -179.5}, 111.2},
+		{"fiji to samoa", types.Location{Lat: -18.14, Lon: 178.44}, types.Location{Lat: -13.83, Lon: -171.76}, 1150},
+		{"pole to pole", types.Location{Lat: 90, Lon: 0}, types.Location{Lat: -90, Lon: 0}, math.Pi * earthRadiusKm},
+		{"north pole at any longitude", types.Location{Lat: 90, Lon: 0}, types.Location{Lat: 90, Lon: 120}, 0},
+		{"antipodes", types.Location{Lat: 0, Lon: 0}, types.Location{Lat: 0, Lon: 180}, math.Pi * earthRadiusKm},
+		{"near the pole", types.Location{Lat: 89.9, Lon: 0}, types.Location{Lat: 89.9, Lon: 180}, 22.2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := HaversineKm(tt.a, tt.b)
+			if math.IsNaN(got) {
+				t.Fatal("distance is NaN")
+			}
+			// Within 1% or 1km, whichever is larger
+			if tol := max(tt.wantKm*0.01, 1); math.Abs(got-tt.wantKm) > tol {
+				t.Errorf("HaversineKm = %.1f, want %.1f", got, tt.wantKm)
+			}
+			if back := HaversineKm(tt.b, tt.a); math.Abs(back-got) > 1e-9 {
+				t.Errorf("distance isn't symmetric: %g there, %g back", got, back)
+			}
+		})
+	}
+}
+
+func TestRadiusFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		near     types.Location
+		radiusKm float64
+		want     []string
+	}{
+		{"around lisbon", types.Location{Lat: 38.72, Lon: -9.14}, 300, []string{"lisbon", "porto", "algarve"}},
+		{"tight radius", types.Location{Lat: 38.72, Lon: -9.14}, 10, []string{"lisbon"}},
+		{"across the antimeridian", types.Location{Lat: -16, Lon: 180}, 1000, []string{"suva", "apia"}},
+		{"north pole", types.Location{Lat: 90, Lon: 0}, 1000, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &types.GeographicFilters{Near: &tt.near, RadiusKm: tt.radiusKm}
+			if err := ValidateFilters(f); err != nil {
+				t.Fatalf("ValidateFilters: %v", err)
+			}
+			got := ids(ApplyFilters(placeFixture, f))
+			if !sameIDs(got, tt.want) {
+				t.Errorf("ApplyFilters = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRadius(t *testing.T) {
+	near := &types.Location{Lat: 38.72, Lon: -9.14}
+	for _, radius := range []float64{0, -5, math.NaN(), math.Inf(1)} {
+		if err := ValidateFilters(&types.GeographicFilters{Near: near, RadiusKm: radius}); err == nil {
+			t.Errorf("ValidateFilters accepted radius_km %g", radius)
+		}
+	}
+	if err := ValidateFilters(&types.GeographicFilters{Near: &types.Location{Lat: 91}, RadiusKm: 10}); err == nil {
+		t.Error("ValidateFilters accepted lat 91")
+	}
+}
+
+// sameIDs reports whether a and b hold the same IDs, in any order
+func sameIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[string]int, len(a))
+	for _, id := range a {
+		seen[id]++
+	}
+	for _, id := range b {
+		seen[id]--
+		if seen[id] < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestBoundingBoxFilter(t *testing.T) {
+	tests := []struct {
+		name string
+		box  types.BoundingBox
+		want []string
+	}{
+		{"iberia", types.BoundingBox{MinLat: 36, MinLon: -10, MaxLat: 44, MaxLon: 4}, []string{"lisbon", "porto", "algarve"}},
+		{"wraps the antimeridian", types.BoundingBox{MinLat: -20, MinLon: 170, MaxLat: -10, MaxLon: -170}, []string{"suva", "apia"}},
+		{"west of the antimeridian only", types.BoundingBox{MinLat: -20, MinLon: 170, MaxLat: -10, MaxLon: 180}, []string{"suva"}},
+		{"empty ocean", types.BoundingBox{MinLat: -50, MinLon: -40, MaxLat: -40, MaxLon: -30}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &types.GeographicFilters{BoundingBox: &tt.box}
+			if err := ValidateFilters(f); err != nil {
+				t.Fatalf("ValidateFilters: %v", err)
+			}
+			if got := ids(ApplyFilters(placeFixture, f)); !sameIDs(got, tt.want) {
+				t.Errorf("ApplyFilters = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateBoundingBox(t *testing.T) {
+	for _, box := range []types.BoundingBox{
+		{MinLat: 10, MinLon: 0, MaxLat: -10, MaxLon: 10},
+		{MinLat: -95, MinLon: 0, MaxLat: 10, MaxLon: 10},
+		{MinLat: 0, MinLon: 0, MaxLat: 10, MaxLon: 190},
+	} {
+		if err := ValidateFilters(&types.GeographicFilters{BoundingBox: &box}); err == nil {
+			t.Errorf("ValidateFilters accepted %+v", box)
+		}
+	}
+}
+
+func TestValidateFiltersCoordinates(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters types.GeographicFilters
+		// wantErr is the expected error, empty for valid filters
+		wantErr string
+	}{
+		{"near at the boundary", types.GeographicFilters{Near: &types.Location{Lat: -90, Lon: 180}, RadiusKm: 10}, ""},
+		{"near lat just outside", types.GeographicFilters{Near: &types.Location{Lat: 90.5, Lon: 0}, RadiusKm: 10}, "near: lat must be between -90 and 90, got 90.5"},
+		{"near lon just outside", types.GeographicFilters{Near: &types.Location{Lat: 0, Lon: -180.5}, RadiusKm: 10}, "near: lon must be between -180 and 180, got -180.5"},
+		{"bbox at the boundary", types.GeographicFilters{BoundingBox: &types.BoundingBox{MinLat: -90, MinLon: -180, MaxLat: 90, MaxLon: 180}}, ""},
+		{"bbox min corner just outside", types.GeographicFilters{BoundingBox: &types.BoundingBox{MinLat: -90.5, MinLon: 0, MaxLat: 10, MaxLon: 10}}, "bbox: lat must be between -90 and 90, got -90.5"},
+		{"bbox max corner just outside", types.GeographicFilters{BoundingBox: &types.BoundingBox{MinLat: 0, MinLon: 0, MaxLat: 10, MaxLon: 180.5}}, "bbox: lon must be between -180 and 180, got 180.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFilters(&tt.filters)
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tt.wantErr {
+				t.Errorf("ValidateFilters = %q, want %q", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNearest(t *testing.T) {
+	dests := []types.Destination{
+		{ID: "tokyo", Location: types.Location{Lat: 35.68, Lon: 139.69}},
+		{ID: "porto", Location: types.Location{Lat: 41.15, Lon: -8.61}},
+		{ID: "lisbon", Location: types.Location{Lat: 38.72, Lon: -9.14}},
+		{ID: "madrid", Location: types.Location{Lat: 40.42, Lon: -3.70}},
+		// Same point as lisbon, so the tie goes to ID order
+		{ID: "alfama", Location: types.Location{Lat: 38.72, Lon: -9.14}},
+	}
+	lisbon := types.Location{Lat: 38.72, Lon: -9.14}
+	tests := []struct {
+		name string
+		n    int
+		want []string
+	}{
+		{"all", 10, []string{"alfama", "lisbon", "porto", "madrid", "tokyo"}},
+		{"top two", 2, []string{"alfama", "lisbon"}},
+		{"none", 0, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Nearest(lisbon, dests, tt.n)
+			ids := make([]string, len(got))
+			for i, n := range got {
+				ids[i] = n.Destination.ID
+				if i > 0 && n.DistanceKm < got[i-1].DistanceKm {
+					t.Errorf("%s at %.1f km follows %.1f km", ids[i], n.DistanceKm, got[i-1].DistanceKm)
+				}
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("Nearest = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package ranking
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// SimilarityFunc rates how close a destination's feature vector is to a
+// query vector, in [0, 1] with higher meaning closer. Weights are
+// per-dimension multipliers; nil weighs every dimension equally. Constrained
+// marks the query dimensions the search targets, nil meaning all of them,
+// for metrics that compare only those.
+type SimilarityFunc func(query, dest, weights []float64, constrained []bool) float64
+
+// DefaultMetric is the similarity metric used when a search doesn't name one
+const DefaultMetric = "cosine"
+
+// metrics are the similarity metrics a search can select by name
+var metrics = map[string]SimilarityFunc{
+	"cosine":    cosineMetric,
+	"euclidean": EuclideanSimilarity,
+}
+
+// cosineMetric is WeightedCosineSimilarity as a SimilarityFunc. Cosine
+// compares every dimension: an untargeted one is 0 in the query and adds
+// nothing to the dot product.
+func cosineMetric(query, dest, weights []float64, _ []bool) float64 {
+	return WeightedCosineSimilarity(query, dest, weights)
+}
+
+// ConstrainedMask marks the features named by keys, ordered like Features,
+// for a SimilarityFunc's constrained argument. Unknown keys are ignored.
+func ConstrainedMask(keys ...string) []bool {
+	mask := make([]bool, len(Features))
+	for i, feat := range Features {
+		mask[i] = slices.Contains(keys, feat.Key)
+	}
+	return mask
+}
+
+// MetricByName looks up a similarity metric, defaulting to cosine for ""
+func MetricByName(name string) (SimilarityFunc, error) {
+	if name == "" {
+		name = DefaultMetric
+	}
+	if m, ok := metrics[name]; ok {
+		return m, nil
+	}
+	names := make([]string, 0, len(metrics))
+	for n := range metrics {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("metric must be one of %s", strings.Join(names, ", "))
+}
+
+// EuclideanSimilarity converts the weighted euclidean distance between query
+// and dest into a similarity: 1 at the query, 0 at the farthest possible
+// point. Dimensions constrained leaves unmarked are left out, so they stay
+// neutral instead of pulling toward the query's 0; a targeted 0, such as
+// the midpoint of {max: 0}, still counts.
+func EuclideanSimilarity(query, dest, weights []float64, constrained []bool) float64 {
+	var sum, total float64
+	for i := range query {
+		if constrained != nil && !constrained[i] {
+			continue
+		}
+		w := 1.0
+		if weights != nil {
+			w = weights[i]
+		}
+		d := query[i] - dest[i]
+		sum += w * d * d
+		total += w
+	}
+	if total == 0 {
+		return 0
+	}
+	// Features are normalized to [0, 1], so sum/total is at most 1
+	return 1 - math.Sqrt(sum/total)
+}
@@ -0,0 +1,72 @@
+package ranking
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestMetricByName(t *testing.T) {
+	for _, name := range []string{"", "cosine", "euclidean"} {
+		if m, err := MetricByName(name); err != nil || m == nil {
+			t.Errorf("MetricByName(%q) = %v, %v", name, m, err)
+		}
+	}
+	if _, err := MetricByName("manhattan"); err == nil || err.Error() != "metric must be one of cosine, euclidean" {
+		t.Errorf("MetricByName(manhattan) error = %v", err)
+	}
+}
+
+func TestEuclideanSimilarity(t *testing.T) {
+	tests := []struct {
+		name                 string
+		query, dest, weights []float64
+		constrained          []bool
+		want                 float64
+	}{
+		{"identical", []float64{0.2, 0.8}, []float64{0.2, 0.8}, nil, nil, 1},
+		{"farthest point", []float64{0, 1}, []float64{1, 0}, nil, nil, 0},
+		{"halfway", []float64{0, 0}, []float64{0.5, 0.5}, nil, nil, 0.5},
+		// An untargeted query 0 is neutral rather than a target
+		{"unconstrained left out", []float64{0.6, 0}, []float64{0.6, 1}, nil, []bool{true, false}, 1},
+		{"targeted zero counts", []float64{0.6, 0}, []float64{0.6, 1}, nil, []bool{true, true}, 1 - math.Sqrt(0.5)},
+		{"weighted", []float64{0, 0}, []float64{1, 0}, []float64{3, 1}, nil, 1 - math.Sqrt(0.75)},
+		{"nothing to compare", []float64{0.6}, []float64{0.1}, nil, []bool{false}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EuclideanSimilarity(tt.query, tt.dest, tt.weights, tt.constrained); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("EuclideanSimilarity = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetricsOrder(t *testing.T) {
+	// Cosine compares direction, so "more of the same" ranks high; euclidean
+	// compares distance, so being close to the target does
+	dests := []types.Destination{
+		{ID: "stronger", Features: types.DestinationFeatures{SkiingScore: 1, HikingScore: 0.9}},
+		{ID: "lopsided", Features: types.DestinationFeatures{SkiingScore: 0.5, HikingScore: 0.3}},
+		{ID: "exact", Features: types.DestinationFeatures{SkiingScore: 0.5, HikingScore: 0.5}},
+	}
+	query := types.DestinationFeatures{SkiingScore: 0.5, HikingScore: 0.5}
+	tests := []struct {
+		metric string
+		want   []string
+	}{
+		{"cosine", []string{"exact", "stronger", "lopsided"}},
+		{"euclidean", []string{"exact", "lopsided", "stronger"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.metric, func(t *testing.T) {
+			metric, _ := MetricByName(tt.metric)
+			s := Scorer{Query: query, Metric: metric, Constrained: ConstrainedMask("skiing_score", "hiking_score")}
+			if got := resultIDs(s.Rank(dests)); !slices.Equal(got, tt.want) {
+				t.Errorf("%s ranks %v, want %v", tt.metric, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package ranking
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// beachTown is another beach town for vibeFixture's two to be liked beside
+var beachTown = types.Destination{ID: "puerto_escondido", Features: types.DestinationFeatures{
+	AvgTempC: 0.78, TourismDensity: 0.5, WikipediaPageviews: 0.3, AccommodationDensity: 0.6,
+	Population: 0.1, CoastDistanceKm: 0, NatureRatio: 0.55, Elevation: 0.01,
+	WaterSportsScore: 0.95, HikingScore: 0.25, WildlifeScore: 0.45, NightlifeDensity: 0.65,
+	DevelopmentLevel: 0.45, GDPPerCapita: 0.35,
+}}
+
+func TestMoreLike(t *testing.T) {
+	dests := append(slices.Clone(vibeFixture), beachTown)
+	tests := []struct {
+		name  string
+		seeds []string
+		// wantFirst is the expected closest match
+		wantFirst string
+	}{
+		{"two beach towns", []string{"tamarindo", "tulum"}, "puerto_escondido"},
+		{"one seed is Similar", []string{"zermatt"}, "verbier"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seeds := make([]types.Destination, len(tt.seeds))
+			for i, id := range tt.seeds {
+				seeds[i] = fixture(t, id)
+			}
+			results := MoreLike(seeds, dests, len(dests))
+			got := resultIDs(results)
+			if len(got) != len(dests)-len(seeds) {
+				t.Fatalf("MoreLike returned %v, want every destination but the seeds", got)
+			}
+			for _, id := range tt.seeds {
+				if slices.Contains(got, id) {
+					t.Errorf("MoreLike includes seed %s: %v", id, got)
+				}
+			}
+			if got[0] != tt.wantFirst {
+				t.Errorf("closest = %s, want %s (%v)", got[0], tt.wantFirst, got)
+			}
+			if len(seeds) == 1 && !slices.Equal(got, resultIDs(Similar(seeds[0], dests, len(dests)))) {
+				t.Errorf("MoreLike of one seed = %v, want Similar's order", got)
+			}
+		})
+	}
+}
+
+func TestCentroid(t *testing.T) {
+	a := types.Destination{ID: "a", Features: types.DestinationFeatures{AvgTempC: 0.2, SkiingScore: 0.8, HikingScore: 0.5}}
+	b := types.Destination{ID: "b", Features: types.DestinationFeatures{AvgTempC: 0.6, SkiingScore: 0, HikingScore: 0.7},
+		MissingFeatures: []string{"skiing_score"}}
+
+	got, missing := Centroid([]types.Destination{a, b})
+	tests := []struct {
+		name      string
+		got, want float64
+	}{
+		{"averaged", got.AvgTempC, 0.4},
+		{"averaged over those with data", got.SkiingScore, 0.8},
+		{"averaged again", got.HikingScore, 0.6},
+	}
+	for _, tt := range tests {
+		if math.Abs(tt.got-tt.want) > 1e-9 {
+			t.Errorf("%s: got %g, want %g", tt.name, tt.got, tt.want)
+		}
+	}
+	if len(missing) != 0 {
+		t.Errorf("missing = %v, want none", missing)
+	}
+
+	b.MissingFeatures = append(b.MissingFeatures, "elevation")
+	a.MissingFeatures = []string{"elevation"}
+	if _, missing := Centroid([]types.Destination{a, b}); !slices.Equal(missing, []string{"elevation"}) {
+		t.Errorf("missing = %v, want elevation, which neither has", missing)
+	}
+}
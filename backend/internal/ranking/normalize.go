@@ -0,0 +1,76 @@
+package ranking
+
+import (
+	"math"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// Bounds of the normalized scale every stored feature and constraint uses
+const (
+	NormalizedMin = 0.0
+	NormalizedMax = 1.0
+)
+
+// Range is the source range a raw feature is mapped from onto [0, 1].
+// Values outside it are clamped. Log ranges are scaled on log(1+v), which
+// suits heavy-tailed counts like population and pageviews.
+type Range struct {
+	Min, Max float64
+	Log      bool
+}
+
+// Scale maps v from the range onto [0, 1], clamping at the ends
+func (r Range) Scale(v float64) float64 {
+	lo, hi := r.Min, r.Max
+	if r.Log {
+		v, lo, hi = math.Log1p(math.Max(v, 0)), math.Log1p(lo), math.Log1p(hi)
+	}
+	if hi <= lo {
+		return NormalizedMin
+	}
+	return math.Min(math.Max((v-lo)/(hi-lo), NormalizedMin), NormalizedMax)
+}
+
+// Normalize maps raw measurements onto the [0, 1] scale used for ranking.
+// Monthly temperatures share avg_temp_c's range; an all-zero raw series is
+// kept as all zeros, meaning no seasonal data.
+func Normalize(raw types.RawFeatures) types.DestinationFeatures {
+	src := types.DestinationFeatures(raw)
+	var out types.DestinationFeatures
+	for _, f := range Features {
+		*f.Field(&out) = FeatureRanges[f.Key].Scale(*f.Field(&src))
+	}
+
+	if HasSeasonalData(src) {
+		temp := FeatureRanges["avg_temp_c"]
+		for m, v := range src.MonthlyTempC {
+			out.MonthlyTempC[m] = temp.Scale(v)
+		}
+	}
+	return out
+}
+
+// ZeroNonFinite replaces NaN and infinite values in f with 0 and returns the
+// keys of the features it changed, with "monthly_temp_c" standing for any of
+// the monthly temperatures
+func ZeroNonFinite(f *types.DestinationFeatures) []string {
+	var changed []string
+	for _, feat := range Features {
+		if v := feat.Field(f); !isFinite(*v) {
+			*v = 0
+			changed = append(changed, feat.Key)
+		}
+	}
+	monthly := false
+	for m, v := range f.MonthlyTempC {
+		if !isFinite(v) {
+			f.MonthlyTempC[m] = 0
+			monthly = true
+		}
+	}
+	if monthly {
+		changed = append(changed, "monthly_temp_c")
+	}
+	return changed
+}
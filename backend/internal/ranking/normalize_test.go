@@ -0,0 +1,136 @@
+package ranking
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestRangeScale(t *testing.T) {
+	linear := Range{Min: 0, Max: 500}
+	logScale := Range{Min: 0, Max: 99, Log: true}
+	tests := []struct {
+		name string
+		r    Range
+		v    float64
+		want float64
+	}{
+		{"linear minimum", linear, 0, 0},
+		{"linear maximum", linear, 500, 1},
+		{"linear midpoint", linear, 250, 0.5},
+		{"clamped below", linear, -20, 0},
+		{"clamped above", linear, 900, 1},
+		{"log minimum", logScale, 0, 0},
+		{"log maximum", logScale, 99, 1},
+		// log1p(9) is half of log1p(99)
+		{"log midpoint", logScale, 9, 0.5},
+		{"log negative", logScale, -5, 0},
+		{"empty range", Range{Min: 1, Max: 1}, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.r.Scale(tt.v); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Scale(%v) = %v, want %v", tt.v, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeBoundaries(t *testing.T) {
+	var atMin, atMax, below, above types.RawFeatures
+	for _, f := range Features {
+		r := FeatureRanges[f.Key]
+		*f.Field((*types.DestinationFeatures)(&atMin)) = r.Min
+		*f.Field((*types.DestinationFeatures)(&atMax)) = r.Max
+		*f.Field((*types.DestinationFeatures)(&below)) = r.Min - 1_000
+		*f.Field((*types.DestinationFeatures)(&above)) = r.Max * 10
+	}
+	tests := []struct {
+		name string
+		raw  types.RawFeatures
+		want float64
+	}{
+		{"range minimum", atMin, 0},
+		{"range maximum", atMax, 1},
+		{"below the range", below, 0},
+		{"above the range", above, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := Normalize(tt.raw)
+			for _, f := range Features {
+				if got := *f.Field(&out); math.Abs(got-tt.want) > 1e-9 {
+					t.Errorf("%s = %v, want %v", f.Key, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeMidValues(t *testing.T) {
+	out := Normalize(types.RawFeatures{AvgTempC: 15, CoastDistanceKm: 50, Population: 200_000, Elevation: 2_500})
+	tests := []struct {
+		key       string
+		got       float64
+		min, max  float64
+		rationale string
+	}{
+		{"avg_temp_c", out.AvgTempC, 0.5, 0.5, "15°C is halfway between -15 and 45"},
+		{"coast_distance_km", out.CoastDistanceKm, 0.1, 0.1, "50 km of 500"},
+		{"elevation", out.Elevation, 0.5, 0.5, "2,500 m of 5,000"},
+		// Log scaling puts a mid-sized city well above its linear share
+		{"population", out.Population, 0.4, 0.6, "a city of 200,000"},
+	}
+	for _, tt := range tests {
+		if tt.got < tt.min-1e-9 || tt.got > tt.max+1e-9 {
+			t.Errorf("%s = %v for %s, want within [%v, %v]", tt.key, tt.got, tt.rationale, tt.min, tt.max)
+		}
+	}
+}
+
+func TestNormalizeMonthlyTemps(t *testing.T) {
+	seasonal := Normalize(types.RawFeatures{MonthlyTempC: [12]float64{-15, 0, 15, 30, 45, 60}})
+	want := [12]float64{0, 0.25, 0.5, 0.75, 1, 1, 0.25, 0.25, 0.25, 0.25, 0.25, 0.25}
+	for m := range want {
+		if math.Abs(seasonal.MonthlyTempC[m]-want[m]) > 1e-9 {
+			t.Errorf("month %d = %v, want %v", m+1, seasonal.MonthlyTempC[m], want[m])
+		}
+	}
+	if none := Normalize(types.RawFeatures{AvgTempC: 20}); none.MonthlyTempC != [12]float64{} {
+		t.Errorf("no seasonal data normalized to %v, want all zeros", none.MonthlyTempC)
+	}
+}
+
+func TestZeroNonFinite(t *testing.T) {
+	tests := []struct {
+		name string
+		f    types.DestinationFeatures
+		want []string
+	}{
+		{"finite", types.DestinationFeatures{SkiingScore: 0.5, Population: 1}, nil},
+		{"NaN", types.DestinationFeatures{SkiingScore: math.NaN()}, []string{"skiing_score"}},
+		{"infinities", types.DestinationFeatures{AvgTempC: math.Inf(-1), Population: math.Inf(1)}, []string{"avg_temp_c", "population"}},
+		{"monthly", types.DestinationFeatures{MonthlyTempC: [12]float64{3: math.NaN(), 7: math.Inf(1)}}, []string{"monthly_temp_c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := tt.f
+			got := ZeroNonFinite(&f)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ZeroNonFinite = %v, want %v", got, tt.want)
+			}
+			for i, v := range Vector(f) {
+				if !isFinite(v) {
+					t.Errorf("%s left at %v", Features[i].Key, v)
+				}
+			}
+			for m, v := range f.MonthlyTempC {
+				if !isFinite(v) {
+					t.Errorf("month %d left at %v", m+1, v)
+				}
+			}
+		})
+	}
+}
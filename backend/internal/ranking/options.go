@@ -0,0 +1,52 @@
+package ranking
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// BuildFilterOptions collects the distinct continents, countries, and
+// regions in dests, grouped and sorted alphabetically at every level
+func BuildFilterOptions(dests []types.Destination) types.FilterOptions {
+	// continent -> country -> set of regions
+	tree := map[types.Continent]map[string]map[string]bool{}
+	for _, d := range dests {
+		countries, ok := tree[d.Continent]
+		if !ok {
+			countries = map[string]map[string]bool{}
+			tree[d.Continent] = countries
+		}
+		regions, ok := countries[d.Country]
+		if !ok {
+			regions = map[string]bool{}
+			countries[d.Country] = regions
+		}
+		if d.Region != nil && *d.Region != "" {
+			regions[*d.Region] = true
+		}
+	}
+
+	opts := types.FilterOptions{Continents: make([]types.ContinentOption, 0, len(tree))}
+	for continent, countries := range tree {
+		co := types.ContinentOption{Name: continent, Countries: make([]types.CountryOption, 0, len(countries))}
+		for country, regions := range countries {
+			co.Countries = append(co.Countries, types.CountryOption{Name: country, Regions: sortedKeys(regions)})
+		}
+		slices.SortFunc(co.Countries, func(a, b types.CountryOption) int { return cmp.Compare(a.Name, b.Name) })
+		opts.Continents = append(opts.Continents, co)
+	}
+	slices.SortFunc(opts.Continents, func(a, b types.ContinentOption) int { return cmp.Compare(a.Name, b.Name) })
+	return opts
+}
+
+// sortedKeys returns the keys of a set in ascending order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,45 @@
+package ranking
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestBuildFilterOptions(t *testing.T) {
+	// Russia and Turkey each span Europe and Asia, so they're listed under both
+	dests := []types.Destination{
+		{ID: "vladivostok", Country: "Russia", Continent: types.Asia, Region: new("Primorsky Krai")},
+		{ID: "moscow", Country: "Russia", Continent: types.Europe, Region: new("Moscow Oblast")},
+		{ID: "sochi", Country: "Russia", Continent: types.Europe, Region: new("Krasnodar Krai")},
+		{ID: "cappadocia", Country: "Turkey", Continent: types.Asia, Region: new("Nevsehir")},
+		{ID: "istanbul", Country: "Turkey", Continent: types.Europe},
+		{ID: "goreme", Country: "Turkey", Continent: types.Asia, Region: new("Nevsehir")},
+		{ID: "kyoto", Country: "Japan", Continent: types.Asia, Region: new("Kansai")},
+		{ID: "blank-region", Country: "Japan", Continent: types.Asia, Region: new("")},
+		{ID: "lisbon", Country: "Portugal", Continent: types.Europe},
+	}
+	want := types.FilterOptions{Continents: []types.ContinentOption{
+		{Name: types.Asia, Countries: []types.CountryOption{
+			{Name: "Japan", Regions: []string{"Kansai"}},
+			{Name: "Russia", Regions: []string{"Primorsky Krai"}},
+			{Name: "Turkey", Regions: []string{"Nevsehir"}},
+		}},
+		{Name: types.Europe, Countries: []types.CountryOption{
+			{Name: "Portugal", Regions: []string{}},
+			{Name: "Russia", Regions: []string{"Krasnodar Krai", "Moscow Oblast"}},
+			{Name: "Turkey", Regions: []string{}},
+		}},
+	}}
+	if got := BuildFilterOptions(dests); !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildFilterOptions =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestBuildFilterOptionsEmpty(t *testing.T) {
+	got := BuildFilterOptions(nil)
+	if got.Continents == nil || len(got.Continents) != 0 {
+		t.Errorf("Continents = %#v, want an empty list that encodes as []", got.Continents)
+	}
+}
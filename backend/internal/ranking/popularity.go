@@ -0,0 +1,26 @@
+package ranking
+
+import (
+	"cmp"
+	"slices"
+)
+
+// DefaultPopularityFeature orders searches that give nothing to rank on
+const DefaultPopularityFeature = "wikipedia_pageviews"
+
+// OrderByPopularity sorts results by descending score, breaking ties by
+// descending popularity feature value and then by ID, so the order is the
+// same on every call. It gives searches without a query or constraints,
+// whose scores all tie, a meaningful order.
+func OrderByPopularity(results []Result, popularity Feature) {
+	slices.SortStableFunc(results, func(a, b Result) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		pa, pb := *popularity.Field(&a.Destination.Features), *popularity.Field(&b.Destination.Features)
+		if c := cmp.Compare(pb, pa); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Destination.ID, b.Destination.ID)
+	})
+}
@@ -0,0 +1,47 @@
+package ranking
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestOrderByPopularity(t *testing.T) {
+	result := func(id string, score, pageviews, population float64) Result {
+		return Result{Score: score, Destination: types.Destination{ID: id, Features: types.DestinationFeatures{
+			WikipediaPageviews: pageviews, Population: population,
+		}}}
+	}
+	tests := []struct {
+		name       string
+		popularity string
+		results    []Result
+		want       []string
+	}{
+		{"ties ordered by pageviews", DefaultPopularityFeature,
+			[]Result{result("quiet", 1, 0.1, 0.9), result("famous", 1, 0.9, 0.1), result("known", 1, 0.5, 0.5)},
+			[]string{"famous", "known", "quiet"}},
+		{"configured feature", "population",
+			[]Result{result("quiet", 1, 0.1, 0.9), result("famous", 1, 0.9, 0.1), result("known", 1, 0.5, 0.5)},
+			[]string{"quiet", "known", "famous"}},
+		{"score before popularity", DefaultPopularityFeature,
+			[]Result{result("famous", 0.5, 0.9, 0), result("match", 0.8, 0.1, 0)},
+			[]string{"match", "famous"}},
+		{"full ties by ID", DefaultPopularityFeature,
+			[]Result{result("b", 1, 0.5, 0), result("c", 1, 0.5, 0), result("a", 1, 0.5, 0)},
+			[]string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, ok := FeatureByKey(tt.popularity)
+			if !ok {
+				t.Fatalf("no feature %q", tt.popularity)
+			}
+			OrderByPopularity(tt.results, f)
+			if got := resultIDs(tt.results); !slices.Equal(got, tt.want) {
+				t.Errorf("order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package ranking
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// Presets are the named vibes a search can start from. Targets are on the
+// normalized scale; see the vibe profiles in docs/SCHEMA.md for how the raw
+// ranges map onto it.
+var Presets = []types.Preset{
+	{
+		Name:        "adventure",
+		Description: "Outdoor thrills: trails, slopes, water, and wild places",
+		Targets: map[string]float64{
+			"hiking_score":       0.9,
+			"nature_ratio":       0.8,
+			"wildlife_score":     0.6,
+			"water_sports_score": 0.6,
+			"skiing_score":       0.5,
+			"elevation":          0.4,
+			"tourism_density":    0.2,
+		},
+		Weights: map[string]float64{"hiking_score": 2, "nature_ratio": 2},
+	},
+	{
+		Name:        "relaxation",
+		Description: "Warm, quiet, and green, with water nearby",
+		Targets: map[string]float64{
+			"avg_temp_c":         0.65, // about 24°C
+			"nature_ratio":       0.7,
+			"water_sports_score": 0.5,
+			"tourism_density":    0.2,
+			"nightlife_density":  0.1,
+		},
+		Weights: map[string]float64{"avg_temp_c": 2, "nature_ratio": 2},
+	},
+	{
+		Name:        "culture",
+		Description: "Well-known, well-developed places with plenty to see",
+		Targets: map[string]float64{
+			"wikipedia_pageviews":   0.8,
+			"tourism_density":       0.7,
+			"development_level":     0.8,
+			"population":            0.6,
+			"accommodation_density": 0.6,
+		},
+		Weights: map[string]float64{"wikipedia_pageviews": 2, "tourism_density": 2},
+	},
+	{
+		Name:        "party",
+		Description: "Big nights out in busy, warm places",
+		Targets: map[string]float64{
+			"nightlife_density":     0.9,
+			"tourism_density":       0.7,
+			"accommodation_density": 0.7,
+			"population":            0.6,
+			"avg_temp_c":            0.6, // about 21°C
+		},
+		Weights: map[string]float64{"nightlife_density": 3},
+	},
+}
+
+// PresetByName looks up a preset, ignoring case. Unknown names produce an
+// error listing the valid ones.
+func PresetByName(name string) (types.Preset, error) {
+	names := make([]string, len(Presets))
+	for i, p := range Presets {
+		if strings.EqualFold(p.Name, name) {
+			return p, nil
+		}
+		names[i] = p.Name
+	}
+	return types.Preset{}, fmt.Errorf("unknown preset %q, must be one of %s", name, strings.Join(names, ", "))
+}
+
+// ApplyPreset fills in query with the preset's targets for every feature
+// constraints leaves unconstrained, and returns weights with the preset's
+// weights added for features weights doesn't already set
+func ApplyPreset(p types.Preset, query *types.DestinationFeatures, constraints types.SearchConstraints, weights map[string]float64) map[string]float64 {
+	for key, v := range p.Targets {
+		if _, ok := constraints[key]; ok {
+			continue
+		}
+		if feat, ok := FeatureByKey(key); ok {
+			*feat.Field(query) = v
+		}
+	}
+
+	merged := maps.Clone(p.Weights)
+	if merged == nil {
+		merged = map[string]float64{}
+	}
+	maps.Copy(merged, weights)
+	return merged
+}
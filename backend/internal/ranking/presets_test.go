@@ -0,0 +1,104 @@
+package ranking
+
+import (
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestPresetsUseKnownFeatures(t *testing.T) {
+	for _, p := range Presets {
+		if p.Name == "" || p.Description == "" || len(p.Targets) == 0 {
+			t.Errorf("preset %+v is incomplete", p)
+		}
+		for key, v := range p.Targets {
+			if _, ok := FeatureByKey(key); !ok {
+				t.Errorf("%s targets unknown feature %q", p.Name, key)
+			}
+			if v < 0 || v > 1 {
+				t.Errorf("%s target %s = %v, want it on the normalized 0-1 scale", p.Name, key, v)
+			}
+		}
+		for key, w := range p.Weights {
+			if _, ok := p.Targets[key]; !ok {
+				t.Errorf("%s weighs %q without targeting it", p.Name, key)
+			}
+			if w <= 0 {
+				t.Errorf("%s weight %s = %v, want positive", p.Name, key, w)
+			}
+		}
+	}
+}
+
+func TestPresetByName(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"adventure", "adventure", false},
+		{"Party", "party", false},
+		{"RELAXATION", "relaxation", false},
+		{"nap", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PresetByName(tt.name)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "must be one of adventure, relaxation, culture, party") {
+					t.Errorf("PresetByName(%q) error = %v, want the valid names listed", tt.name, err)
+				}
+				return
+			}
+			if err != nil || got.Name != tt.want {
+				t.Errorf("PresetByName(%q) = %s, %v; want %s", tt.name, got.Name, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyPreset(t *testing.T) {
+	preset := types.Preset{
+		Name:    "test",
+		Targets: map[string]float64{"hiking_score": 0.9, "nature_ratio": 0.8},
+		Weights: map[string]float64{"hiking_score": 2, "nature_ratio": 2},
+	}
+	tests := []struct {
+		name        string
+		constraints types.SearchConstraints
+		weights     map[string]float64
+		wantHiking  float64
+		wantWeights map[string]float64
+	}{
+		{"preset alone", nil, nil, 0.9, map[string]float64{"hiking_score": 2, "nature_ratio": 2}},
+		{
+			"constraint keeps its own target",
+			types.SearchConstraints{"hiking_score": {Min: new(0.2), Max: new(0.4)}}, nil,
+			0, map[string]float64{"hiking_score": 2, "nature_ratio": 2},
+		},
+		{
+			"explicit weights win and add to the preset's",
+			nil, map[string]float64{"hiking_score": 1, "skiing_score": 3},
+			0.9, map[string]float64{"hiking_score": 1, "nature_ratio": 2, "skiing_score": 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query types.DestinationFeatures
+			weights := ApplyPreset(preset, &query, tt.constraints, tt.weights)
+			if query.HikingScore != tt.wantHiking || query.NatureRatio != 0.8 {
+				t.Errorf("query hiking %v, nature %v; want %v, 0.8", query.HikingScore, query.NatureRatio, tt.wantHiking)
+			}
+			if !maps.Equal(weights, tt.wantWeights) {
+				t.Errorf("weights = %v, want %v", weights, tt.wantWeights)
+			}
+		})
+	}
+
+	if _, ok := preset.Weights["skiing_score"]; ok {
+		t.Error("ApplyPreset changed the preset's own weights")
+	}
+}
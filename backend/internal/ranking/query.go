@@ -0,0 +1,190 @@
+package ranking
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// bound is a keyword's constraint on a single feature
+type bound struct {
+	feature string
+	min     *float64
+	max     *float64
+}
+
+func atLeast(feature string, v float64) bound { return bound{feature: feature, min: &v} }
+func atMost(feature string, v float64) bound  { return bound{feature: feature, max: &v} }
+
+// profiles are the named vibes the query parser and search conveniences
+// (such as mountain=true) share, expressed in normalized feature space.
+// Temperatures use the ingestion scale of -15°C to 45°C and coast distance
+// is capped at 500km.
+var profiles = map[string][]bound{
+	"beach":     {atMost("coast_distance_km", 0.01), atLeast("water_sports_score", 0.6)},
+	"snow":      {atLeast("skiing_score", 0.6), atMost("avg_temp_c", 0.35)},
+	"mountain":  {atLeast("elevation", 0.2), atLeast("hiking_score", 0.5)},
+	"nightlife": {atLeast("nightlife_density", 0.6)},
+	"remote":    {atMost("population", 0.2), atMost("tourism_density", 0.3)},
+	"quiet":     {atMost("tourism_density", 0.4), atMost("nightlife_density", 0.4)},
+	"nature":    {atLeast("nature_ratio", 0.6)},
+	"wildlife":  {atLeast("wildlife_score", 0.6)},
+	"hiking":    {atLeast("hiking_score", 0.6)},
+	"city":      {atLeast("population", 0.5)},
+	"town":      {atMost("population", 0.4)},
+	"warm":      {atLeast("avg_temp_c", 0.58)}, // >= 20°C
+	"hot":       {atLeast("avg_temp_c", 0.7)},  // >= 27°C
+	"cold":      {atMost("avg_temp_c", 0.33)},  // <= 5°C
+	"mild":      {atLeast("avg_temp_c", 0.45), atMost("avg_temp_c", 0.65)},
+	"budget":    {atMost("gdp_per_capita", 0.5)},
+}
+
+// keywords maps query tokens to the profile they imply
+var keywords = map[string]string{
+	"beach":     "beach",
+	"beaches":   "beach",
+	"coast":     "beach",
+	"coastal":   "beach",
+	"seaside":   "beach",
+	"snow":      "snow",
+	"snowy":     "snow",
+	"ski":       "snow",
+	"skiing":    "snow",
+	"mountain":  "mountain",
+	"mountains": "mountain",
+	"alpine":    "mountain",
+	"nightlife": "nightlife",
+	"party":     "nightlife",
+	"lively":    "nightlife",
+	"remote":    "remote",
+	"secluded":  "remote",
+	"quiet":     "quiet",
+	"chill":     "quiet",
+	"relaxing":  "quiet",
+	"nature":    "nature",
+	"green":     "nature",
+	"wildlife":  "wildlife",
+	"safari":    "wildlife",
+	"hiking":    "hiking",
+	"trekking":  "hiking",
+	"city":      "city",
+	"urban":     "city",
+	"town":      "town",
+	"village":   "town",
+	"warm":      "warm",
+	"sunny":     "warm",
+	"hot":       "hot",
+	"tropical":  "hot",
+	"cold":      "cold",
+	"mild":      "mild",
+	"budget":    "budget",
+	"cheap":     "budget",
+}
+
+// stopWords are dropped before keyword matching
+var stopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "and": true, "or": true, "with": true,
+	"in": true, "on": true, "of": true, "to": true, "for": true, "by": true,
+	"near": true, "some": true, "lots": true, "very": true, "really": true,
+	"place": true, "places": true, "somewhere": true, "good": true, "great": true,
+}
+
+// tokenize lowercases q and splits it into words, dropping stop words
+func tokenize(q string) []string {
+	words := strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := words[:0]
+	for _, w := range words {
+		if !stopWords[w] {
+			tokens = append(tokens, w)
+		}
+	}
+	return tokens
+}
+
+// ParseQuery maps the keywords in a free-text query to feature constraints.
+// Unrecognized words are ignored. Overlapping constraints are merged by
+// keeping the tighter bound, and contradictory ones produce an error.
+func ParseQuery(q string) (types.SearchConstraints, error) {
+	constraints := types.SearchConstraints{}
+	for _, token := range tokenize(q) {
+		for _, b := range profiles[keywords[token]] {
+			constraints[b.feature] = mergeConstraint(constraints[b.feature], types.FeatureConstraint{Min: b.min, Max: b.max})
+		}
+	}
+
+	if err := checkConstraints(constraints); err != nil {
+		return nil, err
+	}
+	return constraints, nil
+}
+
+// ProfileConstraints returns the constraints of a named profile
+func ProfileConstraints(name string) (types.SearchConstraints, bool) {
+	bounds, ok := profiles[name]
+	if !ok {
+		return nil, false
+	}
+	c := make(types.SearchConstraints, len(bounds))
+	for _, b := range bounds {
+		c[b.feature] = mergeConstraint(c[b.feature], types.FeatureConstraint{Min: b.min, Max: b.max})
+	}
+	return c, true
+}
+
+// HasKeywords reports whether q contains any word ParseQuery recognizes
+func HasKeywords(q string) bool {
+	for _, token := range tokenize(q) {
+		if _, ok := keywords[token]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// MergeConstraints combines two constraint sets, keeping the tighter bound
+// wherever both constrain the same feature
+func MergeConstraints(a, b types.SearchConstraints) (types.SearchConstraints, error) {
+	merged := make(types.SearchConstraints, len(a)+len(b))
+	for key, c := range a {
+		merged[key] = c
+	}
+	for key, c := range b {
+		merged[key] = mergeConstraint(merged[key], c)
+	}
+
+	if err := checkConstraints(merged); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// mergeConstraint returns the intersection of two constraints
+func mergeConstraint(a, b types.FeatureConstraint) types.FeatureConstraint {
+	out := a
+	if b.Min != nil && (out.Min == nil || *b.Min > *out.Min) {
+		out.Min = b.Min
+	}
+	if b.Max != nil && (out.Max == nil || *b.Max < *out.Max) {
+		out.Max = b.Max
+	}
+	return out
+}
+
+// checkConstraints reports the first constraint whose min exceeds its max
+func checkConstraints(c types.SearchConstraints) error {
+	for _, feat := range Features {
+		fc, ok := c[feat.Key]
+		if !ok || fc.Min == nil || fc.Max == nil {
+			continue
+		}
+		if *fc.Min > *fc.Max {
+			return fmt.Errorf("contradictory constraints on %s: min %g is greater than max %g", feat.Key, *fc.Min, *fc.Max)
+		}
+	}
+	return nil
+}
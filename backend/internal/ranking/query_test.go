@@ -0,0 +1,110 @@
+package ranking
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  types.SearchConstraints
+	}{
+		{"", types.SearchConstraints{}},
+		{"somewhere with lots of llamas", types.SearchConstraints{}},
+		{"beach", types.SearchConstraints{
+			"coast_distance_km":  {Max: new(0.01)},
+			"water_sports_score": {Min: new(0.6)},
+		}},
+		{"snowy mountain town with nightlife", types.SearchConstraints{
+			"skiing_score":      {Min: new(0.6)},
+			"avg_temp_c":        {Max: new(0.35)},
+			"elevation":         {Min: new(0.2)},
+			"hiking_score":      {Min: new(0.5)},
+			"population":        {Max: new(0.4)},
+			"nightlife_density": {Min: new(0.6)},
+		}},
+		// hiking's tighter hiking_score bound wins over mountain's
+		{"Mountain HIKING", types.SearchConstraints{
+			"elevation":    {Min: new(0.2)},
+			"hiking_score": {Min: new(0.6)},
+		}},
+		// quiet and nightlife overlap on nightlife_density; the tighter
+		// bound on each side is kept
+		{"quiet remote", types.SearchConstraints{
+			"population":        {Max: new(0.2)},
+			"tourism_density":   {Max: new(0.3)},
+			"nightlife_density": {Max: new(0.4)},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery(%q): %v", tt.query, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseQuery(%q) = %s, want %s", tt.query, formatConstraints(got), formatConstraints(tt.want))
+			}
+		})
+	}
+}
+
+func TestParseQueryContradiction(t *testing.T) {
+	for _, query := range []string{"snowy tropical", "cold warm", "quiet party"} {
+		if _, err := ParseQuery(query); err == nil {
+			t.Errorf("ParseQuery(%q) succeeded, want a contradiction error", query)
+		}
+	}
+}
+
+// formatConstraints prints c with its bounds dereferenced
+func formatConstraints(c types.SearchConstraints) string {
+	var b strings.Builder
+	for _, feat := range Features {
+		fc, ok := c[feat.Key]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&b, "%s:[%s,%s] ", feat.Key, formatBound(fc.Min), formatBound(fc.Max))
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func formatBound(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(*v)
+}
+
+func TestProfileConstraints(t *testing.T) {
+	got, ok := ProfileConstraints("mountain")
+	want := types.SearchConstraints{
+		"elevation":    {Min: new(0.2)},
+		"hiking_score": {Min: new(0.5)},
+	}
+	if !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("ProfileConstraints(mountain) = %s, %t; want %s", formatConstraints(got), ok, formatConstraints(want))
+	}
+
+	// The query parser reads the same table
+	parsed, _ := ParseQuery("mountain")
+	if !reflect.DeepEqual(parsed, got) {
+		t.Errorf("ParseQuery(mountain) = %s, want the mountain profile", formatConstraints(parsed))
+	}
+
+	// Callers get a copy they're free to change
+	delete(got, "elevation")
+	if again, _ := ProfileConstraints("mountain"); len(again) != 2 {
+		t.Errorf("changing a returned profile leaked into the table: %s", formatConstraints(again))
+	}
+
+	if _, ok := ProfileConstraints("volcano"); ok {
+		t.Error("ProfileConstraints(volcano) found a profile")
+	}
+}
@@ -0,0 +1,398 @@
+package ranking
+
+import (
+	"cmp"
+	"math"
+	"runtime"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// CosineSimilarity returns the cosine of the angle between a and b.
+// A zero-magnitude vector has no direction, so its similarity is 0.
+func CosineSimilarity(a, b []float64) float64 {
+	return WeightedCosineSimilarity(a, b, nil)
+}
+
+// WeightedCosineSimilarity is CosineSimilarity with each dimension's
+// contribution scaled by the matching weight. Nil weights count every
+// dimension equally.
+func WeightedCosineSimilarity(a, b, weights []float64) float64 {
+	var dot, magA, magB float64
+	for i := range a {
+		w := 1.0
+		if weights != nil {
+			w = weights[i]
+		}
+		dot += w * a[i] * b[i]
+		magA += w * a[i] * a[i]
+		magB += w * b[i] * b[i]
+	}
+	if magA == 0 || magB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(magA) * math.Sqrt(magB))
+}
+
+// Scorer scores destinations against a query vector
+type Scorer struct {
+	Query types.DestinationFeatures
+	// Weights are per-feature multipliers ordered like Features; nil weighs
+	// every feature equally
+	Weights []float64
+	// Metric compares feature vectors; nil uses cosine similarity
+	Metric SimilarityFunc
+	// Constrained marks the query features the search targets, ordered
+	// like Features (see ConstrainedMask); nil targets all of them, as a
+	// similarity or feature-vector query does
+	Constrained []bool
+	// QueryMissing lists query features with no data, such as those missing
+	// from the source of a similarity search. Like each destination's
+	// MissingFeatures, they're left out of the comparison.
+	QueryMissing []string
+
+	// Avoid holds per-feature avoidance strengths ordered like Features;
+	// high values of avoided features subtract up to AvoidPenalty per
+	// feature from the score. Nil avoids nothing.
+	Avoid        []float64
+	AvoidPenalty float64
+
+	// Comfort penalizes destinations outside a temperature band; nil
+	// applies no bias
+	Comfort *ComfortBias
+
+	// Text is a free-text query matched against names and descriptions
+	Text string
+	// TextBlend is the share of the final score given to the text match
+	TextBlend float64
+
+	// Workers caps the goroutines Rank scores with; 0 uses GOMAXPROCS
+	Workers int
+}
+
+// Score returns the similarity between the query and the destination,
+// blended with the text match when a text query is set
+func (s Scorer) Score(d types.Destination) float64 {
+	metric := s.Metric
+	if metric == nil {
+		metric = cosineMetric
+	}
+	a, b, weights := s.vectors(d)
+	score := metric(a, b, weights, s.Constrained)
+	if s.Text != "" {
+		score = (1-s.TextBlend)*score + s.TextBlend*TextScore(s.Text, d)
+	}
+	return score - s.penalty(d)
+}
+
+// penalty is the score subtracted for high values of avoided features and,
+// with a comfort bias, for an uncomfortable climate
+func (s Scorer) penalty(d types.Destination) float64 {
+	return s.avoidPenalty(d) + s.comfortPenalty(d)
+}
+
+// avoidPenalty is the score subtracted for high values of avoided features
+func (s Scorer) avoidPenalty(d types.Destination) float64 {
+	if s.Avoid == nil {
+		return 0
+	}
+	var p float64
+	for i, v := range Vector(d.Features) {
+		if isFinite(v) {
+			p += s.Avoid[i] * v
+		}
+	}
+	return s.AvoidPenalty * p
+}
+
+// comfortPenalty is the score subtracted by the comfort bias
+func (s Scorer) comfortPenalty(d types.Destination) float64 {
+	if s.Comfort == nil {
+		return 0
+	}
+	return s.Comfort.penalty(d)
+}
+
+// TextMatchKey labels the text match's share of the score in a breakdown
+const TextMatchKey = "text_match"
+
+// Breakdown splits a destination's score into per-feature contributions
+// (plus the text match, avoidance penalty, and comfort bias, when set) that
+// sum to Score. It decomposes cosine similarity, so it only applies with the
+// default metric.
+func (s Scorer) Breakdown(d types.Destination) map[string]float64 {
+	a, b, weights := s.vectors(d)
+	weight := func(i int) float64 {
+		if weights == nil {
+			return 1
+		}
+		return weights[i]
+	}
+	var magA, magB float64
+	for i := range a {
+		w := weight(i)
+		magA += w * a[i] * a[i]
+		magB += w * b[i] * b[i]
+	}
+
+	featureShare := 1.0
+	breakdown := make(map[string]float64, len(Features)+1)
+	if s.Text != "" {
+		featureShare = 1 - s.TextBlend
+		breakdown[TextMatchKey] = s.TextBlend * TextScore(s.Text, d)
+	}
+
+	norm := math.Sqrt(magA) * math.Sqrt(magB)
+	for i, feat := range Features {
+		contribution := 0.0
+		if norm != 0 {
+			contribution = featureShare * weight(i) * a[i] * b[i] / norm
+		}
+		breakdown[feat.Key] = contribution
+	}
+	if s.Avoid != nil {
+		breakdown[AvoidPenaltyKey] = -s.avoidPenalty(d)
+	}
+	if s.Comfort != nil {
+		breakdown[ComfortPenaltyKey] = -s.comfortPenalty(d)
+	}
+	return breakdown
+}
+
+// vectors returns the query and destination vectors to compare and the
+// weights to compare them with. Non-finite values, which should have been
+// caught on load, are zeroed and left out like missing features so a bad
+// value can't turn every score into NaN.
+func (s Scorer) vectors(d types.Destination) (query, dest, weights []float64) {
+	query, dest, weights = Vector(s.Query), Vector(d.Features), s.weightsFor(d)
+	copied := false
+	for i := range query {
+		if isFinite(query[i]) && isFinite(dest[i]) {
+			continue
+		}
+		// weightsFor may return s.Weights itself, which is shared
+		if !copied {
+			weights, copied = ownWeights(weights), true
+		}
+		query[i], dest[i], weights[i] = 0, 0, 0
+	}
+	return query, dest, weights
+}
+
+// ownWeights returns a copy of weights that's safe to modify, expanding
+// nil to equal weights
+func ownWeights(weights []float64) []float64 {
+	if weights != nil {
+		return slices.Clone(weights)
+	}
+	out := make([]float64, len(Features))
+	for i := range out {
+		out[i] = 1
+	}
+	return out
+}
+
+// isFinite reports whether v is neither NaN nor infinite
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// weightsFor returns the weights to compare d with, zeroing the features
+// missing from the query or from d so both sides skip them. It returns
+// s.Weights unchanged when nothing is missing.
+func (s Scorer) weightsFor(d types.Destination) []float64 {
+	if len(s.QueryMissing) == 0 && len(d.MissingFeatures) == 0 {
+		return s.Weights
+	}
+	weights := make([]float64, len(Features))
+	for i := range weights {
+		weights[i] = 1
+		if s.Weights != nil {
+			weights[i] = s.Weights[i]
+		}
+	}
+	for _, mask := range [][]float64{PresenceMask(s.QueryMissing), PresenceMask(d.MissingFeatures)} {
+		for i, present := range mask {
+			weights[i] *= present
+		}
+	}
+	return weights
+}
+
+// minParallelScoring is the smallest input Rank splits across workers;
+// below it the goroutines cost more than they save
+const minParallelScoring = 512
+
+// Rank scores destinations and sorts them by descending score, breaking
+// ties by ID so the order doesn't depend on how scoring was split up. Large
+// inputs are scored by up to Workers goroutines.
+func (s Scorer) Rank(dests []types.Destination) []Result {
+	results := make([]Result, len(dests))
+	workers := s.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers == 1 || len(dests) < minParallelScoring {
+		s.scoreInto(results, dests)
+	} else {
+		// Each worker writes only its own chunk of results
+		chunk := (len(dests) + workers - 1) / workers
+		var wg sync.WaitGroup
+		for lo := 0; lo < len(dests); lo += chunk {
+			hi := min(lo+chunk, len(dests))
+			wg.Go(func() { s.scoreInto(results[lo:hi], dests[lo:hi]) })
+		}
+		wg.Wait()
+	}
+
+	slices.SortFunc(results, func(a, b Result) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Destination.ID, b.Destination.ID)
+	})
+	return results
+}
+
+// scoreInto scores each destination into the matching slot of results
+func (s Scorer) scoreInto(results []Result, dests []types.Destination) {
+	for i, d := range dests {
+		results[i] = Result{Destination: d, Score: s.Score(d)}
+	}
+}
+
+// FilterByScore keeps the results scoring at least min, preserving order
+func FilterByScore(results []Result, min float64) []Result {
+	kept := results[:0]
+	for _, res := range results {
+		if res.Score >= min {
+			kept = append(kept, res)
+		}
+	}
+	return kept
+}
+
+// ScoreScaleMinMax names the score scale that spreads a result set's scores
+// across [0, 1]
+const ScoreScaleMinMax = "minmax"
+
+// ScaleMinMax rescales the scores in results in place so the highest is 1
+// and the lowest 0, keeping their order. Results that all score the same
+// get 1.
+func ScaleMinMax(results []Result) {
+	if len(results) == 0 {
+		return
+	}
+	lo, hi := results[0].Score, results[0].Score
+	for _, res := range results {
+		lo = min(lo, res.Score)
+		hi = max(hi, res.Score)
+	}
+	for i := range results {
+		if hi == lo {
+			results[i].Score = 1
+		} else {
+			results[i].Score = (results[i].Score - lo) / (hi - lo)
+		}
+	}
+}
+
+// ScoreDestination scores how closely a destination matches the query vibe
+func ScoreDestination(query types.DestinationFeatures, d types.Destination) float64 {
+	return Scorer{Query: query}.Score(d)
+}
+
+// Result is a destination paired with its score against a query
+type Result struct {
+	Destination types.Destination
+	Score       float64
+	// Collapsed counts the near-duplicates merged into this result
+	Collapsed int
+}
+
+// Rank scores destinations against the query with equal feature weights
+func Rank(query types.DestinationFeatures, dests []types.Destination) []Result {
+	return Scorer{Query: query}.Rank(dests)
+}
+
+// Similar returns the n destinations most similar in vibe to source,
+// excluding source itself. Ties go to the more popular destination by
+// Wikipedia pageviews.
+func Similar(source types.Destination, dests []types.Destination, n int) []Result {
+	return MoreLike([]types.Destination{source}, dests, n)
+}
+
+// MoreLike returns the n destinations most similar in vibe to the centroid
+// of seeds, excluding the seeds themselves, with ties broken as in Similar
+func MoreLike(seeds []types.Destination, dests []types.Destination, n int) []Result {
+	query, missing := Centroid(seeds)
+	scorer := Scorer{Query: query, QueryMissing: missing}
+	isSeed := make(map[string]bool, len(seeds))
+	for _, seed := range seeds {
+		isSeed[seed.ID] = true
+	}
+
+	results := make([]Result, 0, len(dests))
+	for _, d := range dests {
+		if isSeed[d.ID] {
+			continue
+		}
+		results = append(results, Result{Destination: d, Score: scorer.Score(d)})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Destination.Features.WikipediaPageviews > results[j].Destination.Features.WikipediaPageviews
+	})
+	return results[:min(n, len(results))]
+}
+
+// Centroid averages the features of dests, counting each feature only over
+// the destinations with data for it. Features none of them have come back
+// as 0 and listed as missing.
+func Centroid(dests []types.Destination) (types.DestinationFeatures, []string) {
+	var centroid types.DestinationFeatures
+	var missing []string
+	for _, feat := range Features {
+		var sum float64
+		var n int
+		for _, d := range dests {
+			if !slices.Contains(d.MissingFeatures, feat.Key) {
+				sum += *feat.Field(&d.Features)
+				n++
+			}
+		}
+		if n == 0 {
+			missing = append(missing, feat.Key)
+			continue
+		}
+		*feat.Field(&centroid) = sum / float64(n)
+	}
+	return centroid, missing
+}
+
+// QueryFromConstraints builds a query vector targeting the middle of each
+// constrained feature's allowed range. Unconstrained features stay at 0 so
+// they don't pull the query in any direction.
+func QueryFromConstraints(c types.SearchConstraints) types.DestinationFeatures {
+	var query types.DestinationFeatures
+	for _, feat := range Features {
+		fc, ok := c[feat.Key]
+		if !ok {
+			continue
+		}
+		lo, hi := 0.0, 1.0
+		if fc.Min != nil {
+			lo = *fc.Min
+		}
+		if fc.Max != nil {
+			hi = *fc.Max
+		}
+		*feat.Field(&query) = (lo + hi) / 2
+	}
+	return query
+}
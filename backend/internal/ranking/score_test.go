@@ -0,0 +1,525 @@
+package ranking
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestCosineSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float64
+		want float64
+	}{
+		{"identical", []float64{0.2, 0.5, 0.9}, []float64{0.2, 0.5, 0.9}, 1},
+		{"same direction", []float64{0.1, 0.2}, []float64{0.3, 0.6}, 1},
+		{"orthogonal", []float64{1, 0}, []float64{0, 1}, 0},
+		{"zero query", []float64{0, 0, 0}, []float64{0.2, 0.5, 0.9}, 0},
+		{"zero destination", []float64{0.2, 0.5, 0.9}, []float64{0, 0, 0}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CosineSimilarity(tt.a, tt.b)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("CosineSimilarity(%v, %v) = %g, want %g", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+// vibeFixture pairs up destinations with similar vibes: two beach towns,
+// two ski resorts, and a big city
+var vibeFixture = []types.Destination{
+	{ID: "tamarindo", Features: types.DestinationFeatures{
+		AvgTempC: 0.72, TourismDensity: 0.6, WikipediaPageviews: 0.3, AccommodationDensity: 0.7,
+		Population: 0.1, CoastDistanceKm: 0, NatureRatio: 0.6, Elevation: 0.01,
+		WaterSportsScore: 0.9, HikingScore: 0.3, WildlifeScore: 0.5, NightlifeDensity: 0.7,
+		DevelopmentLevel: 0.5, GDPPerCapita: 0.4,
+	}},
+	{ID: "tulum", Features: types.DestinationFeatures{
+		AvgTempC: 0.75, TourismDensity: 0.7, WikipediaPageviews: 0.4, AccommodationDensity: 0.8,
+		Population: 0.1, CoastDistanceKm: 0, NatureRatio: 0.5, Elevation: 0.01,
+		WaterSportsScore: 0.8, HikingScore: 0.2, WildlifeScore: 0.4, NightlifeDensity: 0.8,
+		DevelopmentLevel: 0.5, GDPPerCapita: 0.45,
+	}},
+	{ID: "zermatt", Features: types.DestinationFeatures{
+		AvgTempC: 0.25, TourismDensity: 0.8, WikipediaPageviews: 0.6, AccommodationDensity: 0.8,
+		Population: 0.05, CoastDistanceKm: 0.6, NatureRatio: 0.8, Elevation: 0.4,
+		SkiingScore: 0.95, HikingScore: 0.9, WildlifeScore: 0.3, NightlifeDensity: 0.3,
+		DevelopmentLevel: 0.95, GDPPerCapita: 0.95,
+	}},
+	{ID: "verbier", Features: types.DestinationFeatures{
+		AvgTempC: 0.28, TourismDensity: 0.7, WikipediaPageviews: 0.35, AccommodationDensity: 0.7,
+		Population: 0.03, CoastDistanceKm: 0.55, NatureRatio: 0.75, Elevation: 0.3,
+		SkiingScore: 0.9, HikingScore: 0.8, WildlifeScore: 0.3, NightlifeDensity: 0.5,
+		DevelopmentLevel: 0.95, GDPPerCapita: 0.95,
+	}},
+	{ID: "tokyo", Features: types.DestinationFeatures{
+		AvgTempC: 0.52, TourismDensity: 0.9, WikipediaPageviews: 1, AccommodationDensity: 0.9,
+		Population: 1, CoastDistanceKm: 0.01, NatureRatio: 0.1, Elevation: 0.01,
+		WaterSportsScore: 0.2, HikingScore: 0.2, WildlifeScore: 0.05, NightlifeDensity: 1,
+		DevelopmentLevel: 0.95, GDPPerCapita: 0.8,
+	}},
+}
+
+// fixture returns the vibeFixture destination with the given ID
+func fixture(t *testing.T, id string) types.Destination {
+	t.Helper()
+	for _, d := range vibeFixture {
+		if d.ID == id {
+			return d
+		}
+	}
+	t.Fatalf("no fixture %q", id)
+	return types.Destination{}
+}
+
+// resultIDs lists the destination IDs of results in order
+func resultIDs(results []Result) []string {
+	out := make([]string, len(results))
+	for i, res := range results {
+		out[i] = res.Destination.ID
+	}
+	return out
+}
+
+func TestSimilar(t *testing.T) {
+	tests := []struct {
+		source string
+		n      int
+		// wantFirst is the expected closest match
+		wantFirst string
+	}{
+		{"tamarindo", 3, "tulum"},
+		{"tulum", 3, "tamarindo"},
+		{"zermatt", 4, "verbier"},
+		{"verbier", 10, "zermatt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			results := Similar(fixture(t, tt.source), vibeFixture, tt.n)
+			got := resultIDs(results)
+			if want := min(tt.n, len(vibeFixture)-1); len(got) != want {
+				t.Fatalf("Similar returned %d results, want %d: %v", len(got), want, got)
+			}
+			if slices.Contains(got, tt.source) {
+				t.Errorf("Similar includes its source: %v", got)
+			}
+			if got[0] != tt.wantFirst {
+				t.Errorf("closest to %s = %s, want %s (%v)", tt.source, got[0], tt.wantFirst, got)
+			}
+			for i := 1; i < len(results); i++ {
+				if results[i].Score > results[i-1].Score {
+					t.Errorf("results out of order at %d: %v", i, got)
+				}
+			}
+		})
+	}
+}
+
+func TestWeightsReorder(t *testing.T) {
+	// A query wanting both skiing and water sports sits between the beach
+	// towns and the ski resorts; weighting one side decides the order
+	query := types.DestinationFeatures{SkiingScore: 1, WaterSportsScore: 1}
+	tests := []struct {
+		name    string
+		weights map[string]float64
+		// want is the top two, in ID order
+		want []string
+	}{
+		{"skiing boosted", map[string]float64{"skiing_score": 10}, []string{"verbier", "zermatt"}},
+		{"water sports boosted", map[string]float64{"water_sports_score": 10}, []string{"tamarindo", "tulum"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weights, err := NormalizeWeights(tt.weights)
+			if err != nil {
+				t.Fatalf("NormalizeWeights: %v", err)
+			}
+			got := resultIDs(Scorer{Query: query, Weights: weights}.Rank(vibeFixture))
+			if top := slices.Sorted(slices.Values(got[:2])); !slices.Equal(top, tt.want) {
+				t.Errorf("top two = %v, want %v (%v)", top, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNormalizeWeights(t *testing.T) {
+	allZero := map[string]float64{}
+	for _, feat := range Features {
+		allZero[feat.Key] = 0
+	}
+	tests := []struct {
+		name    string
+		weights map[string]float64
+		wantErr string
+	}{
+		{"none", nil, ""},
+		{"boost", map[string]float64{"skiing_score": 3}, ""},
+		{"zero one feature", map[string]float64{"skiing_score": 0}, ""},
+		{"unknown", map[string]float64{"llama_density": 1}, "unknown weight features: [llama_density]"},
+		{"negative", map[string]float64{"skiing_score": -1}, "must not be negative"},
+		{"NaN", map[string]float64{"skiing_score": math.NaN()}, "must be a finite number"},
+		{"infinite", map[string]float64{"skiing_score": math.Inf(1)}, "must be a finite number"},
+		{"all zero", allZero, "weights must not all be zero"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vec, err := NormalizeWeights(tt.weights)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("NormalizeWeights error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeWeights: %v", err)
+			}
+			if vec == nil {
+				return
+			}
+			var sum float64
+			for _, w := range vec {
+				sum += w
+			}
+			if math.Abs(sum-float64(len(Features))) > 1e-9 {
+				t.Errorf("weights sum to %g, want %d", sum, len(Features))
+			}
+		})
+	}
+}
+
+func TestNormalizeWeightsUsesDefaultWeights(t *testing.T) {
+	i := slices.IndexFunc(Features, func(f Feature) bool { return f.Key == "skiing_score" })
+	prev := Features[i].DefaultWeight
+	Features[i].DefaultWeight = 3
+	t.Cleanup(func() { Features[i].DefaultWeight = prev })
+
+	tests := []struct {
+		name    string
+		weights map[string]float64
+		// wantRatio is skiing's weight relative to hiking's
+		wantRatio float64
+	}{
+		{"default applies when unset", nil, 3},
+		{"explicit weight overrides it", map[string]float64{"skiing_score": 1}, 1},
+	}
+	hiking := slices.IndexFunc(Features, func(f Feature) bool { return f.Key == "hiking_score" })
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vec, err := NormalizeWeights(tt.weights)
+			if err != nil {
+				t.Fatalf("NormalizeWeights: %v", err)
+			}
+			if vec == nil {
+				if tt.wantRatio != 1 {
+					t.Fatalf("NormalizeWeights = nil (equal weights), want skiing weighed %gx", tt.wantRatio)
+				}
+				return
+			}
+			if got := vec[i] / vec[hiking]; math.Abs(got-tt.wantRatio) > 1e-9 {
+				t.Errorf("skiing weighs %gx hiking, want %gx", got, tt.wantRatio)
+			}
+		})
+	}
+}
+
+func TestBreakdownSumsToScore(t *testing.T) {
+	constraints, err := ParseQuery("ski")
+	if err != nil {
+		t.Fatalf("ParseQuery: %v", err)
+	}
+	query := QueryFromConstraints(constraints)
+	weights, _ := NormalizeWeights(map[string]float64{"skiing_score": 3, "hiking_score": 0.5})
+	avoid, _ := AvoidVector(map[string]float64{"nightlife_density": 1})
+
+	tests := []struct {
+		name   string
+		scorer Scorer
+	}{
+		{"keyword query", Scorer{Query: query}},
+		{"weighted", Scorer{Query: query, Weights: weights}},
+		{"text blend", Scorer{Query: query, Text: "zermatt", TextBlend: 0.3}},
+		{"avoid penalty", Scorer{Query: query, Avoid: avoid, AvoidPenalty: 0.2}},
+		{"comfort bias", Scorer{Query: query, Comfort: &ComfortBias{MinC: 18, MaxC: 26, Penalty: 0.2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, d := range vibeFixture {
+				var sum float64
+				for _, v := range tt.scorer.Breakdown(d) {
+					sum += v
+				}
+				if score := tt.scorer.Score(d); math.Abs(sum-score) > 1e-9 {
+					t.Errorf("%s: breakdown sums to %v, score is %v", d.ID, sum, score)
+				}
+			}
+		})
+	}
+}
+
+func TestBreakdownTopContributor(t *testing.T) {
+	tests := []struct {
+		query, dest string
+		want        string
+	}{
+		{"ski", "zermatt", "skiing_score"},
+		{"beach", "tamarindo", "water_sports_score"},
+		{"nightlife", "tokyo", "nightlife_density"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			constraints, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery: %v", err)
+			}
+			breakdown := Scorer{Query: QueryFromConstraints(constraints)}.Breakdown(fixture(t, tt.dest))
+			top := ""
+			for key, v := range breakdown {
+				if top == "" || v > breakdown[top] {
+					top = key
+				}
+			}
+			if top != tt.want {
+				t.Errorf("top contributor for %q at %s = %s, want %s (%v)", tt.query, tt.dest, top, tt.want, breakdown)
+			}
+		})
+	}
+}
+
+func TestMissingFeaturesNotPenalized(t *testing.T) {
+	// A beach query with a little skiing, against a beach town with no ski
+	// data and a slightly different beach town that has it
+	query := fixture(t, "tamarindo").Features
+	query.SkiingScore = 0.5
+	unknown := fixture(t, "tamarindo")
+	unknown.ID = "unknown"
+	unknown.MissingFeatures = []string{"skiing_score"}
+	known := fixture(t, "tulum")
+	known.Features.SkiingScore = 0.5
+
+	scorer := Scorer{Query: query}
+	if got := scorer.Score(unknown); math.Abs(got-1) > 1e-9 {
+		t.Errorf("score with skiing_score skipped = %g, want 1", got)
+	}
+	if got := resultIDs(scorer.Rank([]types.Destination{known, unknown})); got[0] != "unknown" {
+		t.Errorf("ranking = %v, want the missing ski score not to count against it", got)
+	}
+
+	// Without the flag the zero reads as no skiing and costs it the top spot
+	unknown.MissingFeatures = nil
+	if got := resultIDs(scorer.Rank([]types.Destination{known, unknown})); got[0] != "tulum" {
+		t.Errorf("unflagged ranking = %v, want tulum first", got)
+	}
+}
+
+func TestQueryMissingFeatures(t *testing.T) {
+	d := fixture(t, "zermatt")
+	query := d.Features
+	query.SkiingScore = 0
+	for _, metric := range []SimilarityFunc{cosineMetric, EuclideanSimilarity} {
+		scorer := Scorer{Query: query, QueryMissing: []string{"skiing_score"}, Metric: metric}
+		if got := scorer.Score(d); math.Abs(got-1) > 1e-9 {
+			t.Errorf("score with the query's skiing_score skipped = %g, want 1", got)
+		}
+	}
+}
+
+func TestSimilarSkipsSourceMissingFeatures(t *testing.T) {
+	source := fixture(t, "zermatt")
+	source.Features.SkiingScore = 0
+	source.MissingFeatures = []string{"skiing_score"}
+	twin := fixture(t, "zermatt")
+	twin.ID = "twin"
+	got := Similar(source, []types.Destination{fixture(t, "verbier"), twin}, 1)
+	if len(got) != 1 || got[0].Destination.ID != "twin" || math.Abs(got[0].Score-1) > 1e-9 {
+		t.Errorf("Similar = %v, want twin with score 1", got)
+	}
+}
+
+func TestFilterByScore(t *testing.T) {
+	results := func() []Result {
+		return []Result{
+			{Destination: types.Destination{ID: "a"}, Score: 0.9},
+			{Destination: types.Destination{ID: "b"}, Score: 0.5},
+			{Destination: types.Destination{ID: "c"}, Score: 0.2},
+		}
+	}
+	tests := []struct {
+		min  float64
+		want []string
+	}{
+		{0, []string{"a", "b", "c"}},
+		// The threshold itself is kept
+		{0.5, []string{"a", "b"}},
+		{0.95, []string{}},
+	}
+	for _, tt := range tests {
+		if got := resultIDs(FilterByScore(results(), tt.min)); !slices.Equal(got, tt.want) {
+			t.Errorf("FilterByScore(%g) = %v, want %v", tt.min, got, tt.want)
+		}
+	}
+}
+
+// syntheticDestinations returns n destinations cycling through the
+// vibeFixture vibes, so many score exactly the same
+func syntheticDestinations(n int) []types.Destination {
+	dests := make([]types.Destination, n)
+	for i := range dests {
+		dests[i] = vibeFixture[i%len(vibeFixture)]
+		dests[i].ID = fmt.Sprintf("dest-%05d", n-i)
+	}
+	return dests
+}
+
+// TestRankParallelMatchesSequential checks that splitting scoring across
+// workers doesn't change the ranking; run with -race
+func TestRankParallelMatchesSequential(t *testing.T) {
+	query := fixture(t, "zermatt").Features
+	tests := []struct {
+		name    string
+		n       int
+		workers int
+	}{
+		{"below the parallel threshold", minParallelScoring - 1, 4},
+		{"even chunks", 1024, 4},
+		{"uneven chunks", 1001, 3},
+		{"more workers than destinations per chunk", 600, 64},
+		{"GOMAXPROCS workers", 2000, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dests := syntheticDestinations(tt.n)
+			want := Scorer{Query: query, Workers: 1}.Rank(dests)
+			got := Scorer{Query: query, Workers: tt.workers}.Rank(dests)
+			if len(got) != len(want) {
+				t.Fatalf("parallel Rank returned %d results, want %d", len(got), len(want))
+			}
+			for i := range want {
+				if got[i].Destination.ID != want[i].Destination.ID || got[i].Score != want[i].Score {
+					t.Fatalf("result %d = %s (%v), want %s (%v)",
+						i, got[i].Destination.ID, got[i].Score, want[i].Destination.ID, want[i].Score)
+				}
+			}
+		})
+	}
+}
+
+func TestRankBreaksTiesByID(t *testing.T) {
+	dests := syntheticDestinations(len(vibeFixture) * 3)
+	results := Scorer{Query: fixture(t, "tokyo").Features}.Rank(dests)
+	for i := 1; i < len(results); i++ {
+		prev, cur := results[i-1], results[i]
+		if prev.Score < cur.Score || (prev.Score == cur.Score && prev.Destination.ID > cur.Destination.ID) {
+			t.Errorf("%s (%v) ranked before %s (%v)", prev.Destination.ID, prev.Score, cur.Destination.ID, cur.Score)
+		}
+	}
+}
+
+func BenchmarkRank(b *testing.B) {
+	dests := syntheticDestinations(20000)
+	query := vibeFixture[0].Features
+	for _, workers := range []int{1, 0} {
+		name := "sequential"
+		if workers == 0 {
+			name = "parallel"
+		}
+		b.Run(name, func(b *testing.B) {
+			s := Scorer{Query: query, Workers: workers}
+			for b.Loop() {
+				s.Rank(dests)
+			}
+		})
+	}
+}
+
+// TestScoreSkipsNonFinite checks that a NaN or infinite value that slipped
+// past loading is left out like a missing feature instead of poisoning the
+// score
+func TestScoreSkipsNonFinite(t *testing.T) {
+	query := fixture(t, "zermatt").Features
+	clean := fixture(t, "verbier")
+	missing := clean
+	missing.Features.SkiingScore = 0
+	missing.MissingFeatures = []string{"skiing_score"}
+	want := Scorer{Query: query}.Score(missing)
+
+	avoid, _ := AvoidVector(map[string]float64{"skiing_score": 1})
+	tests := []struct {
+		name   string
+		scorer Scorer
+		dest   func(*types.DestinationFeatures)
+	}{
+		{"NaN destination value", Scorer{Query: query}, func(f *types.DestinationFeatures) { f.SkiingScore = math.NaN() }},
+		{"infinite destination value", Scorer{Query: query}, func(f *types.DestinationFeatures) { f.SkiingScore = math.Inf(1) }},
+		{"with avoid", Scorer{Query: query, Avoid: avoid, AvoidPenalty: 0.5}, func(f *types.DestinationFeatures) { f.SkiingScore = math.NaN() }},
+		{"with comfort bias", Scorer{Query: query, Comfort: &defaultComfort}, func(f *types.DestinationFeatures) { f.AvgTempC = math.NaN() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := clean
+			tt.dest(&d.Features)
+			score := tt.scorer.Score(d)
+			if !isFinite(score) {
+				t.Fatalf("Score = %v, want a finite score", score)
+			}
+			for key, v := range tt.scorer.Breakdown(d) {
+				if !isFinite(v) {
+					t.Errorf("breakdown %s = %v, want finite", key, v)
+				}
+			}
+		})
+	}
+
+	d := clean
+	d.Features.SkiingScore = math.NaN()
+	if got := (Scorer{Query: query}).Score(d); math.Abs(got-want) > 1e-9 {
+		t.Errorf("NaN skiing scores %v, want %v as if skiing were missing", got, want)
+	}
+
+	nanQuery := query
+	nanQuery.HikingScore = math.NaN()
+	if got := (Scorer{Query: nanQuery}).Score(clean); !isFinite(got) {
+		t.Errorf("NaN query value scores %v, want finite", got)
+	}
+}
+
+func TestScaleMinMax(t *testing.T) {
+	scored := func(scores ...float64) []Result {
+		results := make([]Result, len(scores))
+		for i, s := range scores {
+			results[i] = Result{Destination: types.Destination{ID: fmt.Sprint(i)}, Score: s}
+		}
+		return results
+	}
+	tests := []struct {
+		name string
+		in   []Result
+		want []float64
+	}{
+		{"empty", nil, []float64{}},
+		{"one result", scored(0.97), []float64{1}},
+		{"all tied", scored(0.9, 0.9, 0.9), []float64{1, 1, 1}},
+		{"clustered near 1", scored(0.99, 0.98, 0.96, 0.95), []float64{1, 0.75, 0.25, 0}},
+		{"negative scores", scored(0.5, 0, -0.5), []float64{1, 0.5, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := resultIDs(tt.in)
+			ScaleMinMax(tt.in)
+			if got := resultIDs(tt.in); !slices.Equal(got, ids) {
+				t.Errorf("order = %v, want unchanged %v", got, ids)
+			}
+			for i, res := range tt.in {
+				if math.Abs(res.Score-tt.want[i]) > 1e-9 {
+					t.Errorf("score %d = %g, want %g", i, res.Score, tt.want[i])
+				}
+			}
+		})
+	}
+}
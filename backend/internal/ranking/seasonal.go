@@ -0,0 +1,29 @@
+package ranking
+
+import "github.com/simonryrie/otherwhere/internal/types"
+
+// HasSeasonalData reports whether a destination carries monthly temperatures
+func HasSeasonalData(f types.DestinationFeatures) bool {
+	return f.MonthlyTempC != [12]float64{}
+}
+
+// MonthTemp returns the normalized temperature for a month (1-12), falling
+// back to the annual average when no seasonal data is available
+func MonthTemp(f types.DestinationFeatures, month int) float64 {
+	if !HasSeasonalData(f) {
+		return f.AvgTempC
+	}
+	return f.MonthlyTempC[month-1]
+}
+
+// WithMonthTemp returns copies of dests whose AvgTempC is replaced by the
+// given month's temperature, so constraints and scoring on avg_temp_c apply
+// to that month
+func WithMonthTemp(dests []types.Destination, month int) []types.Destination {
+	out := make([]types.Destination, len(dests))
+	for i, d := range dests {
+		d.Features.AvgTempC = MonthTemp(d.Features, month)
+		out[i] = d
+	}
+	return out
+}
@@ -0,0 +1,41 @@
+package ranking
+
+import (
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestMonthTemp(t *testing.T) {
+	seasonal := types.DestinationFeatures{AvgTempC: 0.5, MonthlyTempC: [12]float64{0.7, 0.7, 0.6, 0.5, 0.4, 0.3, 0.3, 0.4, 0.5, 0.6, 0.6, 0.72}}
+	annual := types.DestinationFeatures{AvgTempC: 0.5}
+	tests := []struct {
+		name     string
+		features types.DestinationFeatures
+		month    int
+		want     float64
+	}{
+		{"January", seasonal, 1, 0.7},
+		{"July", seasonal, 7, 0.3},
+		{"December", seasonal, 12, 0.72},
+		{"no seasonal data falls back to the average", annual, 12, 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MonthTemp(tt.features, tt.month); got != tt.want {
+				t.Errorf("MonthTemp(%d) = %v, want %v", tt.month, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithMonthTempCopies(t *testing.T) {
+	dests := []types.Destination{{ID: "sydney", Features: types.DestinationFeatures{AvgTempC: 0.5, MonthlyTempC: [12]float64{11: 0.7}}}}
+	got := WithMonthTemp(dests, 12)
+	if got[0].Features.AvgTempC != 0.7 {
+		t.Errorf("AvgTempC = %v, want December's 0.7", got[0].Features.AvgTempC)
+	}
+	if dests[0].Features.AvgTempC != 0.5 {
+		t.Error("WithMonthTemp changed its input")
+	}
+}
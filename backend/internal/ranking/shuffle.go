@@ -0,0 +1,20 @@
+package ranking
+
+import "math/rand/v2"
+
+// ShuffleTies shuffles each run of equally scored results using a generator
+// seeded with seed, leaving results with distinct scores where they are. A
+// given seed always produces the same order, so varied results stay
+// reproducible. results must already be sorted by score.
+func ShuffleTies(results []Result, seed uint64) {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	for lo := 0; lo < len(results); {
+		hi := lo + 1
+		for hi < len(results) && results[hi].Score == results[lo].Score {
+			hi++
+		}
+		tie := results[lo:hi]
+		rng.Shuffle(len(tie), func(i, j int) { tie[i], tie[j] = tie[j], tie[i] })
+		lo = hi
+	}
+}
@@ -0,0 +1,67 @@
+package ranking
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// tiedResults returns results sorted by score with a run of six ties in
+// the middle and three at the end
+func tiedResults() []Result {
+	scores := []float64{0.9, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.3, 0.1, 0.1, 0.1}
+	results := make([]Result, len(scores))
+	for i, s := range scores {
+		results[i] = Result{Destination: types.Destination{ID: fmt.Sprintf("d%02d", i)}, Score: s}
+	}
+	return results
+}
+
+func TestShuffleTies(t *testing.T) {
+	original := resultIDs(tiedResults())
+	var shuffled bool
+	for seed := range uint64(20) {
+		results := tiedResults()
+		ShuffleTies(results, seed)
+		got := resultIDs(results)
+
+		again := tiedResults()
+		ShuffleTies(again, seed)
+		if !slices.Equal(resultIDs(again), got) {
+			t.Fatalf("seed %d: %v then %v, want the same order both times", seed, got, resultIDs(again))
+		}
+
+		for i, res := range results {
+			if res.Score != tiedResults()[i].Score {
+				t.Fatalf("seed %d: result %d scores %v, want scores kept in order", seed, i, res.Score)
+			}
+		}
+		// Results with a score of their own never move
+		for _, i := range []int{0, 7} {
+			if got[i] != original[i] {
+				t.Errorf("seed %d: untied result %d = %s, want %s", seed, i, got[i], original[i])
+			}
+		}
+		if !slices.Equal(slices.Sorted(slices.Values(got[1:7])), original[1:7]) {
+			t.Errorf("seed %d: tie group = %v, want a permutation of %v", seed, got[1:7], original[1:7])
+		}
+		shuffled = shuffled || !slices.Equal(got, original)
+	}
+	if !shuffled {
+		t.Error("no seed reordered any tie group")
+	}
+}
+
+func TestShuffleTiesNoTies(t *testing.T) {
+	results := []Result{{Score: 0.9}, {Score: 0.5}, {Score: 0.1}}
+	for i := range results {
+		results[i].Destination.ID = fmt.Sprint(i)
+	}
+	ShuffleTies(results, 42)
+	if got := resultIDs(results); !slices.Equal(got, []string{"0", "1", "2"}) {
+		t.Errorf("distinct scores reordered to %v", got)
+	}
+	ShuffleTies(nil, 42)
+}
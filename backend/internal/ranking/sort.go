@@ -0,0 +1,49 @@
+package ranking
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// sortKeys maps sort parameter names to ascending comparisons
+var sortKeys = map[string]func(a, b types.Destination) int{
+	"name": func(a, b types.Destination) int {
+		return cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	},
+	"population": func(a, b types.Destination) int {
+		return cmp.Compare(a.Features.Population, b.Features.Population)
+	},
+	"temp": func(a, b types.Destination) int {
+		return cmp.Compare(a.Features.AvgTempC, b.Features.AvgTempC)
+	},
+}
+
+// DefaultSort orders destinations alphabetically by name
+const DefaultSort = "name"
+
+// SortDestinations stably sorts dests by the named key, descending when the
+// key has a leading "-"
+func SortDestinations(dests []types.Destination, key string) error {
+	desc := strings.HasPrefix(key, "-")
+	compare, ok := sortKeys[strings.TrimPrefix(key, "-")]
+	if !ok {
+		valid := make([]string, 0, len(sortKeys))
+		for k := range sortKeys {
+			valid = append(valid, k)
+		}
+		slices.Sort(valid)
+		return fmt.Errorf("unknown sort key %q, expected one of %s (prefix with - for descending)", key, strings.Join(valid, ", "))
+	}
+
+	slices.SortStableFunc(dests, func(a, b types.Destination) int {
+		if desc {
+			return compare(b, a)
+		}
+		return compare(a, b)
+	})
+	return nil
+}
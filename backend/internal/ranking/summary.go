@@ -0,0 +1,106 @@
+package ranking
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// maxSummaryDescriptors caps how many descriptors a summary lists
+const maxSummaryDescriptors = 3
+
+// descriptor names a destination's feature value at either end of the
+// normalized scale: Low applies at or below LowAt and High at or above
+// HighAt. An empty phrase means that end isn't worth mentioning.
+type descriptor struct {
+	Low    string
+	LowAt  float64
+	High   string
+	HighAt float64
+}
+
+// descriptors phrase the features a summary can mention. Thresholds follow
+// the vibe profiles in docs/SCHEMA.md where one exists.
+var descriptors = map[string]descriptor{
+	"avg_temp_c":            {Low: "cool", LowAt: 0.4, High: "warm", HighAt: 0.58},
+	"tourism_density":       {Low: "off the beaten path", LowAt: 0.3, High: "touristy", HighAt: 0.7},
+	"wikipedia_pageviews":   {Low: "little-known", LowAt: 0.2, High: "world-famous", HighAt: 0.8},
+	"accommodation_density": {Low: "few places to stay", LowAt: 0.2, High: "plenty of places to stay", HighAt: 0.7},
+	"population":            {Low: "small-town", LowAt: 0.4, High: "big-city", HighAt: 0.5},
+	"coast_distance_km":     {Low: "coastal", LowAt: 0.01, High: "inland", HighAt: 0.5},
+	"nature_ratio":          {Low: "urban", LowAt: 0.2, High: "green", HighAt: 0.6},
+	"elevation":             {High: "mountainous", HighAt: 0.2},
+	"skiing_score":          {High: "great skiing", HighAt: 0.6},
+	"water_sports_score":    {High: "water sports", HighAt: 0.6},
+	"hiking_score":          {High: "great hiking", HighAt: 0.6},
+	"wildlife_score":        {High: "rich wildlife", HighAt: 0.6},
+	"nightlife_density":     {Low: "quiet nights", LowAt: 0.4, High: "lively nightlife", HighAt: 0.6},
+	"development_level":     {Low: "undeveloped", LowAt: 0.3, High: "well-developed", HighAt: 0.8},
+	"gdp_per_capita":        {Low: "budget-friendly", LowAt: 0.5},
+}
+
+// phrase describes value when it's far enough towards one end, provided the
+// query asks for that end too (target on the same side of the midpoint)
+func (d descriptor) phrase(value, target float64) string {
+	switch {
+	case d.High != "" && value >= d.HighAt && target >= 0.5:
+		return d.High
+	case d.Low != "" && value <= d.LowAt && target < 0.5:
+		return d.Low
+	}
+	return ""
+}
+
+// Summary is a short phrase such as "Warm, coastal, lively nightlife" giving
+// the reasons d matches the query: descriptors for up to three features the
+// query targets, ordered by how closely d agrees with the query on each,
+// weighted like the score. Ties keep Features order, so the same inputs
+// always give the same summary. Features in hidden are never mentioned. It's
+// empty when nothing stands out.
+func (s Scorer) Summary(d types.Destination, hidden []string) string {
+	query, dest, weights := s.vectors(d)
+
+	type reason struct {
+		phrase    string
+		relevance float64
+	}
+	var reasons []reason
+	for i, feat := range Features {
+		desc, ok := descriptors[feat.Key]
+		if !ok || query[i] == 0 || (weights != nil && weights[i] == 0) || slices.Contains(hidden, feat.Key) {
+			continue
+		}
+		phrase := desc.phrase(dest[i], query[i])
+		if phrase == "" {
+			continue
+		}
+		w := 1.0
+		if weights != nil {
+			w = weights[i]
+		}
+		reasons = append(reasons, reason{phrase, w * (1 - math.Abs(query[i]-dest[i]))})
+	}
+	slices.SortStableFunc(reasons, func(a, b reason) int {
+		return cmp.Compare(b.relevance, a.relevance)
+	})
+
+	phrases := make([]string, 0, maxSummaryDescriptors)
+	for _, r := range reasons[:min(len(reasons), maxSummaryDescriptors)] {
+		phrases = append(phrases, r.phrase)
+	}
+	return capitalize(strings.Join(phrases, ", "))
+}
+
+// capitalize upper-cases the first letter of s
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
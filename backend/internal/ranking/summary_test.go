@@ -0,0 +1,51 @@
+package ranking
+
+import (
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+func TestSummary(t *testing.T) {
+	// A beach-party search: hot, on the coast, out all night
+	beachParty := types.DestinationFeatures{AvgTempC: 0.8, CoastDistanceKm: 0.01, NightlifeDensity: 0.9}
+	withWater := beachParty
+	withWater.WaterSportsScore = 0.9
+
+	tests := []struct {
+		name    string
+		query   types.DestinationFeatures
+		weights map[string]float64
+		dest    string
+		hidden  []string
+		want    string
+	}{
+		{"beach party", beachParty, nil, "tulum", nil, "Coastal, warm, lively nightlife"},
+		{"at most three, ties in Features order", withWater, nil, "tulum", nil, "Coastal, warm, water sports"},
+		{"weights reorder", beachParty, map[string]float64{"avg_temp_c": 1, "coast_distance_km": 1, "nightlife_density": 2}, "tulum", nil, "Lively nightlife, coastal, warm"},
+		{"zero weight left out", beachParty, map[string]float64{"avg_temp_c": 1, "coast_distance_km": 1, "nightlife_density": 0}, "tulum", nil, "Coastal, warm"},
+		{"hidden features left out", beachParty, nil, "tulum", []string{"coast_distance_km"}, "Warm, lively nightlife"},
+		{"only ends the query asks for", beachParty, nil, "zermatt", nil, ""},
+		{"empty query", types.DestinationFeatures{}, nil, "tulum", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Scorer{Query: tt.query}
+			if tt.weights != nil {
+				w, err := NormalizeWeights(tt.weights)
+				if err != nil {
+					t.Fatalf("NormalizeWeights: %v", err)
+				}
+				s.Weights = w
+			}
+			d := fixture(t, tt.dest)
+			got := s.Summary(d, tt.hidden)
+			if got != tt.want {
+				t.Errorf("Summary = %q, want %q", got, tt.want)
+			}
+			if again := s.Summary(d, tt.hidden); again != got {
+				t.Errorf("Summary changed between calls: %q, then %q", got, again)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package ranking
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// DefaultTextBlend is the share of the final score given to the text match
+// when a query falls back to name matching
+const DefaultTextBlend = 0.7
+
+// Relative importance of each field a text query is matched against
+const (
+	nameWeight        = 1.0
+	countryWeight     = 0.8
+	descriptionWeight = 0.5
+)
+
+// foldText lowercases s and strips accents so "Medellín" matches "medellin"
+func foldText(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// TextScore rates how well a free-text query names a destination, in [0, 1].
+// Exact and substring matches score highest; otherwise the closest word by
+// edit distance counts, so misspellings still match.
+func TextScore(query string, d types.Destination) float64 {
+	q := foldText(query)
+	if q == "" {
+		return 0
+	}
+
+	score := nameWeight * fieldScore(q, d.Name)
+	score = max(score, countryWeight*fieldScore(q, d.Country))
+	if d.Description != nil {
+		score = max(score, descriptionWeight*fieldScore(q, *d.Description))
+	}
+	return score
+}
+
+// fieldScore compares a folded query against one text field
+func fieldScore(q, field string) float64 {
+	f := foldText(field)
+	switch {
+	case f == "":
+		return 0
+	case f == q:
+		return 1
+	case strings.Contains(f, q):
+		return 0.9
+	}
+
+	best := similarity(q, f)
+	for _, word := range tokenize(f) {
+		best = max(best, similarity(q, word))
+	}
+	return best
+}
+
+// similarity converts edit distance into a [0, 1] score relative to the
+// longer string's length
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 0
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
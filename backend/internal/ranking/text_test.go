@@ -0,0 +1,63 @@
+package ranking
+
+import (
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// namedFixture has names that are easy to confuse with each other
+var namedFixture = []types.Destination{
+	{ID: "medellin", Name: "Medellín", Country: "Colombia", Description: new("City of eternal spring")},
+	{ID: "zurich", Name: "Zürich", Country: "Switzerland"},
+	{ID: "kyoto", Name: "Kyoto", Country: "Japan", Description: new("Temples and gardens")},
+	{ID: "kyiv", Name: "Kyiv", Country: "Ukraine"},
+	{ID: "porto", Name: "Porto", Country: "Portugal"},
+	{ID: "portland", Name: "Portland", Country: "United States"},
+}
+
+func TestTextScoreRanksMisspellings(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"Kyoto", "kyoto"},
+		{"kyotto", "kyoto"},
+		{"kiev", "kyiv"},
+		{"medellin", "medellin"},
+		{"Medelin", "medellin"},
+		{"zurich", "zurich"},
+		{"Zurick", "zurich"},
+		{"portlnd", "portland"},
+		{"Porto", "porto"},
+		{"colombia", "medellin"},
+		{"temples", "kyoto"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			results := Scorer{Text: tt.query, TextBlend: 1}.Rank(namedFixture)
+			if got := results[0].Destination.ID; got != tt.want {
+				t.Errorf("top match for %q = %s, want %s (%v)", tt.query, got, tt.want, resultIDs(results))
+			}
+		})
+	}
+}
+
+func TestTextScore(t *testing.T) {
+	d := namedFixture[0]
+	tests := []struct {
+		query string
+		want  float64
+	}{
+		{"", 0},
+		{"Medellín", 1},
+		{"  MEDELLIN ", 1},
+		{"medel", 0.9},
+		{"colombia", countryWeight},
+	}
+	for _, tt := range tests {
+		if got := TextScore(tt.query, d); got != tt.want {
+			t.Errorf("TextScore(%q) = %g, want %g", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package ranking
+
+import (
+	"fmt"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// DefaultTierThresholds split destinations into roughly the least visited
+// quarter, the middle half, and the best known quarter of the dataset
+var DefaultTierThresholds = TierThresholds{HiddenGemBelow: 0.35, IconicFrom: 0.75}
+
+// TierThresholds classify destinations by popularity, the mean of their
+// normalized wikipedia_pageviews and tourism_density. Destinations below
+// HiddenGemBelow are hidden gems, those at or above IconicFrom are iconic,
+// and everything in between is popular.
+type TierThresholds struct {
+	HiddenGemBelow float64
+	IconicFrom     float64
+}
+
+// Tiers lists the popularity tiers from least to most visited
+var Tiers = []types.PopularityTier{types.TierHiddenGem, types.TierPopular, types.TierIconic}
+
+// Tier classifies d
+func (t TierThresholds) Tier(d types.Destination) types.PopularityTier {
+	switch p := (d.Features.WikipediaPageviews + d.Features.TourismDensity) / 2; {
+	case p < t.HiddenGemBelow:
+		return types.TierHiddenGem
+	case p >= t.IconicFrom:
+		return types.TierIconic
+	default:
+		return types.TierPopular
+	}
+}
+
+// ParseTier validates a tier name, listing the valid ones when it's unknown
+func ParseTier(name string) (types.PopularityTier, error) {
+	for _, tier := range Tiers {
+		if string(tier) == name {
+			return tier, nil
+		}
+	}
+	return "", fmt.Errorf("tier must be one of %s, %s, or %s, got %q", Tiers[0], Tiers[1], Tiers[2], name)
+}
+
+// FilterByTier keeps the destinations in tier
+func FilterByTier(dests []types.Destination, tier types.PopularityTier, t TierThresholds) []types.Destination {
+	filtered := make([]types.Destination, 0, len(dests))
+	for _, d := range dests {
+		if t.Tier(d) == tier {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,84 @@
+package ranking
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// withPopularity returns a destination with the given normalized pageviews
+// and tourism density
+func withPopularity(id string, pageviews, tourism float64) types.Destination {
+	return types.Destination{ID: id, Features: types.DestinationFeatures{WikipediaPageviews: pageviews, TourismDensity: tourism}}
+}
+
+func TestTier(t *testing.T) {
+	tests := []struct {
+		name               string
+		pageviews, tourism float64
+		want               types.PopularityTier
+	}{
+		{"unvisited", 0, 0, types.TierHiddenGem},
+		{"quiet", 0.2, 0.3, types.TierHiddenGem},
+		{"famous but uncrowded", 0.6, 0, types.TierHiddenGem},
+		{"at the hidden gem threshold", 0.35, 0.35, types.TierPopular},
+		{"middling", 0.5, 0.6, types.TierPopular},
+		{"just below iconic", 0.74, 0.75, types.TierPopular},
+		{"at the iconic threshold", 0.75, 0.75, types.TierIconic},
+		{"world famous", 1, 0.9, types.TierIconic},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultTierThresholds.Tier(withPopularity("d", tt.pageviews, tt.tourism)); got != tt.want {
+				t.Errorf("Tier(%v, %v) = %s, want %s", tt.pageviews, tt.tourism, got, tt.want)
+			}
+		})
+	}
+
+	custom := TierThresholds{HiddenGemBelow: 0.1, IconicFrom: 0.5}
+	if got := custom.Tier(withPopularity("d", 0.5, 0.5)); got != types.TierIconic {
+		t.Errorf("custom thresholds: Tier = %s, want %s", got, types.TierIconic)
+	}
+}
+
+func TestParseTier(t *testing.T) {
+	for _, tier := range Tiers {
+		if got, err := ParseTier(string(tier)); err != nil || got != tier {
+			t.Errorf("ParseTier(%q) = %q, %v", tier, got, err)
+		}
+	}
+	for _, name := range []string{"hidden gem", "Iconic", "obscure"} {
+		if _, err := ParseTier(name); err == nil || !strings.Contains(err.Error(), "tier must be one of hidden_gem, popular, or iconic") {
+			t.Errorf("ParseTier(%q) error = %v, want the valid tiers listed", name, err)
+		}
+	}
+}
+
+func TestFilterByTier(t *testing.T) {
+	dests := []types.Destination{
+		withPopularity("lofoten", 0.2, 0.2),
+		withPopularity("tokyo", 1, 0.9),
+		withPopularity("faroe", 0.1, 0.05),
+		withPopularity("lisbon", 0.5, 0.6),
+	}
+	tests := []struct {
+		tier types.PopularityTier
+		want []string
+	}{
+		{types.TierHiddenGem, []string{"lofoten", "faroe"}},
+		{types.TierPopular, []string{"lisbon"}},
+		{types.TierIconic, []string{"tokyo"}},
+	}
+	for _, tt := range tests {
+		got := FilterByTier(dests, tt.tier, DefaultTierThresholds)
+		ids := make([]string, len(got))
+		for i, d := range got {
+			ids[i] = d.ID
+		}
+		if !slices.Equal(ids, tt.want) {
+			t.Errorf("FilterByTier(%s) = %v, want %v", tt.tier, ids, tt.want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package ranking
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// NormalizeWeights turns per-feature weights keyed by feature name into a
+// weight vector ordered like Features. Unlisted features default to their
+// DefaultWeight, and the result is rescaled to sum to len(Features) so scores
+// stay comparable across queries regardless of the absolute weights a client
+// sends. It returns nil, meaning equal weights, when nothing differs from 1.
+func NormalizeWeights(weights map[string]float64) ([]float64, error) {
+	if len(weights) == 0 && !slices.ContainsFunc(Features, func(f Feature) bool { return f.DefaultWeight != 1 }) {
+		return nil, nil
+	}
+
+	var unknown []string
+	for key, w := range weights {
+		if _, ok := FeatureByKey(key); !ok {
+			unknown = append(unknown, key)
+			continue
+		}
+		if !isFinite(w) {
+			return nil, fmt.Errorf("weight for %s must be a finite number, got %g", key, w)
+		}
+		if w < 0 {
+			return nil, fmt.Errorf("weight for %s must not be negative", key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown weight features: %v", unknown)
+	}
+
+	vec := make([]float64, len(Features))
+	var sum float64
+	for i, feat := range Features {
+		w, ok := weights[feat.Key]
+		if !ok {
+			w = feat.DefaultWeight
+		}
+		vec[i] = w
+		sum += w
+	}
+	if sum == 0 {
+		return nil, errors.New("weights must not all be zero")
+	}
+
+	scale := float64(len(Features)) / sum
+	for i := range vec {
+		vec[i] *= scale
+	}
+	return vec, nil
+}
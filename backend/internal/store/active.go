@@ -0,0 +1,74 @@
+package store
+
+import (
+	"context"
+	"slices"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// ActiveStore wraps a DestinationStore, hiding inactive destinations as if
+// they didn't exist
+type ActiveStore struct {
+	inner DestinationStore
+}
+
+// NewActiveStore creates an ActiveStore around inner
+func NewActiveStore(inner DestinationStore) *ActiveStore {
+	return &ActiveStore{inner: inner}
+}
+
+// List lists the wrapped store's active destinations
+func (s *ActiveStore) List(ctx context.Context) ([]types.Destination, error) {
+	destinations, err := s.inner.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return activeOnly(destinations), nil
+}
+
+// Get fetches a destination from the wrapped store, returning ErrNotFound
+// for an inactive one
+func (s *ActiveStore) Get(ctx context.Context, id string) (types.Destination, error) {
+	d, err := s.inner.Get(ctx, id)
+	if err != nil {
+		return types.Destination{}, err
+	}
+	if !d.Active {
+		return types.Destination{}, ErrNotFound
+	}
+	return d, nil
+}
+
+// GetMany fetches destinations from the wrapped store, leaving out inactive
+// ones like missing ones
+func (s *ActiveStore) GetMany(ctx context.Context, ids []string) ([]types.Destination, error) {
+	destinations, err := s.inner.GetMany(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return activeOnly(destinations), nil
+}
+
+// Reload reloads the wrapped store, failing with ErrNotReloadable when it
+// can't reload
+func (s *ActiveStore) Reload(ctx context.Context) (before, after int, err error) {
+	r, ok := s.inner.(Reloader)
+	if !ok {
+		return 0, 0, ErrNotReloadable
+	}
+	return r.Reload(ctx)
+}
+
+// Version is the wrapped store's dataset version, or empty when it has none
+func (s *ActiveStore) Version() string {
+	if v, ok := s.inner.(Versioner); ok {
+		return v.Version()
+	}
+	return ""
+}
+
+// activeOnly returns the active destinations, without modifying dests
+func activeOnly(dests []types.Destination) []types.Destination {
+	return slices.DeleteFunc(slices.Clone(dests), func(d types.Destination) bool { return !d.Active })
+}
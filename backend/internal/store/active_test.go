@@ -0,0 +1,87 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// withInactive returns testDestinations with kyoto hidden
+func withInactive() []types.Destination {
+	dests := slices.Clone(testDestinations)
+	dests[1].Active = false
+	return dests
+}
+
+func TestActiveStore(t *testing.T) {
+	dests := withInactive()
+	s := NewActiveStore(NewMemoryStore(dests))
+	ctx := context.Background()
+
+	list, err := s.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if got := destinationIDs(list); !slices.Equal(got, []string{"lisbon", "cusco"}) {
+		t.Errorf("List = %v, want the active destinations", got)
+	}
+
+	tests := []struct {
+		id      string
+		wantErr error
+	}{
+		{"lisbon", nil},
+		{"kyoto", ErrNotFound},
+		{"atlantis", ErrNotFound},
+	}
+	for _, tt := range tests {
+		if _, err := s.Get(ctx, tt.id); !errors.Is(err, tt.wantErr) {
+			t.Errorf("Get(%q) error = %v, want %v", tt.id, err, tt.wantErr)
+		}
+	}
+
+	many, err := s.GetMany(ctx, []string{"kyoto", "cusco", "lisbon"})
+	if err != nil {
+		t.Fatalf("GetMany: %v", err)
+	}
+	if got := destinationIDs(many); !slices.Equal(got, []string{"cusco", "lisbon"}) {
+		t.Errorf("GetMany = %v, want the active ones in order", got)
+	}
+
+	if dests[1].Active {
+		t.Error("ActiveStore changed the wrapped store's destinations")
+	}
+}
+
+func TestActiveStoreDelegates(t *testing.T) {
+	inner := NewMemoryStore(withInactive())
+	s := NewActiveStore(inner)
+	if got, want := s.Version(), inner.Version(); got != want {
+		t.Errorf("Version = %q, want the wrapped store's %q", got, want)
+	}
+	// Without a seed file the memory store can't reload, which isn't
+	// ErrNotReloadable
+	if _, _, err := s.Reload(context.Background()); err == nil || errors.Is(err, ErrNotReloadable) {
+		t.Errorf("Reload error = %v, want the wrapped store's", err)
+	}
+
+	hidden := NewActiveStore(struct{ DestinationStore }{inner})
+	if v := hidden.Version(); v != "" {
+		t.Errorf("Version without a Versioner = %q, want empty", v)
+	}
+	if _, _, err := hidden.Reload(context.Background()); !errors.Is(err, ErrNotReloadable) {
+		t.Errorf("Reload without a Reloader error = %v, want ErrNotReloadable", err)
+	}
+}
+
+// destinationIDs lists the IDs of dests in order
+func destinationIDs(dests []types.Destination) []string {
+	ids := make([]string, len(dests))
+	for i, d := range dests {
+		ids[i] = d.ID
+	}
+	return ids
+}
@@ -0,0 +1,113 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// CachingStore wraps a DestinationStore, caching List results for a TTL.
+// Get calls pass straight through to the wrapped store.
+type CachingStore struct {
+	inner DestinationStore
+	ttl   time.Duration
+	now   func() time.Time
+
+	mu        sync.RWMutex
+	cached    []types.Destination
+	version   string
+	fetchedAt time.Time
+	valid     bool
+}
+
+// NewCachingStore creates a CachingStore that refetches from inner once the
+// cached list is older than ttl
+func NewCachingStore(inner DestinationStore, ttl time.Duration) *CachingStore {
+	return &CachingStore{inner: inner, ttl: ttl, now: time.Now}
+}
+
+// List returns the cached destinations, refreshing them from the wrapped
+// store when expired. Concurrent callers that find the cache expired wait
+// for a single refresh instead of each hitting the wrapped store.
+func (s *CachingStore) List(ctx context.Context) ([]types.Destination, error) {
+	s.mu.RLock()
+	if s.fresh() {
+		out := slices.Clone(s.cached)
+		s.mu.RUnlock()
+		return out, nil
+	}
+	s.mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Another caller may have refreshed while we waited for the lock
+	if s.fresh() {
+		return slices.Clone(s.cached), nil
+	}
+
+	destinations, err := s.inner.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.cached = destinations
+	s.version = datasetVersion(destinations)
+	s.fetchedAt = s.now()
+	s.valid = true
+	return slices.Clone(destinations), nil
+}
+
+// Get returns the destination with the given ID from the wrapped store
+func (s *CachingStore) Get(ctx context.Context, id string) (types.Destination, error) {
+	return s.inner.Get(ctx, id)
+}
+
+// GetMany fetches destinations from the wrapped store
+func (s *CachingStore) GetMany(ctx context.Context, ids []string) ([]types.Destination, error) {
+	return s.inner.GetMany(ctx, ids)
+}
+
+// Invalidate drops the cached list so the next List refetches
+func (s *CachingStore) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.valid = false
+	s.cached = nil
+	s.version = ""
+}
+
+// Version identifies the cached list, which is what List serves; it's empty
+// until the first List
+func (s *CachingStore) Version() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.version
+}
+
+// Reload reloads the wrapped store when it supports it, then refetches the
+// cached list. For other stores this just forces a cache refresh.
+func (s *CachingStore) Reload(ctx context.Context) (before, after int, err error) {
+	s.mu.RLock()
+	before = len(s.cached)
+	s.mu.RUnlock()
+
+	if r, ok := s.inner.(Reloader); ok {
+		if before, _, err = r.Reload(ctx); err != nil {
+			return 0, 0, err
+		}
+	}
+	s.Invalidate()
+	destinations, err := s.List(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	return before, len(destinations), nil
+}
+
+// fresh reports whether the cached list can be served; callers hold mu
+func (s *CachingStore) fresh() bool {
+	return s.valid && s.now().Sub(s.fetchedAt) < s.ttl
+}
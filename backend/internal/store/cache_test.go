@@ -0,0 +1,165 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// countingStore is a MemoryStore that counts List calls and can be made to fail
+type countingStore struct {
+	*MemoryStore
+	lists atomic.Int32
+	err   error
+}
+
+func (s *countingStore) List(ctx context.Context) ([]types.Destination, error) {
+	s.lists.Add(1)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.MemoryStore.List(ctx)
+}
+
+// fakeClock is a settable time source
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// newTestCache returns a CachingStore over a countingStore, on a fake clock
+func newTestCache(ttl time.Duration) (*CachingStore, *countingStore, *fakeClock) {
+	inner := &countingStore{MemoryStore: NewMemoryStore(testDestinations)}
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cache := NewCachingStore(inner, ttl)
+	cache.now = clock.Now
+	return cache, inner, clock
+}
+
+func TestCachingStoreList(t *testing.T) {
+	tests := []struct {
+		name string
+		// elapsed are the clock advances before each List after the first
+		elapsed   []time.Duration
+		wantLists int32
+	}{
+		{"first list fetches", nil, 1},
+		{"hits within the TTL", []time.Duration{time.Second, 30 * time.Second}, 1},
+		{"refetches at the TTL", []time.Duration{time.Minute}, 2},
+		{"refetches after expiry, then hits again", []time.Duration{2 * time.Minute, time.Second}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, inner, clock := newTestCache(time.Minute)
+			ctx := context.Background()
+			for i := 0; i <= len(tt.elapsed); i++ {
+				if i > 0 {
+					clock.Advance(tt.elapsed[i-1])
+				}
+				got, err := cache.List(ctx)
+				if err != nil {
+					t.Fatalf("List: %v", err)
+				}
+				if len(got) != len(testDestinations) {
+					t.Fatalf("List returned %d destinations, want %d", len(got), len(testDestinations))
+				}
+			}
+			if n := inner.lists.Load(); n != tt.wantLists {
+				t.Errorf("underlying List called %d times, want %d", n, tt.wantLists)
+			}
+		})
+	}
+}
+
+func TestCachingStoreListIsCopied(t *testing.T) {
+	cache, _, _ := newTestCache(time.Minute)
+	first, _ := cache.List(context.Background())
+	first[0].Name = "changed"
+	second, _ := cache.List(context.Background())
+	if second[0].Name == "changed" {
+		t.Error("a caller's change leaked into the cache")
+	}
+}
+
+func TestCachingStoreInvalidate(t *testing.T) {
+	cache, inner, _ := newTestCache(time.Hour)
+	cache.List(context.Background())
+	cache.Invalidate()
+	cache.List(context.Background())
+	if n := inner.lists.Load(); n != 2 {
+		t.Errorf("underlying List called %d times, want 2", n)
+	}
+}
+
+func TestCachingStoreDoesNotCacheErrors(t *testing.T) {
+	cache, inner, _ := newTestCache(time.Hour)
+	inner.err = errors.New("backend down")
+	if _, err := cache.List(context.Background()); err == nil {
+		t.Fatal("List succeeded, want the backend's error")
+	}
+	inner.err = nil
+	if _, err := cache.List(context.Background()); err != nil {
+		t.Fatalf("List after recovery: %v", err)
+	}
+	if n := inner.lists.Load(); n != 2 {
+		t.Errorf("underlying List called %d times, want 2", n)
+	}
+}
+
+func TestCachingStoreSingleRefresh(t *testing.T) {
+	cache, inner, _ := newTestCache(time.Hour)
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Go(func() { cache.List(context.Background()) })
+	}
+	wg.Wait()
+	if n := inner.lists.Load(); n != 1 {
+		t.Errorf("underlying List called %d times by concurrent misses, want 1", n)
+	}
+}
+
+func TestCachingStoreVersion(t *testing.T) {
+	cache, inner, clock := newTestCache(time.Minute)
+	if v := cache.Version(); v != "" {
+		t.Errorf("Version before the first List = %q, want empty", v)
+	}
+	cache.List(context.Background())
+	want := datasetVersion(testDestinations)
+	if v := cache.Version(); v != want {
+		t.Errorf("Version = %q, want the cached list's %q", v, want)
+	}
+
+	// The cache keeps serving, and reporting, the old list until it expires
+	inner.MemoryStore = NewMemoryStore(testDestinations[:1])
+	cache.List(context.Background())
+	if v := cache.Version(); v != want {
+		t.Errorf("Version within the TTL = %q, want %q", v, want)
+	}
+	clock.Advance(time.Minute)
+	cache.List(context.Background())
+	if v := cache.Version(); v != datasetVersion(testDestinations[:1]) {
+		t.Errorf("Version after a refresh = %q, want the new list's", v)
+	}
+
+	cache.Invalidate()
+	if v := cache.Version(); v != "" {
+		t.Errorf("Version after Invalidate = %q, want empty", v)
+	}
+}
@@ -0,0 +1,54 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+
+	"github.com/simonryrie/otherwhere/internal/config"
+)
+
+// Store backends selectable with STORE_BACKEND
+const (
+	// BackendMemory loads the seed file once at startup
+	BackendMemory = "memory"
+	// BackendFile serves the seed file and rereads it on reload
+	BackendFile = "file"
+	// BackendFirestore reads a Firestore collection
+	BackendFirestore = "firestore"
+)
+
+// NewStore creates the destination store selected by cfg.StoreBackend,
+// checking that the settings that backend needs are present. Stores that
+// hold resources implement io.Closer.
+func NewStore(cfg config.Config) (DestinationStore, error) {
+	switch cfg.StoreBackend {
+	case BackendMemory, BackendFile:
+		if cfg.SeedFile == "" {
+			return nil, fmt.Errorf("store backend %s requires SEED_FILE", cfg.StoreBackend)
+		}
+		if cfg.StoreBackend == BackendFile {
+			return NewMemoryStoreFromFile(cfg.SeedFile, ImagePolicy(cfg.InvalidImages), NonFinitePolicy(cfg.NonFiniteFeatures))
+		}
+		destinations, err := LoadDestinationsFromFile(cfg.SeedFile, ImagePolicy(cfg.InvalidImages), NonFinitePolicy(cfg.NonFiniteFeatures))
+		if err != nil {
+			return nil, err
+		}
+		return NewMemoryStore(destinations), nil
+	case BackendFirestore:
+		if cfg.FirestoreProjectID == "" {
+			return nil, fmt.Errorf("store backend %s requires FIRESTORE_PROJECT_ID", cfg.StoreBackend)
+		}
+		client, err := firestore.NewClient(context.Background(), cfg.FirestoreProjectID)
+		if err != nil {
+			return nil, fmt.Errorf("create firestore client: %w", err)
+		}
+		s := NewFirestoreStore(client, cfg.FirestoreCollection)
+		s.ownsClient = true
+		return s, nil
+	default:
+		return nil, fmt.Errorf("unknown STORE_BACKEND %q, must be %s, %s, or %s",
+			cfg.StoreBackend, BackendMemory, BackendFile, BackendFirestore)
+	}
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/config"
+)
+
+func TestNewStore(t *testing.T) {
+	seed := writeSeed(t, `[{"id":"lisbon","name":"Lisbon","continent":"Europe","location":{"lat":38.72,"lon":-9.14}}]`)
+	// The emulator needs no credentials, and the client doesn't connect
+	// until it's used
+	t.Setenv("FIRESTORE_EMULATOR_HOST", "localhost:1")
+
+	tests := []struct {
+		name string
+		cfg  config.Config
+		// check inspects the store built for cfg
+		check func(t *testing.T, s DestinationStore)
+	}{
+		{"memory", config.Config{StoreBackend: BackendMemory, SeedFile: seed}, func(t *testing.T, s DestinationStore) {
+			m, ok := s.(*MemoryStore)
+			if !ok {
+				t.Fatalf("store is %T, want *MemoryStore", s)
+			}
+			// Loaded once, so there's nothing to reread
+			if _, _, err := m.Reload(context.Background()); err == nil {
+				t.Error("memory backend reloaded, want it fixed at startup")
+			}
+		}},
+		{"file", config.Config{StoreBackend: BackendFile, SeedFile: seed}, func(t *testing.T, s DestinationStore) {
+			m, ok := s.(*MemoryStore)
+			if !ok {
+				t.Fatalf("store is %T, want *MemoryStore", s)
+			}
+			if _, after, err := m.Reload(context.Background()); err != nil || after != 1 {
+				t.Errorf("Reload = %d, %v; want the seed file reread", after, err)
+			}
+		}},
+		{"firestore", config.Config{StoreBackend: BackendFirestore, FirestoreProjectID: "otherwhere-test", FirestoreCollection: "destinations"}, func(t *testing.T, s DestinationStore) {
+			if _, ok := s.(*FirestoreStore); !ok {
+				t.Fatalf("store is %T, want *FirestoreStore", s)
+			}
+			// The factory created the client, so the store closes it
+			if err := s.(io.Closer).Close(); err != nil {
+				t.Errorf("Close: %v", err)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewStore(tt.cfg)
+			if err != nil {
+				t.Fatalf("NewStore: %v", err)
+			}
+			tt.check(t, s)
+		})
+	}
+}
+
+func TestNewStoreErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Config
+		// wantErr is a substring of the expected error
+		wantErr string
+	}{
+		{"unknown backend", config.Config{StoreBackend: "postgres"}, `unknown STORE_BACKEND "postgres", must be memory, file, or firestore`},
+		{"empty backend", config.Config{}, `unknown STORE_BACKEND ""`},
+		{"memory without a seed file", config.Config{StoreBackend: BackendMemory}, "store backend memory requires SEED_FILE"},
+		{"file without a seed file", config.Config{StoreBackend: BackendFile}, "store backend file requires SEED_FILE"},
+		{"firestore without a project", config.Config{StoreBackend: BackendFirestore}, "store backend firestore requires FIRESTORE_PROJECT_ID"},
+		{"unreadable seed file", config.Config{StoreBackend: BackendFile, SeedFile: "/nonexistent/destinations.json"}, "/nonexistent/destinations.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewStore(tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NewStore error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// DefaultCollection is the Firestore collection the ingestion scripts upload to
+const DefaultCollection = "destinations"
+
+// FirestoreStore is a DestinationStore backed by a Firestore collection.
+// Documents are keyed by destination ID and decoded via the firestore tags
+// on types.Destination, except images, which decodeImages handles.
+//
+// The client is injected so callers control its lifetime. To run against the
+// local emulator (docker-compose up firestore), set FIRESTORE_EMULATOR_HOST
+// (e.g. localhost:8081) before calling firestore.NewClient.
+type FirestoreStore struct {
+	client     *firestore.Client
+	collection string
+	// ownsClient is set when the store created the client, as NewStore does
+	ownsClient bool
+}
+
+// NewFirestoreStore creates a FirestoreStore reading from the given collection
+func NewFirestoreStore(client *firestore.Client, collection string) *FirestoreStore {
+	if collection == "" {
+		collection = DefaultCollection
+	}
+	return &FirestoreStore{client: client, collection: collection}
+}
+
+// Close closes the client if the store created it; injected clients are
+// left to their owner
+func (s *FirestoreStore) Close() error {
+	if !s.ownsClient {
+		return nil
+	}
+	return s.client.Close()
+}
+
+// List returns every destination in the collection
+func (s *FirestoreStore) List(ctx context.Context) ([]types.Destination, error) {
+	docs, err := s.client.Collection(s.collection).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", s.collection, err)
+	}
+
+	destinations := make([]types.Destination, 0, len(docs))
+	for _, doc := range docs {
+		d, err := decodeDestination(doc)
+		if err != nil {
+			return nil, err
+		}
+		destinations = append(destinations, d)
+	}
+	return destinations, nil
+}
+
+// Get returns the destination with the given ID, or ErrNotFound
+func (s *FirestoreStore) Get(ctx context.Context, id string) (types.Destination, error) {
+	doc, err := s.client.Collection(s.collection).Doc(id).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return types.Destination{}, ErrNotFound
+	}
+	if err != nil {
+		return types.Destination{}, fmt.Errorf("get %s/%s: %w", s.collection, id, err)
+	}
+	return decodeDestination(doc)
+}
+
+// GetMany fetches every ID's document in a single batched read rather than
+// one round trip each. Documents that don't exist are left out.
+func (s *FirestoreStore) GetMany(ctx context.Context, ids []string) ([]types.Destination, error) {
+	if len(ids) == 0 {
+		return []types.Destination{}, nil
+	}
+	col := s.client.Collection(s.collection)
+	refs := make([]*firestore.DocumentRef, len(ids))
+	for i, id := range ids {
+		refs[i] = col.Doc(id)
+	}
+	docs, err := s.client.GetAll(ctx, refs)
+	if err != nil {
+		return nil, fmt.Errorf("get %d from %s: %w", len(ids), s.collection, err)
+	}
+
+	destinations := make([]types.Destination, 0, len(docs))
+	for _, doc := range docs {
+		if !doc.Exists() {
+			continue
+		}
+		d, err := decodeDestination(doc)
+		if err != nil {
+			return nil, err
+		}
+		destinations = append(destinations, d)
+	}
+	return destinations, nil
+}
+
+// decodeDestination maps a document onto a Destination, falling back to the
+// document ID when the id field is missing and treating it as active when
+// the active field is
+func decodeDestination(doc *firestore.DocumentSnapshot) (types.Destination, error) {
+	// DataTo leaves fields the document lacks alone, so Active defaults on
+	d := types.Destination{Active: true}
+	if err := doc.DataTo(&d); err != nil {
+		return types.Destination{}, fmt.Errorf("decode %s: %w", doc.Ref.Path, err)
+	}
+	images, err := decodeImages(doc.Data()["images"])
+	if err != nil {
+		return types.Destination{}, fmt.Errorf("decode %s: images: %w", doc.Ref.Path, err)
+	}
+	d.Images = images
+	if d.ID == "" {
+		d.ID = doc.Ref.ID
+	}
+	reconcileContinent(&d)
+	zeroNonFinite(&d)
+	d.Tags = types.NormalizeTags(d.Tags)
+	d.Images = validImages(d.Images)
+	return d, nil
+}
+
+// decodeImages decodes a document's images field through types.Image's JSON
+// decoding, so documents can hold URL strings or image maps like the seed
+// file. A missing field means no images.
+func decodeImages(v any) ([]types.Image, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var images []types.Image
+	if err := json.Unmarshal(data, &images); err != nil {
+		return nil, err
+	}
+	return images, nil
+}
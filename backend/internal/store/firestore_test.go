@@ -0,0 +1,198 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// newEmulatorStore returns a FirestoreStore over a fresh collection in the
+// Firestore emulator, seeded with docs keyed by document ID. It skips the
+// test unless FIRESTORE_EMULATOR_HOST points at a running emulator, e.g.
+//
+//	docker-compose up firestore
+//	FIRESTORE_EMULATOR_HOST=localhost:8081 go test ./internal/store
+//
+// opts are passed on to the client.
+func newEmulatorStore(t *testing.T, docs map[string]any, opts ...option.ClientOption) *FirestoreStore {
+	t.Helper()
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") == "" {
+		t.Skip("FIRESTORE_EMULATOR_HOST is not set")
+	}
+	ctx := context.Background()
+	client, err := firestore.NewClient(ctx, "otherwhere-test", opts...)
+	if err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	// Each test gets its own collection so runs don't see each other's docs
+	collection := strings.ReplaceAll(t.Name(), "/", "_")
+	col := client.Collection(collection)
+	for id, doc := range docs {
+		if _, err := col.Doc(id).Set(ctx, doc); err != nil {
+			t.Fatalf("seed %s: %v", id, err)
+		}
+	}
+	t.Cleanup(func() {
+		for id := range docs {
+			col.Doc(id).Delete(context.Background())
+		}
+	})
+	return NewFirestoreStore(client, collection)
+}
+
+func TestFirestoreStoreGet(t *testing.T) {
+	s := newEmulatorStore(t, map[string]any{
+		"lisbon": testDestinations[0],
+		// Older uploads left out the id and active fields and stored
+		// images as bare URLs
+		"kyoto": map[string]any{
+			"name": "Kyoto", "country": "Japan", "continent": "Asia", "type": "city",
+			"images": []any{"https://example.com/kyoto.jpg"},
+		},
+	})
+	ctx := context.Background()
+
+	tests := []struct {
+		id      string
+		name    string
+		images  int
+		wantErr error
+	}{
+		{"lisbon", "Lisbon", 0, nil},
+		{"kyoto", "Kyoto", 1, nil},
+		{"atlantis", "", 0, ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			d, err := s.Get(ctx, tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Get(%q) error = %v, want %v", tt.id, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if d.ID != tt.id || d.Name != tt.name || !d.Active || len(d.Images) != tt.images {
+				t.Errorf("Get(%q) = %+v, want ID %q, name %q, active, and %d images", tt.id, d, tt.id, tt.name, tt.images)
+			}
+		})
+	}
+
+	all, err := s.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(all) != 2 {
+		t.Errorf("List returned %d destinations, want 2", len(all))
+	}
+}
+
+func TestFirestoreStoreZeroesNonFinite(t *testing.T) {
+	// Firestore stores NaN and infinities natively, unlike JSON seed files
+	s := newEmulatorStore(t, map[string]any{
+		"lisbon": map[string]any{
+			"name": "Lisbon", "country": "Portugal", "continent": "Europe", "type": "city",
+			"features": map[string]any{"skiing_score": math.NaN(), "population": math.Inf(1), "hiking_score": 0.4},
+		},
+	})
+	d, err := s.Get(context.Background(), "lisbon")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if d.Features.SkiingScore != 0 || d.Features.Population != 0 || d.Features.HikingScore != 0.4 {
+		t.Errorf("features = %+v, want non-finite values zeroed and the rest kept", d.Features)
+	}
+	if want := []string{"population", "skiing_score"}; !slices.Equal(slices.Sorted(slices.Values(d.MissingFeatures)), want) {
+		t.Errorf("missing features = %v, want %v", d.MissingFeatures, want)
+	}
+}
+
+func TestFirestoreStoreGetMany(t *testing.T) {
+	// Count the BatchGetDocuments calls the client makes
+	var batchGets atomic.Int32
+	count := grpc.WithStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if strings.HasSuffix(method, "/BatchGetDocuments") {
+			batchGets.Add(1)
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	})
+	s := newEmulatorStore(t, map[string]any{
+		"lisbon": testDestinations[0],
+		"kyoto":  testDestinations[1],
+		"cusco":  testDestinations[2],
+	}, option.WithGRPCDialOption(count))
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		ids  []string
+		want []string
+		// wantReads is how many batched reads GetMany may make
+		wantReads int32
+	}{
+		{"all present", []string{"cusco", "lisbon", "kyoto"}, []string{"cusco", "lisbon", "kyoto"}, 1},
+		{"missing left out", []string{"atlantis", "kyoto", "eldorado", "lisbon"}, []string{"kyoto", "lisbon"}, 1},
+		{"none present", []string{"atlantis"}, []string{}, 1},
+		{"no IDs", nil, []string{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := batchGets.Load()
+			got, err := s.GetMany(ctx, tt.ids)
+			if err != nil {
+				t.Fatalf("GetMany: %v", err)
+			}
+			ids := destinationIDs(got)
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("GetMany = %v, want %v", ids, tt.want)
+			}
+			if n := batchGets.Load() - before; n != tt.wantReads {
+				t.Errorf("GetMany made %d batched reads, want %d", n, tt.wantReads)
+			}
+		})
+	}
+}
+
+func TestDecodeImages(t *testing.T) {
+	tests := []struct {
+		name string
+		// field is the images field as the Firestore client returns it
+		field   any
+		want    []types.Image
+		wantErr bool
+	}{
+		{"missing", nil, nil, false},
+		{"legacy strings", []any{"https://example.com/a.jpg"}, []types.Image{{URL: "https://example.com/a.jpg"}}, false},
+		{
+			"maps",
+			[]any{map[string]any{"url": "https://example.com/a.jpg", "caption": "Alfama", "credit": "Ana Silva", "width": int64(1600), "height": int64(1067)}},
+			[]types.Image{{URL: "https://example.com/a.jpg", Caption: "Alfama", Credit: "Ana Silva", Width: 1600, Height: 1067}},
+			false,
+		},
+		{"not a list", "https://example.com/a.jpg", nil, true},
+		{"not an image", []any{true}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeImages(tt.field)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeImages error = %v, want error %t", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("decodeImages = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// MemoryStore is a DestinationStore backed by an in-memory slice. The
+// dataset sits behind an atomic pointer so Reload can swap it without
+// blocking readers.
+type MemoryStore struct {
+	data atomic.Pointer[dataset]
+	// path is the seed file Reload rereads; empty when built from a slice
+	path      string
+	images    ImagePolicy
+	nonFinite NonFinitePolicy
+}
+
+// dataset is an immutable snapshot of the destinations, their ID index, and
+// their version
+type dataset struct {
+	destinations []types.Destination
+	byID         map[string]int
+	version      string
+}
+
+func newDataset(destinations []types.Destination) *dataset {
+	byID := make(map[string]int, len(destinations))
+	for i, d := range destinations {
+		byID[d.ID] = i
+	}
+	return &dataset{destinations: destinations, byID: byID, version: datasetVersion(destinations)}
+}
+
+// NewMemoryStore creates a MemoryStore holding the given destinations
+func NewMemoryStore(destinations []types.Destination) *MemoryStore {
+	s := &MemoryStore{}
+	s.data.Store(newDataset(destinations))
+	return s
+}
+
+// NewMemoryStoreFromFile creates a MemoryStore seeded from a JSON array of
+// destinations, handling malformed image URLs per images and non-finite
+// feature values per nonFinite
+func NewMemoryStoreFromFile(path string, images ImagePolicy, nonFinite NonFinitePolicy) (*MemoryStore, error) {
+	destinations, err := LoadDestinationsFromFile(path, images, nonFinite)
+	if err != nil {
+		return nil, err
+	}
+	s := NewMemoryStore(destinations)
+	s.path, s.images, s.nonFinite = path, images, nonFinite
+	return s, nil
+}
+
+// Reload rereads the seed file and swaps in its destinations. The current
+// dataset stays in place if the file fails to load or validate.
+func (s *MemoryStore) Reload(ctx context.Context) (before, after int, err error) {
+	if s.path == "" {
+		return 0, 0, errors.New("memory store has no seed file to reload")
+	}
+	destinations, err := LoadDestinationsFromFile(s.path, s.images, s.nonFinite)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, 0, err
+	}
+	old := s.data.Swap(newDataset(destinations))
+	return len(old.destinations), len(destinations), nil
+}
+
+// List returns a copy of all destinations so callers can't mutate the store
+func (s *MemoryStore) List(ctx context.Context) ([]types.Destination, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	data := s.data.Load()
+	out := make([]types.Destination, len(data.destinations))
+	copy(out, data.destinations)
+	return out, nil
+}
+
+// Get returns the destination with the given ID, or ErrNotFound
+func (s *MemoryStore) Get(ctx context.Context, id string) (types.Destination, error) {
+	if err := ctx.Err(); err != nil {
+		return types.Destination{}, err
+	}
+
+	data := s.data.Load()
+	i, ok := data.byID[id]
+	if !ok {
+		return types.Destination{}, ErrNotFound
+	}
+	return data.destinations[i], nil
+}
+
+// GetMany looks up each ID in one snapshot of the dataset, so a concurrent
+// reload can't mix old and new destinations
+func (s *MemoryStore) GetMany(ctx context.Context, ids []string) ([]types.Destination, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	data := s.data.Load()
+	out := make([]types.Destination, 0, len(ids))
+	for _, id := range ids {
+		if i, ok := data.byID[id]; ok {
+			out = append(out, data.destinations[i])
+		}
+	}
+	return out, nil
+}
+
+// Version identifies the dataset currently served; Reload changes it when
+// the reloaded destinations differ
+func (s *MemoryStore) Version() string {
+	return s.data.Load().version
+}
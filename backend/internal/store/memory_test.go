@@ -0,0 +1,182 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// testDestinations are minimal valid destinations for store tests
+var testDestinations = []types.Destination{
+	{ID: "lisbon", Name: "Lisbon", Country: "Portugal", Continent: types.Europe, Type: types.City, Active: true},
+	{ID: "kyoto", Name: "Kyoto", Country: "Japan", Continent: types.Asia, Type: types.City, Active: true},
+	{ID: "cusco", Name: "Cusco", Country: "Peru", Continent: types.SouthAmerica, Type: types.City, Active: true},
+}
+
+func TestMemoryStoreGet(t *testing.T) {
+	s := NewMemoryStore(testDestinations)
+	tests := []struct {
+		id      string
+		wantErr error
+	}{
+		{"kyoto", nil},
+		{"atlantis", ErrNotFound},
+		{"", ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			d, err := s.Get(context.Background(), tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Get(%q) error = %v, want %v", tt.id, err, tt.wantErr)
+			}
+			if err == nil && d.ID != tt.id {
+				t.Errorf("Get(%q) returned %q", tt.id, d.ID)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreGetMany(t *testing.T) {
+	s := NewMemoryStore(testDestinations)
+	tests := []struct {
+		name string
+		ids  []string
+		want []string
+	}{
+		{"input order kept", []string{"cusco", "lisbon"}, []string{"cusco", "lisbon"}},
+		{"missing left out", []string{"atlantis", "kyoto", ""}, []string{"kyoto"}},
+		{"none present", []string{"atlantis"}, []string{}},
+		{"no IDs", nil, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.GetMany(context.Background(), tt.ids)
+			if err != nil {
+				t.Fatalf("GetMany: %v", err)
+			}
+			ids := destinationIDs(got)
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("GetMany(%q) = %v, want %v", tt.ids, ids, tt.want)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.GetMany(ctx, []string{"kyoto"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetMany with a cancelled context error = %v, want context.Canceled", err)
+	}
+}
+
+// generation is a seed file whose destinations all carry the generation n in
+// their names, so a reader can tell which dataset it saw
+func generation(n, size int) string {
+	parts := make([]string, size)
+	for i := range parts {
+		parts[i] = fmt.Sprintf(`{"id":"d%d","name":"gen %d","continent":"Europe","location":{"lat":0,"lon":0}}`, i, n)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+func TestMemoryStoreReload(t *testing.T) {
+	path := writeSeed(t, generation(1, 3))
+	s, err := NewMemoryStoreFromFile(path, "", "")
+	if err != nil {
+		t.Fatalf("NewMemoryStoreFromFile: %v", err)
+	}
+	v1 := s.Version()
+
+	os.WriteFile(path, []byte(generation(2, 2)), 0o600)
+	before, after, err := s.Reload(context.Background())
+	if err != nil || before != 3 || after != 2 {
+		t.Fatalf("Reload = %d, %d, %v; want 3, 2", before, after, err)
+	}
+	if s.Version() == v1 {
+		t.Error("Version unchanged by a reload with new data")
+	}
+
+	// A bad file keeps the current dataset
+	os.WriteFile(path, []byte(`[{"id":"d0"}]`), 0o600)
+	if _, _, err := s.Reload(context.Background()); err == nil {
+		t.Error("Reload of an invalid file succeeded")
+	}
+	if got, _ := s.List(context.Background()); len(got) != 2 || got[0].Name != "gen 2" {
+		t.Errorf("after a failed reload List = %+v, want generation 2", got)
+	}
+
+	if _, _, err := NewMemoryStore(testDestinations).Reload(context.Background()); err == nil {
+		t.Error("Reload of a store without a seed file succeeded")
+	}
+}
+
+// TestMemoryStoreReloadConcurrent reloads while readers list and look up
+// destinations; run with -race. Every read must see one whole generation.
+func TestMemoryStoreReloadConcurrent(t *testing.T) {
+	path := writeSeed(t, generation(0, 4))
+	s, err := NewMemoryStoreFromFile(path, "", "")
+	if err != nil {
+		t.Fatalf("NewMemoryStoreFromFile: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			for ctx.Err() == nil {
+				list, _ := s.List(context.Background())
+				many, _ := s.GetMany(context.Background(), []string{"d0", "d1", "d2", "d3"})
+				for _, got := range [][]types.Destination{list, many} {
+					if len(got) != 2 && len(got) != 4 {
+						t.Errorf("read %d destinations, want a whole generation of 2 or 4", len(got))
+					}
+					for _, d := range got {
+						if d.Name != got[0].Name {
+							t.Errorf("read mixes %q and %q", got[0].Name, d.Name)
+						}
+					}
+				}
+			}
+		})
+	}
+	for n := 1; n <= 20; n++ {
+		os.WriteFile(path, []byte(generation(n, 2+2*(n%2))), 0o600)
+		if _, _, err := s.Reload(context.Background()); err != nil {
+			t.Errorf("Reload %d: %v", n, err)
+		}
+	}
+	cancel()
+	wg.Wait()
+}
+
+func TestDatasetVersion(t *testing.T) {
+	renamed := slices.Clone(testDestinations)
+	renamed[0].Name = "Lisboa"
+	tests := []struct {
+		name string
+		a, b []types.Destination
+		same bool
+	}{
+		{"same data", testDestinations, slices.Clone(testDestinations), true},
+		{"changed field", testDestinations, renamed, false},
+		{"fewer destinations", testDestinations, testDestinations[:1], false},
+		{"reordered", testDestinations, []types.Destination{testDestinations[1], testDestinations[0]}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := datasetVersion(tt.a), datasetVersion(tt.b)
+			if a == "" || (a == b) != tt.same {
+				t.Errorf("versions %q and %q, want same = %t", a, b, tt.same)
+			}
+		})
+	}
+	if got := NewMemoryStore(testDestinations).Version(); got != datasetVersion(testDestinations) {
+		t.Errorf("MemoryStore.Version = %q, want the dataset's %q", got, datasetVersion(testDestinations))
+	}
+}
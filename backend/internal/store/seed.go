@@ -0,0 +1,140 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+
+	"golang.org/x/text/language"
+
+	"github.com/simonryrie/otherwhere/internal/ranking"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// ImagePolicy decides what happens to malformed image URLs on load
+type ImagePolicy string
+
+const (
+	// DropInvalidImages removes malformed image URLs and keeps the destination
+	DropInvalidImages ImagePolicy = "drop"
+	// RejectInvalidImages fails the load on any malformed image URL
+	RejectInvalidImages ImagePolicy = "reject"
+)
+
+// NonFinitePolicy decides what happens to NaN and infinite feature values on
+// load
+type NonFinitePolicy string
+
+const (
+	// ZeroNonFinite stores non-finite values as 0 and marks them missing
+	ZeroNonFinite NonFinitePolicy = "zero"
+	// RejectNonFinite fails the load on any non-finite value
+	RejectNonFinite NonFinitePolicy = "reject"
+)
+
+// LoadDestinationsFromFile reads a JSON array of destinations and checks that
+// each has the fields the API relies on. Errors name the offending index.
+// Malformed image URLs are handled per images; "" means DropInvalidImages.
+// Non-finite feature values are handled per nonFinite; "" means
+// ZeroNonFinite.
+func LoadDestinationsFromFile(path string, images ImagePolicy, nonFinite NonFinitePolicy) ([]types.Destination, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read seed file: %w", err)
+	}
+
+	var destinations []types.Destination
+	if err := json.Unmarshal(data, &destinations); err != nil {
+		return nil, fmt.Errorf("parse seed file %s: %w", path, err)
+	}
+
+	for i := range destinations {
+		reconcileContinent(&destinations[i])
+		if bad := zeroNonFinite(&destinations[i]); len(bad) > 0 && nonFinite == RejectNonFinite {
+			return nil, fmt.Errorf("seed file %s: destination %d: %s: non-finite values for %v", path, i, destinations[i].ID, bad)
+		}
+		d := destinations[i]
+		if err := validateDestination(d); err != nil {
+			return nil, fmt.Errorf("seed file %s: destination %d: %w", path, i, err)
+		}
+		valid := validImages(d.Images)
+		if images == RejectInvalidImages && len(valid) != len(d.Images) {
+			return nil, fmt.Errorf("seed file %s: destination %d: %s: invalid image URL", path, i, d.ID)
+		}
+		destinations[i].Images = valid
+		destinations[i].Tags = types.NormalizeTags(d.Tags)
+	}
+	return destinations, nil
+}
+
+// validateDestination checks the required fields of a seeded destination
+func validateDestination(d types.Destination) error {
+	switch {
+	case d.ID == "":
+		return fmt.Errorf("id is required")
+	case d.Name == "":
+		return fmt.Errorf("%s: name is required", d.ID)
+	case !d.Continent.IsValid():
+		return fmt.Errorf("%s: unknown continent %q", d.ID, d.Continent)
+	}
+	if err := d.Location.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", d.ID, err)
+	}
+	for _, key := range d.MissingFeatures {
+		if _, ok := ranking.FeatureByKey(key); !ok {
+			return fmt.Errorf("%s: unknown missing feature %q", d.ID, key)
+		}
+	}
+	for field, translations := range map[string]map[string]string{"names": d.Names, "descriptions": d.Descriptions} {
+		for tag := range translations {
+			if _, err := language.Parse(tag); err != nil {
+				return fmt.Errorf("%s: %s: invalid language tag %q", d.ID, field, tag)
+			}
+		}
+	}
+	return nil
+}
+
+// reconcileContinent fills in a missing continent from the country and logs
+// destinations whose continent disagrees with it. Mismatches are kept as
+// loaded, since the table can't know every territory's grouping.
+func reconcileContinent(d *types.Destination) {
+	want, ok := types.ContinentForCountry(d.Country)
+	switch {
+	case !ok:
+	case d.Continent == "":
+		d.Continent = want
+	case d.Continent != want:
+		slog.Warn("continent does not match country",
+			"id", d.ID, "country", d.Country, "continent", d.Continent, "expected", want)
+	}
+}
+
+// zeroNonFinite zeroes d's NaN and infinite feature values, marking them
+// missing so ranking skips them, and logs what it changed
+func zeroNonFinite(d *types.Destination) []string {
+	bad := ranking.ZeroNonFinite(&d.Features)
+	if len(bad) == 0 {
+		return nil
+	}
+	for _, key := range bad {
+		if _, ok := ranking.FeatureByKey(key); ok && !slices.Contains(d.MissingFeatures, key) {
+			d.MissingFeatures = append(d.MissingFeatures, key)
+		}
+	}
+	slog.Warn("zeroed non-finite feature values", "id", d.ID, "features", bad)
+	return bad
+}
+
+// validImages returns the images with well-formed absolute URLs
+func validImages(images []types.Image) []types.Image {
+	out := make([]types.Image, 0, len(images))
+	for _, img := range images {
+		if types.ValidImageURL(img.URL) {
+			out = append(out, img)
+		}
+	}
+	return out
+}
@@ -0,0 +1,232 @@
+package store
+
+import (
+	"errors"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// writeSeed writes a seed file into a temporary directory and returns its path
+func writeSeed(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "destinations.json")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("write seed file: %v", err)
+	}
+	return path
+}
+
+func TestLoadDestinationsFromFile(t *testing.T) {
+	path := writeSeed(t, `[
+		{"id":"lisbon","name":"Lisbon","country":"Portugal","continent":"Europe","type":"City","location":{"lat":38.72,"lon":-9.14},"tags":["Food"," food "]},
+		{"id":"suva","name":"Suva","country":"Fiji","continent":"Oceania","type":"City","location":{"lat":-18.14,"lon":178.44}}
+	]`)
+	got, err := LoadDestinationsFromFile(path, "", "")
+	if err != nil {
+		t.Fatalf("LoadDestinationsFromFile: %v", err)
+	}
+	if len(got) != 2 || got[0].ID != "lisbon" || got[1].Location.Lon != 178.44 {
+		t.Fatalf("loaded %+v", got)
+	}
+	if len(got[0].Tags) != 1 || got[0].Tags[0] != "food" {
+		t.Errorf("tags = %q, want normalized [food]", got[0].Tags)
+	}
+}
+
+func TestLoadDestinationsFromFileInvalid(t *testing.T) {
+	valid := `{"id":"lisbon","name":"Lisbon","country":"Portugal","continent":"Europe","location":{"lat":38.72,"lon":-9.14}}`
+	tests := []struct {
+		name string
+		// second is the destination at index 1, after a valid one
+		second string
+		// wantErr is a substring of the expected error
+		wantErr string
+	}{
+		{"latitude out of range", `{"id":"north","name":"North","continent":"Europe","location":{"lat":91,"lon":0}}`, "destination 1: north: lat must be between -90 and 90, got 91"},
+		{"longitude out of range", `{"id":"east","name":"East","continent":"Asia","location":{"lat":0,"lon":180.5}}`, "destination 1: east: lon must be between -180 and 180"},
+		{"latitude just below range", `{"id":"south","name":"South","continent":"Oceania","location":{"lat":-90.001,"lon":0}}`, "destination 1: south: lat must be between -90 and 90, got -90.001"},
+		{"longitude just below range", `{"id":"west","name":"West","continent":"Oceania","location":{"lat":0,"lon":-180.001}}`, "destination 1: west: lon must be between -180 and 180, got -180.001"},
+		{"missing id", `{"name":"Nowhere","continent":"Europe","location":{"lat":0,"lon":0}}`, "destination 1: id is required"},
+		{"missing name", `{"id":"nowhere","continent":"Europe","location":{"lat":0,"lon":0}}`, "destination 1: nowhere: name is required"},
+		{"invalid language tag", `{"id":"bled","name":"Bled","continent":"Europe","location":{"lat":46.37,"lon":14.11},"names":{"not a tag!":"Bled"}}`, `destination 1: bled: names: invalid language tag "not a tag!"`},
+		{"unknown missing feature", `{"id":"bled","name":"Bled","continent":"Europe","location":{"lat":46.37,"lon":14.11},"missing_features":["llama_density"]}`, `destination 1: bled: unknown missing feature "llama_density"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadDestinationsFromFile(writeSeed(t, "["+valid+","+tt.second+"]"), "", "")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadDestinationsFromFileBoundaryCoordinates(t *testing.T) {
+	path := writeSeed(t, `[
+		{"id":"pole","name":"Pole","continent":"Oceania","location":{"lat":-90,"lon":-180}},
+		{"id":"top","name":"Top","continent":"Europe","location":{"lat":90,"lon":180}}
+	]`)
+	got, err := LoadDestinationsFromFile(path, "", "")
+	if err != nil {
+		t.Fatalf("LoadDestinationsFromFile: %v", err)
+	}
+	if len(got) != 2 {
+		t.Errorf("loaded %d destinations, want both boundary ones", len(got))
+	}
+}
+
+func TestLoadDestinationsFromFileUnreadable(t *testing.T) {
+	_, err := LoadDestinationsFromFile(filepath.Join(t.TempDir(), "missing.json"), "", "")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing file error = %v, want fs.ErrNotExist", err)
+	}
+	_, err = LoadDestinationsFromFile(writeSeed(t, `{"id":"lisbon"}`), "", "")
+	if err == nil || !strings.Contains(err.Error(), "parse seed file") {
+		t.Errorf("non-array file error = %v, want a parse error", err)
+	}
+}
+
+func TestShippedSeedFileLoads(t *testing.T) {
+	got, err := LoadDestinationsFromFile(filepath.Join("..", "..", "data", "destinations.json"), RejectInvalidImages, RejectNonFinite)
+	if err != nil {
+		t.Fatalf("data/destinations.json: %v", err)
+	}
+	if len(got) == 0 {
+		t.Error("data/destinations.json is empty")
+	}
+}
+
+func TestLoadDestinationsFromFileContinent(t *testing.T) {
+	tests := []struct {
+		name, destination string
+		// wantErr is a substring of the expected error, or "" when it loads
+		wantErr string
+	}{
+		{"known continent", `{"id":"cusco","name":"Cusco","continent":"South America","location":{"lat":-13.53,"lon":-71.97}}`, ""},
+		{"filled from the country", `{"id":"cusco","name":"Cusco","country":"Peru","location":{"lat":-13.53,"lon":-71.97}}`, ""},
+		{"Antarctica", `{"id":"mcmurdo","name":"McMurdo","continent":"Antarctica","location":{"lat":-77.85,"lon":166.67}}`, `mcmurdo: unknown continent "Antarctica"`},
+		{"missing", `{"id":"nowhere","name":"Nowhere","location":{"lat":0,"lon":0}}`, `nowhere: unknown continent ""`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadDestinationsFromFile(writeSeed(t, "["+tt.destination+"]"), "", "")
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("error = %v, want it to load", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadDestinationsFromFileImages(t *testing.T) {
+	mixed := `[{"id":"lisbon","name":"Lisbon","continent":"Europe","location":{"lat":38.72,"lon":-9.14},
+		"images":["https://example.com/a.jpg","not a url",{"url":"ftp://example.com/b.jpg"},{"url":"https://example.com/c.jpg","caption":"Alfama"}]}]`
+	none := `[{"id":"lisbon","name":"Lisbon","continent":"Europe","location":{"lat":38.72,"lon":-9.14}}]`
+	tests := []struct {
+		name     string
+		contents string
+		policy   ImagePolicy
+		want     []string
+		wantErr  bool
+	}{
+		{"mixed, dropped", mixed, DropInvalidImages, []string{"https://example.com/a.jpg", "https://example.com/c.jpg"}, false},
+		{"mixed, default policy", mixed, "", []string{"https://example.com/a.jpg", "https://example.com/c.jpg"}, false},
+		{"mixed, rejected", mixed, RejectInvalidImages, nil, true},
+		{"none", none, RejectInvalidImages, []string{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadDestinationsFromFile(writeSeed(t, tt.contents), tt.policy, "")
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "destination 0: lisbon: invalid image URL") {
+					t.Errorf("error = %v, want an invalid image URL error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadDestinationsFromFile: %v", err)
+			}
+			urls := make([]string, len(got[0].Images))
+			for i, img := range got[0].Images {
+				urls[i] = img.URL
+			}
+			if !slices.Equal(urls, tt.want) {
+				t.Errorf("images = %q, want %q", urls, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileContinent(t *testing.T) {
+	tests := []struct {
+		name, country   string
+		continent, want types.Continent
+	}{
+		{"filled from the country", "Kenya", "", types.Africa},
+		{"matches", "Japan", types.Asia, types.Asia},
+		// Mismatches are only logged
+		{"mismatch kept", "Peru", types.Europe, types.Europe},
+		{"unknown country", "Atlantis", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := types.Destination{ID: "d", Country: tt.country, Continent: tt.continent}
+			reconcileContinent(&d)
+			if d.Continent != tt.want {
+				t.Errorf("Continent = %q, want %q", d.Continent, tt.want)
+			}
+		})
+	}
+}
+
+func TestZeroNonFinite(t *testing.T) {
+	tests := []struct {
+		name        string
+		features    types.DestinationFeatures
+		missing     []string
+		wantBad     []string
+		wantMissing []string
+	}{
+		{"finite", types.DestinationFeatures{SkiingScore: 0.5}, nil, nil, nil},
+		{"NaN marked missing", types.DestinationFeatures{SkiingScore: math.NaN()}, nil, []string{"skiing_score"}, []string{"skiing_score"}},
+		{"already missing", types.DestinationFeatures{SkiingScore: math.Inf(1)}, []string{"skiing_score"}, []string{"skiing_score"}, []string{"skiing_score"}},
+		// Monthly temperatures aren't a feature of their own, so they're
+		// zeroed without marking anything missing
+		{"monthly", types.DestinationFeatures{MonthlyTempC: [12]float64{0: math.NaN()}}, nil, []string{"monthly_temp_c"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := types.Destination{ID: "lisbon", Features: tt.features, MissingFeatures: tt.missing}
+			if bad := zeroNonFinite(&d); !slices.Equal(bad, tt.wantBad) {
+				t.Errorf("zeroNonFinite = %v, want %v", bad, tt.wantBad)
+			}
+			if !slices.Equal(d.MissingFeatures, tt.wantMissing) {
+				t.Errorf("missing features = %v, want %v", d.MissingFeatures, tt.wantMissing)
+			}
+			if math.IsNaN(d.Features.SkiingScore) || math.IsInf(d.Features.SkiingScore, 0) || math.IsNaN(d.Features.MonthlyTempC[0]) {
+				t.Errorf("features = %+v, want non-finite values zeroed", d.Features)
+			}
+		})
+	}
+}
+
+func TestLoadDestinationsFromFileOutOfRange(t *testing.T) {
+	// JSON has no NaN or Infinity, so the nearest a seed file gets is a
+	// number too large for a float64, which fails to decode under either
+	// policy rather than loading as +Inf
+	path := writeSeed(t, `[{"id":"lisbon","name":"Lisbon","continent":"Europe","location":{"lat":38.72,"lon":-9.14},"features":{"skiing_score":1e999}}]`)
+	for _, policy := range []NonFinitePolicy{ZeroNonFinite, RejectNonFinite} {
+		if _, err := LoadDestinationsFromFile(path, "", policy); err == nil {
+			t.Errorf("%s: loaded an out-of-range feature value", policy)
+		}
+	}
+}
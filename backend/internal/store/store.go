@@ -0,0 +1,55 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// ErrNotFound is returned when a destination doesn't exist in the store
+var ErrNotFound = errors.New("destination not found")
+
+// DestinationStore provides read access to the destination dataset
+type DestinationStore interface {
+	// List returns every destination in the store
+	List(ctx context.Context) ([]types.Destination, error)
+
+	// Get returns the destination with the given ID, or ErrNotFound
+	Get(ctx context.Context, id string) (types.Destination, error)
+
+	// GetMany returns the destinations with the given IDs in the same
+	// order, leaving out IDs that don't exist rather than failing
+	GetMany(ctx context.Context, ids []string) ([]types.Destination, error)
+}
+
+// Reloader is implemented by stores that can refresh their dataset while
+// serving. Reads in flight during a reload see either the old or the new
+// dataset, never a mix.
+type Reloader interface {
+	// Reload refreshes the dataset, returning the destination counts
+	// before and after
+	Reload(ctx context.Context) (before, after int, err error)
+}
+
+// Versioner is implemented by stores that can identify the dataset they're
+// serving, so clients caching it can tell when it changes
+type Versioner interface {
+	// Version is a short opaque string that changes whenever the dataset
+	// does; empty when the store doesn't know it yet
+	Version() string
+}
+
+// datasetVersion hashes the destinations' JSON, so identical datasets share
+// a version across instances and restarts while any change gives a new one
+func datasetVersion(destinations []types.Destination) string {
+	body, err := json.Marshal(destinations)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:8])
+}
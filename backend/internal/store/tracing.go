@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/simonryrie/otherwhere/internal/tracing"
+	"github.com/simonryrie/otherwhere/internal/types"
+)
+
+// ErrNotReloadable is returned when reloading a store that can't reload
+var ErrNotReloadable = errors.New("store does not support reloading")
+
+// TracingStore wraps a DestinationStore, recording a span around each call.
+// Spans are children of the span in the caller's context, so store latency
+// shows up under the request that caused it.
+type TracingStore struct {
+	inner  DestinationStore
+	tracer trace.Tracer
+}
+
+// NewTracingStore creates a TracingStore around inner
+func NewTracingStore(inner DestinationStore) *TracingStore {
+	return &TracingStore{inner: inner, tracer: tracing.Tracer("store")}
+}
+
+// List lists the wrapped store's destinations in a "store.List" span
+func (s *TracingStore) List(ctx context.Context) ([]types.Destination, error) {
+	ctx, span := s.tracer.Start(ctx, "store.List")
+	defer span.End()
+
+	destinations, err := s.inner.List(ctx)
+	recordError(span, err)
+	span.SetAttributes(attribute.Int("store.count", len(destinations)))
+	return destinations, err
+}
+
+// Get fetches a destination from the wrapped store in a "store.Get" span
+func (s *TracingStore) Get(ctx context.Context, id string) (types.Destination, error) {
+	ctx, span := s.tracer.Start(ctx, "store.Get", trace.WithAttributes(attribute.String("destination.id", id)))
+	defer span.End()
+
+	d, err := s.inner.Get(ctx, id)
+	// A missing destination is an answer, not a failure
+	if !errors.Is(err, ErrNotFound) {
+		recordError(span, err)
+	}
+	return d, err
+}
+
+// GetMany fetches destinations from the wrapped store in a "store.GetMany"
+// span, recording how many were asked for and found
+func (s *TracingStore) GetMany(ctx context.Context, ids []string) ([]types.Destination, error) {
+	ctx, span := s.tracer.Start(ctx, "store.GetMany", trace.WithAttributes(attribute.Int("store.requested", len(ids))))
+	defer span.End()
+
+	destinations, err := s.inner.GetMany(ctx, ids)
+	recordError(span, err)
+	span.SetAttributes(attribute.Int("store.count", len(destinations)))
+	return destinations, err
+}
+
+// Reload reloads the wrapped store in a "store.Reload" span, failing with
+// ErrNotReloadable when it can't reload
+func (s *TracingStore) Reload(ctx context.Context) (before, after int, err error) {
+	r, ok := s.inner.(Reloader)
+	if !ok {
+		return 0, 0, ErrNotReloadable
+	}
+	ctx, span := s.tracer.Start(ctx, "store.Reload")
+	defer span.End()
+
+	before, after, err = r.Reload(ctx)
+	recordError(span, err)
+	return before, after, err
+}
+
+// Version reports the wrapped store's version, or "" when it has none
+func (s *TracingStore) Version() string {
+	if v, ok := s.inner.(Versioner); ok {
+		return v.Version()
+	}
+	return ""
+}
+
+// recordError marks span as failed when err is set
+func recordError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,89 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// spanRecorder is installed as the global tracer provider's span
+// processor. Tracers delegate to the first global provider set, so the
+// whole test binary shares it.
+var spanRecorder = sync.OnceValue(func() *tracetest.SpanRecorder {
+	rec := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	return rec
+})
+
+func TestTracingStoreSpans(t *testing.T) {
+	rec := spanRecorder()
+	s := NewTracingStore(NewMemoryStore(testDestinations))
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		call     func() error
+		wantSpan string
+		// wantFailed is whether the span is marked as an error
+		wantFailed bool
+	}{
+		{"list", func() error { _, err := s.List(ctx); return err }, "store.List", false},
+		{"get", func() error { _, err := s.Get(ctx, "lisbon"); return err }, "store.Get", false},
+		// A missing destination is an answer, so the span isn't failed
+		{"get missing", func() error { _, err := s.Get(ctx, "atlantis"); return err }, "store.Get", false},
+		{"get many", func() error { _, err := s.GetMany(ctx, []string{"lisbon", "kyoto"}); return err }, "store.GetMany", false},
+		// Without a seed file the memory store can't reload
+		{"reload failure", func() error { _, _, err := s.Reload(ctx); return err }, "store.Reload", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(rec.Ended())
+			tt.call()
+			spans := rec.Ended()[before:]
+			if len(spans) != 1 || spans[0].Name() != tt.wantSpan {
+				t.Fatalf("spans = %v, want one %s", spans, tt.wantSpan)
+			}
+			if failed := spans[0].Status().Code == codes.Error; failed != tt.wantFailed {
+				t.Errorf("span failed = %t, want %t", failed, tt.wantFailed)
+			}
+		})
+	}
+}
+
+func TestTracingStoreChildSpans(t *testing.T) {
+	rec := spanRecorder()
+	s := NewTracingStore(NewMemoryStore(testDestinations))
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+	s.List(ctx)
+	parent.End()
+
+	spans := rec.Ended()
+	child := spans[len(spans)-2]
+	if child.Name() != "store.List" || child.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("store span %q has parent %s, want the request span %s", child.Name(), child.Parent().SpanID(), parent.SpanContext().SpanID())
+	}
+}
+
+func TestTracingStoreReloadUnsupported(t *testing.T) {
+	// Embedding the interface hides MemoryStore's Reload
+	s := NewTracingStore(struct{ DestinationStore }{NewMemoryStore(testDestinations)})
+	if _, _, err := s.Reload(context.Background()); !errors.Is(err, ErrNotReloadable) {
+		t.Errorf("Reload error = %v, want ErrNotReloadable", err)
+	}
+}
+
+func TestTracingStoreVersion(t *testing.T) {
+	inner := NewMemoryStore(testDestinations)
+	if got := NewTracingStore(inner).Version(); got != inner.Version() {
+		t.Errorf("Version = %q, want the wrapped store's %q", got, inner.Version())
+	}
+	hidden := NewTracingStore(struct{ DestinationStore }{inner})
+	if got := hidden.Version(); got != "" {
+		t.Errorf("Version without a Versioner = %q, want empty", got)
+	}
+}
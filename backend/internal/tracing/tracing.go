@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName identifies the API in exported traces
+const ServiceName = "otherwhere-backend"
+
+// Setup installs a global tracer provider exporting spans over OTLP/HTTP to
+// endpoint (e.g. http://localhost:4318). With an empty endpoint tracing
+// stays a no-op. The returned function flushes and stops the exporter.
+func Setup(ctx context.Context, endpoint string) (shutdown func(context.Context) error, err error) {
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("create otlp exporter: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
+
+// Handler wraps h so each request gets a server span, continuing any trace
+// propagated in the request headers
+func Handler(h http.Handler) http.Handler {
+	return otelhttp.NewHandler(h, "http.request")
+}
+
+// RouteName renames the request's span to its chi route pattern once the
+// route is matched, so IDs in URLs don't create a span name per destination.
+// It must run inside Handler.
+func RouteName(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			span := trace.SpanFromContext(r.Context())
+			span.SetName(r.Method + " " + rctx.RoutePattern())
+			span.SetAttributes(attribute.String("http.route", rctx.RoutePattern()))
+		}
+	})
+}
+
+// Tracer returns the tracer for manual spans in the named package
+func Tracer(pkg string) trace.Tracer {
+	return otel.Tracer("github.com/simonryrie/otherwhere/internal/" + pkg)
+}
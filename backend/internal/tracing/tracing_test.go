@@ -0,0 +1,86 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recorder is installed as the global tracer provider's span processor.
+// Tracers delegate to the first global provider set, so the whole test
+// binary shares it.
+var recorder = sync.OnceValue(func() *tracetest.SpanRecorder {
+	rec := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	return rec
+})
+
+// endedSince returns the spans ended after the first n
+func endedSince(n int) []sdktrace.ReadOnlySpan {
+	return recorder().Ended()[n:]
+}
+
+func TestHandlerSpanPerRequest(t *testing.T) {
+	rec := recorder()
+	r := chi.NewRouter()
+	r.Use(RouteName)
+	r.Get("/api/destinations/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	h := Handler(r)
+
+	before := len(rec.Ended())
+	for _, path := range []string{"/api/destinations/tokyo", "/api/destinations/kyoto"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	spans := endedSince(before)
+	if len(spans) != 2 {
+		t.Fatalf("recorded %d spans for 2 requests, want 2", len(spans))
+	}
+	for _, span := range spans {
+		// Named by route pattern, so IDs don't create a name per destination
+		if span.Name() != "GET /api/destinations/{id}" {
+			t.Errorf("span name = %q", span.Name())
+		}
+		found := false
+		for _, kv := range span.Attributes() {
+			if kv == attribute.String("http.route", "/api/destinations/{id}") {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("span %q has no http.route attribute: %v", span.Name(), span.Attributes())
+		}
+	}
+	if spans[0].SpanContext().TraceID() == spans[1].SpanContext().TraceID() {
+		t.Error("separate requests share a trace")
+	}
+}
+
+func TestHandlerUnmatchedRouteKeepsName(t *testing.T) {
+	rec := recorder()
+	r := chi.NewRouter()
+	r.Use(RouteName)
+	before := len(rec.Ended())
+	Handler(r).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nowhere", nil))
+	spans := endedSince(before)
+	if len(spans) != 1 || spans[0].Name() != "http.request" {
+		t.Errorf("spans = %v, want one named http.request", spans)
+	}
+}
+
+func TestSetupWithoutEndpoint(t *testing.T) {
+	shutdown, err := Setup(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown: %v", err)
+	}
+}
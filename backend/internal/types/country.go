@@ -0,0 +1,44 @@
+package types
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// countriesCSV maps country names, including common variants such as "USA",
+// to continents
+//
+//go:embed countries.csv
+var countriesCSV string
+
+// countryContinents indexes countriesCSV by lowercased country name
+var countryContinents = sync.OnceValue(func() map[string]Continent {
+	r := csv.NewReader(strings.NewReader(countriesCSV))
+	r.Comment = '#'
+	rows, err := r.ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("parse countries.csv: %v", err))
+	}
+
+	table := make(map[string]Continent, len(rows))
+	for _, row := range rows[1:] {
+		c := Continent(row[1])
+		if !c.IsValid() {
+			panic(fmt.Sprintf("countries.csv: %s: unknown continent %q", row[0], c))
+		}
+		table[strings.ToLower(row[0])] = c
+	}
+	return table
+})
+
+// ContinentForCountry returns the continent a country lies on, matching
+// names case-insensitively. Countries spanning continents map to the one
+// they're usually grouped with (Russia and Turkey to Europe and Asia
+// respectively).
+func ContinentForCountry(country string) (Continent, bool) {
+	c, ok := countryContinents()[strings.ToLower(strings.TrimSpace(country))]
+	return c, ok
+}
@@ -0,0 +1,41 @@
+package types
+
+import "testing"
+
+func TestContinentForCountry(t *testing.T) {
+	tests := []struct {
+		country string
+		want    Continent
+		wantOK  bool
+	}{
+		{"Portugal", Europe, true},
+		{"Norway", Europe, true},
+		{"Japan", Asia, true},
+		{"Thailand", Asia, true},
+		{"Kenya", Africa, true},
+		{"Egypt", Africa, true},
+		{"Canada", NorthAmerica, true},
+		{"Mexico", NorthAmerica, true},
+		{"Peru", SouthAmerica, true},
+		{"Argentina", SouthAmerica, true},
+		{"Fiji", Oceania, true},
+		{"New Zealand", Oceania, true},
+		// Variants, case, and padding map to the same continent
+		{"United States", NorthAmerica, true},
+		{"USA", NorthAmerica, true},
+		{"united states of america", NorthAmerica, true},
+		{" UK ", Europe, true},
+		{"Czech Republic", Europe, true},
+		// Transcontinental countries follow their usual grouping
+		{"Russia", Europe, true},
+		{"Turkey", Asia, true},
+		{"Atlantis", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ContinentForCountry(tt.country)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ContinentForCountry(%q) = %q, %t; want %q, %t", tt.country, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
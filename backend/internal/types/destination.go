@@ -1,5 +1,14 @@
 package types
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
 // DestinationType represents whether a destination is a city or region
 type DestinationType string
 
@@ -20,21 +29,49 @@ const (
 	Oceania      Continent = "Oceania"
 )
 
+// AllContinents returns the known continents in canonical order. Antarctica
+// is deliberately absent: it has no destinations in the dataset, so it's
+// treated as an unknown value rather than an empty one.
+func AllContinents() []Continent {
+	return []Continent{Europe, Asia, Africa, NorthAmerica, SouthAmerica, Oceania}
+}
+
+// IsValid reports whether c is one of the known continents
+func (c Continent) IsValid() bool {
+	return slices.Contains(AllContinents(), c)
+}
+
 // Location represents geographic coordinates
 type Location struct {
 	Lat float64 `json:"lat" firestore:"lat"`
 	Lon float64 `json:"lon" firestore:"lon"`
 }
 
+// Validate reports a latitude outside [-90, 90] or a longitude outside
+// [-180, 180], including NaN, either of which breaks distance math
+func (l Location) Validate() error {
+	if !(l.Lat >= -90 && l.Lat <= 90) {
+		return fmt.Errorf("lat must be between -90 and 90, got %g", l.Lat)
+	}
+	if !(l.Lon >= -180 && l.Lon <= 180) {
+		return fmt.Errorf("lon must be between -180 and 180, got %g", l.Lon)
+	}
+	return nil
+}
+
 // DestinationFeatures contains all normalized feature values [0, 1]
 type DestinationFeatures struct {
 	// Climate
 	AvgTempC float64 `json:"avg_temp_c" firestore:"avg_temp_c"`
+	// MonthlyTempC holds normalized average temperatures for January to
+	// December on the same scale as AvgTempC. All zeros means no seasonal
+	// data, in which case AvgTempC stands in for every month.
+	MonthlyTempC [12]float64 `json:"monthly_temp_c" firestore:"monthly_temp_c"`
 
 	// Tourism & Popularity
-	TourismDensity        float64 `json:"tourism_density" firestore:"tourism_density"`
-	WikipediaPageviews    float64 `json:"wikipedia_pageviews" firestore:"wikipedia_pageviews"`
-	AccommodationDensity  float64 `json:"accommodation_density" firestore:"accommodation_density"`
+	TourismDensity       float64 `json:"tourism_density" firestore:"tourism_density"`
+	WikipediaPageviews   float64 `json:"wikipedia_pageviews" firestore:"wikipedia_pageviews"`
+	AccommodationDensity float64 `json:"accommodation_density" firestore:"accommodation_density"`
 
 	// Urbanization
 	Population float64 `json:"population" firestore:"population"`
@@ -56,6 +93,84 @@ type DestinationFeatures struct {
 	GDPPerCapita     float64 `json:"gdp_per_capita" firestore:"gdp_per_capita"`
 }
 
+// RawFeatures holds feature measurements in their source units (°C, km,
+// people, ...) before normalization. It shares DestinationFeatures' layout.
+type RawFeatures DestinationFeatures
+
+// Image is a destination photo with its caption and attribution. Width and
+// Height are in pixels, 0 when unknown.
+type Image struct {
+	URL     string `json:"url"`
+	Caption string `json:"caption,omitempty"`
+	Credit  string `json:"credit,omitempty"`
+	Width   int    `json:"width,omitempty"`
+	Height  int    `json:"height,omitempty"`
+}
+
+// UnmarshalJSON decodes an image from an object or, as older data stores
+// images, a bare URL string
+func (img *Image) UnmarshalJSON(data []byte) error {
+	var url string
+	if err := json.Unmarshal(data, &url); err == nil {
+		*img = Image{URL: url}
+		return nil
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return fmt.Errorf("image must be a URL string or an object, got %s", data)
+	}
+	// plain has the image's fields but not this method
+	type plain Image
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	*img = Image(p)
+	return nil
+}
+
+// ValidImageURL reports whether raw is an absolute http(s) URL with a host
+func ValidImageURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// NormalizeTags lowercases and trims tags, dropping empty and repeated ones
+func NormalizeTags(tags []string) []string {
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !slices.Contains(out, tag) {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
+
+// FeatureMetadata describes a feature so clients can render controls for it
+type FeatureMetadata struct {
+	Key          string `json:"key"`
+	Label        string `json:"label"`
+	Unit         string `json:"unit"`
+	HigherIsMore bool   `json:"higher_is_more"`
+	// SourceMin and SourceMax bound the raw measurements, in Unit, that
+	// normalize onto [0, 1]
+	SourceMin     float64 `json:"source_min"`
+	SourceMax     float64 `json:"source_max"`
+	DefaultWeight float64 `json:"default_weight"`
+	// Aliases are other names searches accept for the feature
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// Preset is a named vibe that expands into a weighted search query
+type Preset struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Targets are the normalized feature values the preset queries for
+	Targets map[string]float64 `json:"targets"`
+	// Weights emphasize the features that define the preset
+	Weights map[string]float64 `json:"weights,omitempty"`
+}
+
 // FeatureConstraint represents min/max constraints for a feature
 type FeatureConstraint struct {
 	Min *float64 `json:"min,omitempty"`
@@ -65,11 +180,26 @@ type FeatureConstraint struct {
 // SearchConstraints maps feature names to their constraints
 type SearchConstraints map[string]FeatureConstraint
 
+// BoundingBox is a lat/lon viewport. A box with MinLon > MaxLon crosses the antimeridian.
+type BoundingBox struct {
+	MinLat float64 `json:"min_lat"`
+	MinLon float64 `json:"min_lon"`
+	MaxLat float64 `json:"max_lat"`
+	MaxLon float64 `json:"max_lon"`
+}
+
 // GeographicFilters for filtering by location
 type GeographicFilters struct {
 	Continent *Continent `json:"continent,omitempty"`
 	Region    *string    `json:"region,omitempty"`
 	Country   *string    `json:"country,omitempty"`
+
+	// Radius search around a point
+	Near     *Location `json:"near,omitempty"`
+	RadiusKm float64   `json:"radius_km,omitempty"`
+
+	// Viewport search
+	BoundingBox *BoundingBox `json:"bbox,omitempty"`
 }
 
 // Destination represents a complete destination object
@@ -79,9 +209,9 @@ type Destination struct {
 	Name string `json:"name" firestore:"name"`
 
 	// Geographic metadata (for filtering)
-	Country   string     `json:"country" firestore:"country"`
-	Continent Continent  `json:"continent" firestore:"continent"`
-	Region    *string    `json:"region,omitempty" firestore:"region,omitempty"`
+	Country   string    `json:"country" firestore:"country"`
+	Continent Continent `json:"continent" firestore:"continent"`
+	Region    *string   `json:"region,omitempty" firestore:"region,omitempty"`
 
 	// Type and location
 	Type     DestinationType `json:"type" firestore:"type"`
@@ -89,10 +219,42 @@ type Destination struct {
 
 	// Features (for vibe-based ranking)
 	Features DestinationFeatures `json:"features" firestore:"features"`
+	// MissingFeatures lists feature keys with no data; their value is 0 but
+	// scoring leaves them out rather than treating them as the lowest value
+	MissingFeatures []string `json:"missing_features,omitempty" firestore:"missing_features,omitempty"`
+
+	// Curated labels such as "unesco" or "honeymoon", stored lowercase
+	Tags []string `json:"tags" firestore:"tags"`
 
 	// Media and description
-	Images      []string `json:"images" firestore:"images"`
-	Description *string  `json:"description,omitempty" firestore:"description,omitempty"`
+	// Images are decoded from either Image objects or, in older data, bare
+	// URL strings. Firestore documents are decoded by the store, which
+	// accepts both the same way.
+	Images      []Image `json:"images" firestore:"-"`
+	Description *string `json:"description,omitempty" firestore:"description,omitempty"`
+
+	// Translations of Name and Description keyed by BCP-47 language tag
+	// (e.g. "fr", "pt-BR"). Responses pick the best match for the client's
+	// Accept-Language, falling back to Name and Description.
+	Names        map[string]string `json:"names,omitempty" firestore:"names,omitempty"`
+	Descriptions map[string]string `json:"descriptions,omitempty" firestore:"descriptions,omitempty"`
+
+	// Active is false for destinations hidden from public responses without
+	// deleting their data. Records that don't set it are active.
+	Active bool `json:"active" firestore:"active"`
+}
+
+// UnmarshalJSON decodes a destination, defaulting Active to true when the
+// record doesn't set it
+func (d *Destination) UnmarshalJSON(data []byte) error {
+	// plain has the destination's fields but not this method
+	type plain Destination
+	p := plain{Active: true}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	*d = Destination(p)
+	return nil
 }
 
 // SearchRequest represents a search query
@@ -100,16 +262,329 @@ type SearchRequest struct {
 	Query       string             `json:"query"`
 	Constraints *SearchConstraints `json:"constraints,omitempty"`
 	Filters     *GeographicFilters `json:"filters,omitempty"`
+
+	// Preset names a vibe preset (see /api/presets) to search for; explicit
+	// constraints and weights take precedence over it
+	Preset string `json:"preset,omitempty"`
+
+	// FeatureVector, when set, is the query vector itself instead of one
+	// parsed from Query, with every value in [0, 1]. Omitted features stay
+	// at 0, and monthly_temp_c is ignored.
+	FeatureVector *DestinationFeatures `json:"feature_vector,omitempty"`
+
+	// Where narrows results with AND/OR groups of constraints, on top of
+	// Constraints. It filters only; ranking still targets Constraints.
+	Where *ConstraintGroup `json:"where,omitempty"`
+
+	// Weights scale each feature's influence on ranking (unlisted features default to 1.0)
+	Weights map[string]float64 `json:"weights,omitempty"`
+
+	// Avoid penalizes high values of the named features, with a strength
+	// in [0, 1] each (e.g. {"tourism_density": 1} for "not too touristy")
+	Avoid map[string]float64 `json:"avoid,omitempty"`
+
+	// Tags requires every listed tag; AnyTags requires at least one
+	Tags    []string `json:"tags,omitempty"`
+	AnyTags []string `json:"any_tags,omitempty"`
+
+	// Exclude lists destination IDs to leave out of the results
+	Exclude []string `json:"exclude,omitempty"`
+
+	// MinScore drops results scoring below it, in [0, 1]
+	MinScore *float64 `json:"min_score,omitempty"`
+
+	// Pagination (a zero limit uses the server default)
+	Limit  int `json:"limit,omitempty"`
+	Offset int `json:"offset,omitempty"`
+}
+
+// ConstraintGroup is a node in a boolean tree of feature constraints. It
+// matches when every bound in Constraints holds, every group in All matches,
+// and, if Any is set, at least one group in Any matches.
+type ConstraintGroup struct {
+	Constraints SearchConstraints `json:"constraints,omitempty"`
+	All         []ConstraintGroup `json:"all,omitempty"`
+	Any         []ConstraintGroup `json:"any,omitempty"`
+}
+
+// TemperatureUnit is the unit display temperatures are reported in
+type TemperatureUnit string
+
+const (
+	Celsius    TemperatureUnit = "c"
+	Fahrenheit TemperatureUnit = "f"
+)
+
+// Temperature is a display temperature derived from the normalized avg_temp_c feature
+type Temperature struct {
+	Avg  float64         `json:"avg"`
+	Unit TemperatureUnit `json:"unit"`
+}
+
+// PopularityTier is a coarse popularity class derived from a destination's
+// features
+type PopularityTier string
+
+const (
+	TierHiddenGem PopularityTier = "hidden_gem"
+	TierPopular   PopularityTier = "popular"
+	TierIconic    PopularityTier = "iconic"
+)
+
+// DestinationView is the API representation of a destination. It carries
+// derived display values alongside the stored data, which is never modified.
+type DestinationView struct {
+	Destination
+	Temperature Temperature `json:"temperature"`
+	// IsCoastal is derived from CoastDistanceKm and the server's threshold
+	IsCoastal bool `json:"is_coastal"`
+	// Tier is derived from WikipediaPageviews and TourismDensity and the
+	// server's thresholds
+	Tier PopularityTier `json:"tier"`
+
+	// Search results only
+	Score          *float64           `json:"score,omitempty"`
+	ScoreBreakdown map[string]float64 `json:"score_breakdown,omitempty"`
+	// Summary names up to three reasons the destination matched
+	Summary string `json:"summary,omitempty"`
+	// CollapsedCount is how many near-duplicates this result stands for
+	CollapsedCount int `json:"collapsed_count,omitempty"`
+
+	// Nearest results only: great-circle distance from the requested point
+	DistanceKm *float64 `json:"distance_km,omitempty"`
+
+	// RedactedFeatures are feature keys left out of the encoded view
+	RedactedFeatures []string `json:"-"`
+}
+
+// MarshalJSON encodes the view without its RedactedFeatures, which are also
+// dropped from missing_features. Derived fields go with the features they're
+// computed from: redacting avg_temp_c drops monthly_temp_c and temperature,
+// coast_distance_km drops is_coastal, and either wikipedia_pageviews or
+// tourism_density drops tier.
+func (v DestinationView) MarshalJSON() ([]byte, error) {
+	// plain has the view's fields but not this method
+	type plain DestinationView
+	if len(v.RedactedFeatures) == 0 {
+		return json.Marshal(plain(v))
+	}
+
+	redacted := func(key string) bool { return slices.Contains(v.RedactedFeatures, key) }
+	v.MissingFeatures = slices.DeleteFunc(slices.Clone(v.MissingFeatures), redacted)
+	data, err := json.Marshal(plain(v))
+	if err != nil {
+		return nil, err
+	}
+	var obj, features map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(obj["features"], &features); err != nil {
+		return nil, err
+	}
+	for _, key := range v.RedactedFeatures {
+		delete(features, key)
+	}
+	if redacted("avg_temp_c") {
+		delete(features, "monthly_temp_c")
+		delete(obj, "temperature")
+	}
+	if redacted("coast_distance_km") {
+		delete(obj, "is_coastal")
+	}
+	if redacted("wikipedia_pageviews") || redacted("tourism_density") {
+		delete(obj, "tier")
+	}
+	if obj["features"], err = json.Marshal(features); err != nil {
+		return nil, err
+	}
+	return json.Marshal(obj)
 }
 
 // SearchResponse represents search results
 type SearchResponse struct {
-	Destinations []Destination `json:"destinations"`
-	Total        int           `json:"total"`
+	Destinations []DestinationView `json:"destinations"`
+	Total        int               `json:"total"`
+	Meta         *PageMeta         `json:"meta,omitempty"`
+	// Facets summarizes every match, not just this page; set when requested
+	Facets []FeatureFacet `json:"facets,omitzero"`
+}
+
+// FeatureFacet is the spread of one feature's normalized values across a
+// result set
+type FeatureFacet struct {
+	Key  string  `json:"key"`
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+	Mean float64 `json:"mean"`
+}
+
+// FeatureCoverage is how many destinations have data for a feature
+type FeatureCoverage struct {
+	Key string `json:"key"`
+	// Populated counts destinations with a non-zero value
+	Populated int `json:"populated"`
+	// Percent is Populated as a percentage of all destinations, to one
+	// decimal place
+	Percent float64 `json:"percent"`
+}
+
+// CoverageResponse reports per-feature coverage across the dataset
+type CoverageResponse struct {
+	Total    int               `json:"total"`
+	Features []FeatureCoverage `json:"features"`
+}
+
+// ContinentStats summarizes the destinations on one continent
+type ContinentStats struct {
+	Continent Continent `json:"continent"`
+	Count     int       `json:"count"`
+	// FeatureMeans maps each feature key to its mean normalized value among
+	// the continent's destinations with data for it
+	FeatureMeans map[string]float64 `json:"feature_means"`
+}
+
+// ConstraintDiagnosis is how much one feature constraint narrows a search
+type ConstraintDiagnosis struct {
+	Feature string `json:"feature"`
+	// Eliminated counts candidates outside this constraint's bounds
+	Eliminated int `json:"eliminated"`
+	// WouldAdd counts candidates failing only this constraint, which
+	// loosening it would bring back
+	WouldAdd int `json:"would_add"`
+}
+
+// DiagnoseResponse explains which constraints of a search are too strict
+type DiagnoseResponse struct {
+	// Candidates passed the non-feature filters; Matching also meet every
+	// constraint
+	Candidates  int                   `json:"candidates"`
+	Matching    int                   `json:"matching"`
+	Constraints []ConstraintDiagnosis `json:"constraints"`
+	// MostLimiting is the feature whose constraint would add the most
+	// results if loosened; empty when no constraint eliminates anything
+	MostLimiting string `json:"most_limiting,omitempty"`
 }
 
 // DestinationsResponse represents a list of destinations
 type DestinationsResponse struct {
-	Destinations []Destination `json:"destinations"`
-	Total        int           `json:"total"`
+	Destinations []DestinationView `json:"destinations"`
+	Total        int               `json:"total"`
+	Meta         *PageMeta         `json:"meta,omitempty"`
+}
+
+// ResponseMeta accompanies every API response payload
+type ResponseMeta struct {
+	// RequestID matches the X-Request-ID response header
+	RequestID string `json:"request_id"`
+}
+
+// PageMeta describes the page a paginated response covers. Limit is the page
+// size actually applied, which the server caps regardless of the request;
+// Total counts every match, not just those returned.
+type PageMeta struct {
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// FilterOptions lists the geographic filter values present in the dataset
+type FilterOptions struct {
+	Continents []ContinentOption `json:"continents"`
+}
+
+// ContinentOption is a continent and the countries found on it
+type ContinentOption struct {
+	Name      Continent       `json:"name"`
+	Countries []CountryOption `json:"countries"`
+}
+
+// CountryOption is a country and the regions found in it
+type CountryOption struct {
+	Name    string   `json:"name"`
+	Regions []string `json:"regions"`
+}
+
+// BestMonthResponse is the suggested month to visit a destination
+type BestMonthResponse struct {
+	ID          string      `json:"id"`
+	Month       int         `json:"month"`
+	MonthName   string      `json:"month_name"`
+	Temperature Temperature `json:"temperature"`
+	Rationale   string      `json:"rationale"`
+}
+
+// Suggestion is a destination name offered by autocomplete
+type Suggestion struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Country string `json:"country"`
+}
+
+// BatchRequest lists destinations to fetch in one call
+type BatchRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// BatchResponse holds the found destinations in request order, plus the IDs
+// that didn't match any destination
+type BatchResponse struct {
+	Destinations []DestinationView `json:"destinations"`
+	NotFound     []string          `json:"not_found"`
+}
+
+// SearchValidation echoes a valid search request as the server will run it
+type SearchValidation struct {
+	// Request has aliases resolved to feature keys, tags normalized, and
+	// repeated exclusions dropped, with bounds clamped when the server
+	// clamps them
+	Request SearchRequest `json:"request"`
+	// Constraints are what the search filters and ranks on: the query's
+	// keywords merged with the explicit constraints
+	Constraints SearchConstraints `json:"constraints"`
+}
+
+// MoreLikeRequest lists liked destinations to find more like. Limit
+// defaults to the similar-destinations page size.
+type MoreLikeRequest struct {
+	IDs   []string `json:"ids"`
+	Limit int      `json:"limit,omitempty"`
+}
+
+// MoreLikeResponse ranks destinations by similarity to the liked ones,
+// which it leaves out, and lists the liked IDs that didn't match any
+// destination
+type MoreLikeResponse struct {
+	Destinations []DestinationView `json:"destinations"`
+	Total        int               `json:"total"`
+	Meta         *PageMeta         `json:"meta,omitempty"`
+	NotFound     []string          `json:"not_found"`
+}
+
+// CompareRequest lists the destinations to compare side by side
+type CompareRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// FeatureComparison holds one feature's values across compared destinations,
+// ordered like CompareResponse.Destinations. Normalized rescales the values
+// to [0, 1] within the compared set.
+type FeatureComparison struct {
+	Key        string    `json:"key"`
+	Min        float64   `json:"min"`
+	Max        float64   `json:"max"`
+	Values     []float64 `json:"values"`
+	Normalized []float64 `json:"normalized"`
+}
+
+// CompareResponse is the compared destinations plus a per-feature matrix
+type CompareResponse struct {
+	Destinations []DestinationView   `json:"destinations"`
+	Features     []FeatureComparison `json:"features"`
+}
+
+// ReloadResponse reports the dataset size before and after a reload
+type ReloadResponse struct {
+	Before int `json:"before"`
+	After  int `json:"after"`
 }
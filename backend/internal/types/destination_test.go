@@ -0,0 +1,148 @@
+package types
+
+import (
+	"encoding/json"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestContinentIsValid(t *testing.T) {
+	tests := []struct {
+		continent Continent
+		want      bool
+	}{
+		{Europe, true},
+		{Asia, true},
+		{Africa, true},
+		{NorthAmerica, true},
+		{SouthAmerica, true},
+		{Oceania, true},
+		// Antarctica has no destinations, so it's rejected with the rest
+		{"Antarctica", false},
+		{"europe", false},
+		{"Atlantis", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := tt.continent.IsValid(); got != tt.want {
+			t.Errorf("Continent(%q).IsValid() = %t, want %t", tt.continent, got, tt.want)
+		}
+	}
+}
+
+func TestAllContinentsIsACopy(t *testing.T) {
+	AllContinents()[0] = "Atlantis"
+	if !Europe.IsValid() || Continent("Atlantis").IsValid() {
+		t.Error("changing the returned slice changed the known continents")
+	}
+}
+
+func TestValidImageURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want bool
+	}{
+		{"https://images.example.com/lisbon.jpg", true},
+		{"http://example.com/a.png?w=800", true},
+		{"", false},
+		{"lisbon.jpg", false},
+		{"/static/lisbon.jpg", false},
+		{"ftp://example.com/a.png", false},
+		{"https://", false},
+		{"javascript:alert(1)", false},
+		{"https://exa mple.com/a.png", false},
+	}
+	for _, tt := range tests {
+		if got := ValidImageURL(tt.raw); got != tt.want {
+			t.Errorf("ValidImageURL(%q) = %t, want %t", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestLocationValidate(t *testing.T) {
+	tests := []struct {
+		loc Location
+		// wantErr is the expected error, empty for a valid location
+		wantErr string
+	}{
+		{Location{Lat: 0, Lon: 0}, ""},
+		{Location{Lat: 90, Lon: 180}, ""},
+		{Location{Lat: -90, Lon: -180}, ""},
+		{Location{Lat: 90.0001, Lon: 0}, "lat must be between -90 and 90, got 90.0001"},
+		{Location{Lat: -90.0001, Lon: 0}, "lat must be between -90 and 90, got -90.0001"},
+		{Location{Lat: 0, Lon: 180.0001}, "lon must be between -180 and 180, got 180.0001"},
+		{Location{Lat: 0, Lon: -180.0001}, "lon must be between -180 and 180, got -180.0001"},
+		{Location{Lat: math.NaN(), Lon: 0}, "lat must be between -90 and 90, got NaN"},
+		{Location{Lat: 0, Lon: math.Inf(1)}, "lon must be between -180 and 180, got +Inf"},
+	}
+	for _, tt := range tests {
+		err := tt.loc.Validate()
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.wantErr {
+			t.Errorf("%+v.Validate() = %q, want %q", tt.loc, got, tt.wantErr)
+		}
+	}
+}
+
+func TestDestinationUnmarshalActive(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want bool
+	}{
+		{`{"id":"lisbon"}`, true},
+		{`{"id":"lisbon","active":true}`, true},
+		{`{"id":"lisbon","active":false}`, false},
+	}
+	for _, tt := range tests {
+		var d Destination
+		if err := json.Unmarshal([]byte(tt.raw), &d); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", tt.raw, err)
+		}
+		if d.ID != "lisbon" || d.Active != tt.want {
+			t.Errorf("Unmarshal(%s) = ID %q, active %t; want lisbon, %t", tt.raw, d.ID, d.Active, tt.want)
+		}
+	}
+}
+
+func TestImageUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want []Image
+		// wantErr is a substring of the expected error, empty for none
+		wantErr string
+	}{
+		{"legacy strings", `["https://example.com/a.jpg","https://example.com/b.jpg"]`,
+			[]Image{{URL: "https://example.com/a.jpg"}, {URL: "https://example.com/b.jpg"}}, ""},
+		{"objects", `[{"url":"https://example.com/a.jpg","caption":"Alfama","credit":"Ana Silva","width":1600,"height":1067}]`,
+			[]Image{{URL: "https://example.com/a.jpg", Caption: "Alfama", Credit: "Ana Silva", Width: 1600, Height: 1067}}, ""},
+		{"both", `["https://example.com/a.jpg",{"url":"https://example.com/b.jpg","credit":"Ana Silva"}]`,
+			[]Image{{URL: "https://example.com/a.jpg"}, {URL: "https://example.com/b.jpg", Credit: "Ana Silva"}}, ""},
+		{"number", `[42]`, nil, "image must be a URL string or an object, got 42"},
+		{"nested array", `[["https://example.com/a.jpg"]]`, nil, "image must be a URL string or an object"},
+		{"bad width", `[{"url":"https://example.com/a.jpg","width":"wide"}]`, nil, "cannot unmarshal string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []Image
+			err := json.Unmarshal([]byte(tt.raw), &got)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("images = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
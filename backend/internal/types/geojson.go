@@ -0,0 +1,31 @@
+package types
+
+// GeoJSON types for map rendering (RFC 7946)
+
+// FeatureCollection is a GeoJSON collection of point features. Total is a
+// foreign member carrying the unpaginated result count.
+type FeatureCollection struct {
+	Type     string       `json:"type"`
+	Features []GeoFeature `json:"features"`
+	Total    int          `json:"total"`
+}
+
+// GeoFeature is a GeoJSON feature with a point geometry
+type GeoFeature struct {
+	Type       string        `json:"type"`
+	Geometry   Point         `json:"geometry"`
+	Properties GeoProperties `json:"properties"`
+}
+
+// Point is a GeoJSON point. Coordinates are [lon, lat] per the spec.
+type Point struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// GeoProperties are the destination details attached to each feature
+type GeoProperties struct {
+	ID    string  `json:"id"`
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
@@ -81,10 +81,19 @@ export interface Destination {
   features: DestinationFeatures
 
   // Media and description
-  images: string[]
+  images: Image[]
   description?: string
 }
 
+// Destination photo with its caption and attribution
+export interface Image {
+  url: string
+  caption?: string
+  credit?: string     // Attribution
+  width?: number      // Pixels
+  height?: number
+}
+
 // Search request
 export interface SearchRequest {
   query: string                      // Free-text query